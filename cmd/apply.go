@@ -1,16 +1,12 @@
 package cmd
 
 import (
-	"bytes"
-	"context"
 	"fmt"
-	"io"
-	"mime/multipart"
-	"net/http"
 	"os"
 	"path/filepath"
+	"time"
 
-	"github.com/Facets-cloud/fctl/pkg/config"
+	"github.com/Facets-cloud/fctl/pkg/output"
 	"github.com/Facets-cloud/fctl/pkg/utils"
 	"github.com/hashicorp/terraform-exec/tfexec"
 	"github.com/spf13/cobra"
@@ -18,16 +14,36 @@ import (
 
 var (
 	zipPath               string
-	targetAddr            string
+	targetAddrs           []string
 	statePath             string
 	selectedDeployment    string
 	uploadReleaseMetadata bool
+	varFiles              []string
+	tfVars                []string
+	backendType           string
+	backendConfigPairs    []string
+	backendConfigFile     string
+	jsonOutput            bool
+	zipChecksum           string
+	applyPlanFile         string
+	applyForce            bool
+	tfParallelism         int
+	tfTimeout             time.Duration
+	refreshOnly           bool
+	noRefresh             bool
+	noStateBackup         bool
+	stateBackupCount      int
+	runLogPath            string
+	replaceAddrs          []string
+	decryptPassphrase     string
+	vaultAddr             string
+	vaultToken            string
 )
 
 var applyCmd = &cobra.Command{
 	Use:   "apply",
 	Short: "Apply a Terraform export to your Facets environment.",
-	Long:  `Apply a Terraform configuration exported from Facets to your target environment. This command mimics 'terraform apply', supports state file management, selective module targeting, and can upload release metadata to the control plane for audit and tracking.`,
+	Long:  `Apply a Terraform configuration exported from Facets to your target environment. This command mimics 'terraform apply', supports state file management, selective module targeting, and can upload release metadata to the control plane for audit and tracking. Terraform runs 10 resource operations at once; use --parallelism to change that, for example to lower it for cloud accounts with strict API rate limits.`,
 	RunE:  runApply,
 }
 
@@ -36,19 +52,41 @@ func init() {
 
 	// Add flags
 	applyCmd.Flags().StringVarP(&zipPath, "zip", "z", "", "Path to the exported zip file (required)")
-	applyCmd.Flags().StringVarP(&targetAddr, "target", "t", "", "Module target address for selective releases")
+	applyCmd.Flags().StringSliceVarP(&targetAddrs, "target", "t", nil, "Module target address for selective releases. Can be specified multiple times or comma-separated.")
 	applyCmd.Flags().StringVarP(&statePath, "state", "s", "", "Path to the state file")
+	applyCmd.Flags().StringVar(&backendType, "backend", "", "Terraform backend type for state (s3, gcs, azurerm, consul). Falls back to TF_BACKEND_TYPE")
+	applyCmd.Flags().StringArrayVar(&backendConfigPairs, "backend-config", nil, "Backend variable in the form key=value, overriding TF_BACKEND_<TYPE>_<KEY>. Can be specified multiple times.")
+	applyCmd.Flags().StringVar(&backendConfigFile, "backend-config-file", "", "Path to a JSON or HCL file of backend variables (key = value). --backend-config values take precedence")
+	applyCmd.Flags().StringVar(&vaultAddr, "vault-addr", "", "Address of the Vault server to read backend variables from when TF_BACKEND_<TYPE>_VAULT_PATH is set. Falls back to VAULT_ADDR")
+	applyCmd.Flags().StringVar(&vaultToken, "vault-token", "", "Token for --vault-addr. Falls back to VAULT_TOKEN")
+	applyCmd.Flags().StringArrayVar(&varFiles, "var-file", nil, "Path to a Terraform variables file (.tfvars). Can be specified multiple times.")
+	applyCmd.Flags().StringArrayVar(&tfVars, "var", nil, "Set a Terraform variable, in the form name=value. Can be specified multiple times.")
+	applyCmd.Flags().StringVar(&decryptPassphrase, "decrypt", "", "Passphrase of a zip encrypted with 'fctl repackage --encrypt'")
+	applyCmd.Flags().StringVar(&zipChecksum, "checksum", "", "Expected digest of the zip file, in the form sha256:<hex>")
+	applyCmd.Flags().StringVar(&applyPlanFile, "plan-file", "", "Apply a plan saved with 'fctl plan --out', relative to the deployment directory")
+	applyCmd.Flags().BoolVar(&applyForce, "force", false, "Apply even if the plan destroys or replaces resources. Implies --allow-destroy, as Terraform refuses such plans while prevent_destroy = true")
+	applyCmd.Flags().StringArrayVar(&replaceAddrs, "replace", nil, "Force replacement of the resource at this address, like terraform's -replace. Requires --allow-destroy or --force. Can be specified multiple times.")
+	applyCmd.Flags().BoolVar(&refreshOnly, "refresh-only", false, "Only update the state to match real infrastructure, without changing any resources")
+	applyCmd.Flags().BoolVar(&noRefresh, "no-refresh", false, "Skip refreshing resources before planning, for speed. Changes made outside of Terraform are not detected, so the plan may be wrong")
+	applyCmd.Flags().IntVar(&tfParallelism, "parallelism", 10, "Number of concurrent Terraform operations. Lower it for cloud accounts with strict API rate limits")
+	applyCmd.Flags().DurationVar(&tfTimeout, "timeout", 0, "Stop Terraform if it runs longer than this (e.g. 90m, 2h); 0 means no limit")
+	applyCmd.Flags().BoolVar(&noStateBackup, "no-backup", false, "Do not back up the local state before applying")
+	applyCmd.Flags().IntVar(&stateBackupCount, "backup-count", 5, "Number of local state backups to keep per environment (0 keeps all)")
+	applyCmd.Flags().StringVar(&runLogPath, "log-file", "", "Write the run log to this file instead of <deployment-dir>/fctl-run-<timestamp>.log")
 	applyCmd.Flags().BoolVar(&uploadReleaseMetadata, "upload-release-metadata", false, "Upload release metadata to control plane after apply")
+	applyCmd.Flags().BoolVar(&jsonOutput, "json", false, "Suppress progress output and print a single JSON result when done")
 
 	applyCmd.MarkFlagRequired("zip")
+	applyCmd.MarkFlagsMutuallyExclusive("refresh-only", "no-refresh")
+	applyCmd.MarkFlagsMutuallyExclusive("refresh-only", "replace")
 }
 
-func runApply(cmd *cobra.Command, args []string) error {
+func runApply(cmd *cobra.Command, args []string) (err error) {
 	allowDestroy, _ := cmd.Flags().GetBool("allow-destroy")
-	fmt.Println("🚀 Starting terraform apply process...")
+	output.Infoln("🚀 Starting terraform apply process...")
 
 	// Initialize backend configuration
-	backendConfig, err := config.NewBackendConfig()
+	backendConfig, err := newBackendConfigFromFlags()
 	if err != nil {
 		return fmt.Errorf("❌ Failed to initialize backend configuration: %v", err)
 	}
@@ -58,47 +96,91 @@ func runApply(cmd *cobra.Command, args []string) error {
 		if err := backendConfig.Validate(); err != nil {
 			return fmt.Errorf("❌ Invalid backend configuration: %v", err)
 		}
-		fmt.Printf("🔐 Using %s backend for state management\n", backendConfig.Type)
+		output.Infof("🔐 Using %s backend for state management\n", backendConfig.Type)
 	}
 
-	// Extract deployment ID from zip filename
-	deploymentID, err := utils.ExtractDeploymentID(zipPath)
+	// Validate variables before doing any extraction work
+	resolvedVarFiles, err := resolveVarFiles(varFiles)
 	if err != nil {
-		return fmt.Errorf("❌ Failed to extract deployment ID: %v", err)
+		return fmt.Errorf("❌ Invalid --var-file: %v", err)
 	}
-
-	// Unzip to a temp dir to read deploymentcontext.json
-	tempDir, err := os.MkdirTemp("", "fctl-unzip-*")
-	if err != nil {
-		return fmt.Errorf("❌ Failed to create temp dir: %v", err)
+	if err := validateVars(tfVars); err != nil {
+		return fmt.Errorf("❌ Invalid --var: %v", err)
+	}
+	if err := validateTargets(targetAddrs); err != nil {
+		return fmt.Errorf("❌ Invalid --target: %v", err)
+	}
+	if tfParallelism < 1 {
+		return fmt.Errorf("❌ Invalid --parallelism: must be at least 1, got %d", tfParallelism)
 	}
-	defer os.RemoveAll(tempDir)
-	if err := utils.ExtractZip(zipPath, tempDir); err != nil {
-		return fmt.Errorf("❌ Failed to extract zip: %v", err)
+	if err := validateReplaceAddrs(replaceAddrs, targetAddrs); err != nil {
+		return fmt.Errorf("❌ Invalid --replace: %v", err)
 	}
-	envID, err := utils.ExtractEnvIDFromDeploymentContext(tempDir)
+	// Terraform refuses to plan a deletion or replacement while prevent_destroy = true, so --force lifts
+	// it, and --replace is refused up front instead of failing in the plan
+	if applyForce && !allowDestroy {
+		output.Infoln("🔓 --force implies --allow-destroy")
+		allowDestroy = true
+	}
+	if len(replaceAddrs) > 0 && !allowDestroy {
+		return fmt.Errorf("❌ --replace destroys and recreates resources, which prevent_destroy = true forbids. Re-run with --allow-destroy")
+	}
+
+	// Verify the zip before anything is extracted
+	exportZip, removeDecrypted, err := openExportZip(zipPath, zipChecksum, decryptPassphrase)
 	if err != nil {
-		return fmt.Errorf("❌ Failed to extract environment ID from deploymentcontext.json: %v", err)
+		return fmt.Errorf("❌ %v", err)
 	}
-	fmt.Printf("🌍 Environment ID: %s\n", envID)
-	fmt.Printf("🆔 Deployment ID: %s\n", deploymentID)
+	defer removeDecrypted()
 
-	// Create base directory structure
-	homeDir, err := os.UserHomeDir()
+	// Resolve the environment and the local directories for this deployment
+	paths, err := resolveDeploymentPaths(exportZip)
 	if err != nil {
-		return fmt.Errorf("❌ Failed to get home directory: %v", err)
+		return fmt.Errorf("❌ %v", err)
 	}
-	baseDir := filepath.Join(homeDir, ".facets")
-	envDir := filepath.Join(baseDir, envID)
+	envID, deploymentID := paths.EnvID, paths.DeploymentID
+	output.Infof("🌍 Environment ID: %s\n", envID)
+	output.Infof("🆔 Deployment ID: %s\n", deploymentID)
+	output.Result().EnvironmentID = envID
+	output.Result().DeploymentID = deploymentID
 
 	// Cleanup old releases (directories and zips)
-	cleanupOldReleases(envDir, baseDir, envID)
+	cleanupOldReleases(paths.EnvDir, paths.BaseDir, envID, KeepReleasesFlag)
+
+	envDir, deployDir, tfWorkDir := paths.EnvDir, paths.DeployDir, paths.TFWorkDir
 
-	deployDir := filepath.Join(envDir, deploymentID)
-	tfWorkDir := filepath.Join(deployDir, "tfexport")
+	runLog, err := openRunLog(paths, runLogPath, "apply")
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+	defer func() { runLog.Close(err) }()
+	output.Result().OutputPath = deployDir
+
+	// A saved plan is only valid for the exact configuration it was created from
+	var planFile string
+	if applyPlanFile != "" {
+		if len(targetAddrs) > 0 || len(replaceAddrs) > 0 || len(varFiles) > 0 || len(tfVars) > 0 || refreshOnly || noRefresh {
+			return fmt.Errorf("❌ --plan-file cannot be combined with --target, --replace, --var, --var-file, --refresh-only, or --no-refresh; they are fixed when the plan is saved")
+		}
+		planFile, err = resolvePlanFile(deployDir, applyPlanFile)
+		if err != nil {
+			return fmt.Errorf("❌ Invalid --plan-file: %v", err)
+		}
+		if _, err := os.Stat(planFile); err != nil {
+			return fmt.Errorf("❌ Plan file not found: %v", err)
+		}
+		// The digest of the zip is recorded next to the plan by 'plan --out'
+		different, err := zipDiffersFromDigest(exportZip, planFile+planZipSuffix)
+		if err != nil {
+			return fmt.Errorf("❌ Failed to compare zip with the saved plan: %v", err)
+		}
+		if different {
+			return fmt.Errorf("❌ Plan file %s is stale: the zip changed since it was saved, or it was not saved with 'fctl plan --out'. Run 'fctl plan --zip %s --out %s' again", planFile, zipPath, applyPlanFile)
+		}
+	}
 
 	// Create directories
-	fmt.Printf("📁 Creating deployment directory for environment %s and deployment %s...\n", envID, deploymentID)
+	output.Infof("📁 Creating deployment directory for environment %s and deployment %s...\n", envID, deploymentID)
 	if err := os.MkdirAll(deployDir, 0755); err != nil {
 		return fmt.Errorf("❌ Failed to create directories: %v", err)
 	}
@@ -114,13 +196,13 @@ func runApply(cmd *cobra.Command, args []string) error {
 				return fmt.Errorf("❌ Failed to list existing deployments: %v", err)
 			}
 			if len(existingDeployments) > 0 {
-				proceed, selectedDeployment, err := utils.PromptUser(existingDeployments, tfStatePath)
+				proceed, selectedDeployment, err := selectExistingState(existingDeployments, tfStatePath)
 				if err != nil {
 					return fmt.Errorf("❌ User input error: %v", err)
 				}
 				if proceed {
 					if selectedDeployment == "__USE_TF_TFSTATE__" {
-						fmt.Println("📝 Using tf.tfstate for this deployment...")
+						output.Infoln("📝 Using tf.tfstate for this deployment...")
 						stateDir := filepath.Join(tfWorkDir, "terraform.tfstate.d", envID)
 						if err := os.MkdirAll(stateDir, 0755); err != nil {
 							return fmt.Errorf("❌ Failed to create state directory: %v", err)
@@ -130,7 +212,7 @@ func runApply(cmd *cobra.Command, args []string) error {
 							return fmt.Errorf("❌ Failed to copy tf.tfstate: %v", err)
 						}
 					} else {
-						fmt.Println("🔄 User chose to proceed with state file from existing deployment")
+						output.Infoln("🔄 User chose to proceed with state file from existing deployment")
 						if err := utils.CopyStateFromPreviousDeployment(envDir, deploymentID, envID, selectedDeployment); err != nil {
 							return fmt.Errorf("❌ Failed to copy state file: %v", err)
 						}
@@ -138,46 +220,41 @@ func runApply(cmd *cobra.Command, args []string) error {
 				}
 			}
 		} else {
-			fmt.Printf("ℹ️  Using %s backend for state management\n", backendConfig.Type)
+			output.Infof("ℹ️  Using %s backend for state management\n", backendConfig.Type)
 		}
 		// Now extract zip contents to deployDir
-		fmt.Println("📦 Extracting terraform configuration...")
-		if err := utils.ExtractZip(zipPath, deployDir); err != nil {
-			return fmt.Errorf("❌ Failed to extract zip: %v", err)
-		}
-		// Fix permissions after extraction
-		if err := utils.FixPermissions(tfWorkDir); err != nil {
-			return fmt.Errorf("❌ Failed to fix permissions: %v", err)
+		output.Infoln("📦 Extracting terraform configuration...")
+		if err := extractDeployment(exportZip, paths); err != nil {
+			return fmt.Errorf("❌ %v", err)
 		}
 	} else {
-		fmt.Println("♻️ Using existing deployment directory")
-		// Check if zip contents differ from deployDir
-		different, err := utils.IsZipDifferentFromDir(zipPath, deployDir)
+		output.Infoln("♻️ Using existing deployment directory")
+		// Check if the zip changed since it was extracted to deployDir
+		different, err := zipChangedSinceExtract(exportZip, paths)
 		if err != nil {
-			return fmt.Errorf("❌ Failed to compare zip and directory: %v", err)
+			return fmt.Errorf("❌ Failed to compare zip with the deployment directory: %v", err)
 		}
 		if different {
-			fmt.Println("📦 Changes detected in zip, extracting to deployment directory...")
-			if err := utils.ExtractZip(zipPath, deployDir); err != nil {
-				return fmt.Errorf("❌ Failed to extract zip: %v", err)
-			}
-			// Fix permissions after extraction
-			if err := utils.FixPermissions(tfWorkDir); err != nil {
-				return fmt.Errorf("❌ Failed to fix permissions: %v", err)
+			output.Infoln("📦 Changes detected in zip, extracting to deployment directory...")
+			if err := extractDeployment(exportZip, paths); err != nil {
+				return fmt.Errorf("❌ %v", err)
 			}
 		} else {
-			fmt.Println("✅ No changes detected in zip, skipping extraction.")
+			output.Infoln("✅ No changes detected in zip, skipping extraction.")
 		}
 	}
+	// Resources are protected with prevent_destroy = true unless --allow-destroy is given
 	if allowDestroy {
-		fmt.Println("🔒 Enforcing prevent_destroy = true in all Terraform resources...")
-		if err := utils.UpdatePreventDestroyInTFs(tfWorkDir); err != nil {
-			return fmt.Errorf("❌ Failed to update prevent_destroy in .tf files: %v", err)
-		}
+		output.Infoln("🔓 Setting prevent_destroy = false in all Terraform resources (--allow-destroy)...")
+	} else {
+		output.Infoln("🔒 Enforcing prevent_destroy = true in all Terraform resources...")
+	}
+	if err := utils.UpdatePreventDestroyInTFs(tfWorkDir, !allowDestroy); err != nil {
+		return fmt.Errorf("❌ Failed to update prevent_destroy in .tf files: %v", err)
 	}
 
 	// Initialize terraform
-	fmt.Println("🔧 Initializing terraform...")
+	output.Infoln("🔧 Initializing terraform...")
 	tf, err := tfexec.NewTerraform(tfWorkDir, "terraform")
 	if err != nil {
 		return fmt.Errorf("❌ Failed to create terraform executor: %v", err)
@@ -185,12 +262,12 @@ func runApply(cmd *cobra.Command, args []string) error {
 
 	// set logging for terraform
 	tf.SetLog("INFO")
-	tf.SetStderr(os.Stdout)
-	tf.SetStdout(os.Stdout)
+	tf.SetStderr(output.Writer())
+	tf.SetStdout(output.Writer())
 
 	// Handle state file
 	if statePath != "" && backendConfig == nil {
-		fmt.Println("📝 Copying provided state file...")
+		output.Infoln("📝 Copying provided state file...")
 		stateDir := filepath.Join(tfWorkDir, "terraform.tfstate.d", envID)
 		if err := os.MkdirAll(stateDir, 0755); err != nil {
 			return fmt.Errorf("❌ Failed to create state directory: %v", err)
@@ -204,130 +281,134 @@ func runApply(cmd *cobra.Command, args []string) error {
 
 	// Initialize terraform with backend configuration if provided
 	if backendConfig != nil {
-		fmt.Printf("🔄 Writing backend.tf.json for %s backend...\n", backendConfig.Type)
+		output.Infof("🔄 Writing backend.tf.json for %s backend...\n", backendConfig.Type)
 		if err := backendConfig.WriteBackendTFJSON(tfWorkDir); err != nil {
 			return fmt.Errorf("❌ Failed to write backend.tf.json: %v", err)
 		}
 	}
-	if err := tf.Init(context.Background()); err != nil {
-		return fmt.Errorf("❌ Terraform init failed: %v", err)
+	ctx, cancel := terraformContext()
+	defer cancel()
+	if err := tf.Init(ctx); err != nil {
+		return terraformError(ctx, "init", err)
 	}
 
 	// Select workspace/environment
-	if err := tf.WorkspaceSelect(context.Background(), envID); err != nil {
+	if err := tf.WorkspaceSelect(ctx, envID); err != nil {
 		// If workspace doesn't exist, create it
-		if err := tf.WorkspaceNew(context.Background(), envID); err != nil {
+		if err := tf.WorkspaceNew(ctx, envID); err != nil {
 			return fmt.Errorf("❌ Failed to create workspace: %v", err)
 		}
 	}
 
-	// Run terraform apply
-	applyOptions := []tfexec.ApplyOption{}
-	if targetAddr != "" {
-		fmt.Printf("🎯 Targeting module: %s\n", targetAddr)
-		applyOptions = append(applyOptions, tfexec.Target(targetAddr))
+	// Plan first, unless applying a saved plan, so destructive changes are caught before anything is applied
+	if planFile != "" {
+		output.Infof("📄 Applying saved plan: %s\n", planFile)
+	} else {
+		planOptions := terraformRunOptions[tfexec.PlanOption](targetAddrs, resolvedVarFiles, tfVars, tfParallelism)
+		for _, addr := range replaceAddrs {
+			output.Infof("♻️ Forcing replacement of %s\n", addr)
+			planOptions = append(planOptions, tfexec.Replace(addr))
+		}
+		planOptions = append(planOptions, refreshPlanOptions()...)
+		planFile = filepath.Join(deployDir, "fctl-apply.tfplan")
+		defer os.Remove(planFile)
+		planOptions = append(planOptions, tfexec.Out(planFile))
+
+		output.Infoln("📋 Running terraform plan...")
+		if _, err := tf.Plan(ctx, planOptions...); err != nil {
+			return terraformError(ctx, "plan", err)
+		}
+	}
+
+	plan, err := tf.ShowPlanFile(ctx, planFile)
+	if err != nil {
+		return fmt.Errorf("❌ Failed to read plan: %v", err)
+	}
+	if destroyed := utils.PlannedDestroys(plan); len(destroyed) > 0 {
+		if !allowDestroy && !applyForce {
+			output.Errorf("🛑 The plan would destroy the following resources:\n")
+			for _, address := range destroyed {
+				output.Errorf("   - %s\n", address)
+			}
+			return fmt.Errorf("❌ Refusing to apply a plan that destroys %d resource(s). Re-run with --allow-destroy or --force to apply it anyway", len(destroyed))
+		}
+		output.Warnf("⚠️ Applying a plan that destroys %d resource(s)\n", len(destroyed))
+	}
+
+	// Apply exactly the plan that was checked
+	applyOptions := []tfexec.ApplyOption{tfexec.DirOrPlan(planFile)}
+	applyOptions = append(applyOptions, tfexec.Parallelism(tfParallelism))
+
+	if backendConfig == nil && !noStateBackup {
+		backupPath, err := backupLocalState(envDir, tfWorkDir, envID, stateBackupCount)
+		if err != nil {
+			return fmt.Errorf("❌ Failed to back up state: %v", err)
+		}
+		if backupPath != "" {
+			output.Infof("💾 State backed up to: %s\n", backupPath)
+		}
 	}
 
-	fmt.Println("🔨 Running terraform apply...")
-	if err := tf.Apply(context.Background(), applyOptions...); err != nil {
+	output.Infoln("🔨 Running terraform apply...")
+	if err := tf.Apply(ctx, applyOptions...); err != nil {
 		// even if the terraform apply fails, we need to update the state file
 		if backendConfig == nil {
-			fmt.Printf("💾 State file location: %s/terraform.tfstate.d/%s/terraform.tfstate\n", tfWorkDir, envID)
+			output.Infof("💾 State file location: %s/terraform.tfstate.d/%s/terraform.tfstate\n", tfWorkDir, envID)
 			// Save latest state for this environment
 			latestStatePath := filepath.Join(envDir, "tf.tfstate")
 			currentStatePath := filepath.Join(tfWorkDir, "terraform.tfstate.d", envID, "terraform.tfstate")
 			if _, err := os.Stat(currentStatePath); err == nil {
 				if err := utils.CopyFile(currentStatePath, latestStatePath); err != nil {
-					fmt.Printf("⚠️ Warning: Failed to save latest state: %v\n", err)
+					output.Warnf("⚠️ Warning: Failed to save latest state: %v\n", err)
 				} else {
-					fmt.Printf("📝 Latest state saved to: %s\n", latestStatePath)
+					output.Infof("📝 Latest state saved to: %s\n", latestStatePath)
 				}
 			}
 		}
-		return fmt.Errorf("❌ Terraform apply failed: %v", err)
+		if terraformTimedOut(ctx) {
+			// Record whatever was applied before Terraform was stopped
+			output.Infoln("📊 Generating release metadata from the partial state...")
+			if err := utils.GenerateReleaseMetadata(tf, deployDir); err != nil {
+				output.Warnf("⚠️ Warning: Failed to generate release metadata: %v\n", err)
+			}
+		}
+		return terraformError(ctx, "apply", err)
 	}
 
 	// Generate release metadata
-	fmt.Println("📊 Generating release metadata...")
+	output.Infoln("📊 Generating release metadata...")
 	if err := utils.GenerateReleaseMetadata(tf, deployDir); err != nil {
-		fmt.Printf("⚠️ Warning: Failed to generate release metadata: %v\n", err)
+		output.Warnf("⚠️ Warning: Failed to generate release metadata: %v\n", err)
 	}
 
-	// Upload release metadata if flag is set
+	// Upload release metadata if flag is set. A failed upload fails the command, but only after the
+	// state has been saved below.
+	var uploadErr error
 	if uploadReleaseMetadata {
-		fmt.Println("☁️ Uploading release metadata to control plane...")
-		metadataFile := filepath.Join(deployDir, "release-metadata.json")
-		f, err := os.Open(metadataFile)
-		if err != nil {
-			fmt.Printf("❌ Failed to open release metadata file: %v\n", err)
-		} else {
-			defer f.Close()
-			var requestBody bytes.Buffer
-			writer := multipart.NewWriter(&requestBody)
-			part, err := writer.CreateFormFile("file", filepath.Base(f.Name()))
-			if err != nil {
-				fmt.Printf("❌ Failed to create multipart form file: %v\n", err)
-				return nil
-			}
-			_, err = io.Copy(part, f)
-			if err != nil {
-				fmt.Printf("❌ Failed to copy file to multipart writer: %v\n", err)
-				return nil
-			}
-			writer.Close()
-
-			// Build the upload URL (replace with actual endpoint if needed)
-			clientConfig := config.GetClientConfig("") // use the correct profile if needed
-			if clientConfig == nil {
-				fmt.Printf("❌ Could not get client configuration\n")
-				return nil
-			}
-			uploadURL := clientConfig.ControlPlaneURL + "/cc-ui/v1/clusters/" + envID + "/deployments/" + deploymentID + "/upload-release-metadata"
-
-			req, err := http.NewRequest("POST", uploadURL, &requestBody)
-			if err != nil {
-				fmt.Printf("❌ Failed to create upload request: %v\n", err)
-				return nil
-			}
-			req.Header.Set("Content-Type", writer.FormDataContentType())
-			req.SetBasicAuth(clientConfig.Username, clientConfig.Token)
-
-			httpClient := &http.Client{}
-			resp, err := httpClient.Do(req)
-			if err != nil {
-				fmt.Printf("❌ Failed to upload release metadata: %v\n", err)
-				return nil
-			}
-			defer resp.Body.Close()
-
-			if resp.StatusCode == 503 {
-				fmt.Printf("❌ Control plane is down. Please try again later. (HTTP 503)\n")
-				return nil
-			}
-			if resp.StatusCode != http.StatusOK {
-				body, _ := io.ReadAll(resp.Body)
-				fmt.Printf("❌ Upload failed with status: %s\n%s\n", resp.Status, string(body))
-			} else {
-				fmt.Println("✅ Release metadata uploaded to control plane.")
-			}
+		output.Infoln("☁️ Uploading release metadata to control plane...")
+		if uploadErr = uploadReleaseMetadataFile(deployDir, envID, deploymentID); uploadErr == nil {
+			output.Infoln("✅ Release metadata uploaded to control plane.")
 		}
 	}
 
-	fmt.Printf("✅ Successfully applied terraform configuration!\n")
-	fmt.Printf("📍 Deployment directory: %s\n", deployDir)
+	output.Successf("✅ Successfully applied terraform configuration!\n")
+	output.Infof("📍 Deployment directory: %s\n", deployDir)
 	if backendConfig == nil {
-		fmt.Printf("💾 State file location: %s/terraform.tfstate.d/%s/terraform.tfstate\n", tfWorkDir, envID)
+		output.Infof("💾 State file location: %s/terraform.tfstate.d/%s/terraform.tfstate\n", tfWorkDir, envID)
 		// Save latest state for this environment
 		latestStatePath := filepath.Join(envDir, "tf.tfstate")
 		currentStatePath := filepath.Join(tfWorkDir, "terraform.tfstate.d", envID, "terraform.tfstate")
 		if _, err := os.Stat(currentStatePath); err == nil {
 			if err := utils.CopyFile(currentStatePath, latestStatePath); err != nil {
-				fmt.Printf("⚠️ Warning: Failed to save latest state: %v\n", err)
+				output.Warnf("⚠️ Warning: Failed to save latest state: %v\n", err)
 			} else {
-				fmt.Printf("📝 Latest state saved to: %s\n", latestStatePath)
+				output.Infof("📝 Latest state saved to: %s\n", latestStatePath)
 			}
 		}
 	}
 
+	if uploadErr != nil {
+		return fmt.Errorf("❌ Terraform apply succeeded, but the release metadata upload failed: %v", uploadErr)
+	}
 	return nil
 }
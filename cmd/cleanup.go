@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Facets-cloud/fctl/pkg/output"
+	"github.com/Facets-cloud/fctl/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cleanupDryRun    bool
+	cleanupOlderThan string
+	cleanupEnvID     string
+	cleanupAll       bool
+	cleanupYes       bool
+)
+
+var cleanupCmd = &cobra.Command{
+	Use:   "cleanup",
+	Short: "Report disk usage of the base directory and remove old deployment directories, zips, and run logs.",
+	Long: `Report the disk usage of every environment in the base directory (~/.facets by default) and remove what is no longer needed. Deployments are ordered by modification time.
+
+Which deployments are removed depends on the selector:
+  (none)         Apply the --keep-releases retention, the same cleanup apply, plan, and destroy run for the environment they work on: only the newest deployment directories are kept, within them the newest run logs, and the newest exported zips.
+  --older-than   Remove deployment directories and exported zips last modified longer ago than the given age, such as 30d or 12h.
+  --all          Remove every deployment directory and exported zip.
+
+Use --environment-id to clean up a single environment; only the zips of the deployments removed from it are removed then. The newest deployment of each environment, which holds its newest state, the environment's tf.tfstate, and its state backups are never removed.
+
+Use --dry-run to list what would be removed and how much space it would free. Otherwise you are asked to confirm; pass --yes to skip the confirmation.`,
+	// Cleanup works on local files only
+	Annotations: map[string]string{skipAuthAnnotation: "true"},
+	RunE:        runCleanup,
+}
+
+func init() {
+	rootCmd.AddCommand(cleanupCmd)
+
+	cleanupCmd.Flags().BoolVar(&cleanupDryRun, "dry-run", false, "List what would be removed and the space it would free without removing anything")
+	cleanupCmd.Flags().StringVar(&cleanupOlderThan, "older-than", "", "Remove deployments and zips last modified longer ago than this (e.g. 30d, 12h)")
+	cleanupCmd.Flags().StringVarP(&cleanupEnvID, "environment-id", "e", "", "Only clean up this environment")
+	cleanupCmd.Flags().BoolVar(&cleanupAll, "all", false, "Remove all deployments and zips except the newest deployment of each environment")
+	cleanupCmd.Flags().BoolVarP(&cleanupYes, "yes", "y", false, "Remove without asking for confirmation")
+
+	cleanupCmd.MarkFlagsMutuallyExclusive("older-than", "all")
+	cleanupCmd.RegisterFlagCompletionFunc("environment-id", completeLocalEnvironmentIDs)
+}
+
+// cleanupItem is a deployment directory, run log, or zip selected for removal.
+type cleanupItem struct {
+	Path string
+	Size uint64
+}
+
+func runCleanup(cmd *cobra.Command, args []string) error {
+	var cutoff time.Time
+	if cleanupOlderThan != "" {
+		age, err := parseAge(cleanupOlderThan)
+		if err != nil {
+			return fmt.Errorf("❌ Invalid --older-than %q: %v. Use a number of days such as 30d, or a duration such as 12h", cleanupOlderThan, err)
+		}
+		cutoff = time.Now().Add(-age)
+	}
+	byAge := !cutoff.IsZero() || cleanupAll
+	if !byAge && KeepReleasesFlag <= 0 {
+		return fmt.Errorf("❌ Cleanup is disabled (--keep-releases is %d). Pass --keep-releases with a positive number, --older-than, or --all", KeepReleasesFlag)
+	}
+	if !cleanupDryRun && !cleanupYes && NonInteractiveFlag {
+		return fmt.Errorf("❌ Refusing to remove files without confirmation in non-interactive mode. Pass --yes to remove them, or --dry-run to list them")
+	}
+
+	baseDir, err := resolveBaseDir()
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+	var envIDs []string
+	if cleanupEnvID != "" {
+		if _, err := os.Stat(filepath.Join(baseDir, cleanupEnvID)); err != nil {
+			return fmt.Errorf("❌ No local deployments found for environment %s in %s", cleanupEnvID, baseDir)
+		}
+		envIDs = []string{cleanupEnvID}
+	} else if envIDs, err = listLocalEnvironments(baseDir); err != nil {
+		return fmt.Errorf("❌ Failed to list environments in %s: %v", baseDir, err)
+	}
+
+	switch {
+	case cleanupAll:
+		output.Infof("🧹 Selecting all deployments in %s\n", baseDir)
+	case byAge:
+		output.Infof("🧹 Selecting deployments older than %s in %s\n", cleanupOlderThan, baseDir)
+	default:
+		output.Infof("🧹 Keeping the newest %d release(s) per environment in %s\n", KeepReleasesFlag, baseDir)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ENVIRONMENT\tDEPLOYMENTS\tSIZE\tRECLAIMABLE")
+	var items []cleanupItem
+	for _, envID := range envIDs {
+		envDir := filepath.Join(baseDir, envID)
+		var old []string
+		if byAge {
+			old = deploymentsOlderThan(envDir, cutoff)
+		} else {
+			old = oldReleaseFiles(envDir, KeepReleasesFlag)
+		}
+		envItems := sizeCleanupItems(old)
+		deployments := len(listByModTime(envDir, func(entry os.DirEntry) bool { return entry.IsDir() }))
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", envID, deployments, formatBytes(pathSize(envDir)), formatBytes(totalCleanupSize(envItems)))
+		items = append(items, envItems...)
+
+		if cleanupEnvID != "" {
+			// Other environments' zips are left alone, so only remove the zips of this environment's removed deployments
+			var zips []string
+			for _, path := range old {
+				zipPath := filepath.Join(baseDir, filepath.Base(path)+".zip")
+				if _, err := os.Stat(zipPath); err == nil {
+					zips = append(zips, zipPath)
+				}
+			}
+			items = append(items, sizeCleanupItems(zips)...)
+		}
+	}
+	if cleanupEnvID == "" {
+		allZips := exportZips(baseDir)
+		var zips []string
+		switch {
+		case cleanupAll:
+			zips = allZips
+		case byAge:
+			zips = filterOlderThan(allZips, cutoff)
+		default:
+			zips = oldExportZips(baseDir, KeepReleasesFlag)
+		}
+		zipItems := sizeCleanupItems(zips)
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", "(exported zips)", len(allZips), formatBytes(totalCleanupSize(sizeCleanupItems(allZips))), formatBytes(totalCleanupSize(zipItems)))
+		items = append(items, zipItems...)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Println()
+
+	if len(items) == 0 {
+		output.Successf("✅ Nothing to clean up\n")
+		return nil
+	}
+	reclaimable := formatBytes(totalCleanupSize(items))
+	if cleanupDryRun {
+		for _, item := range items {
+			output.Infof("🔍 Would remove %s (%s)\n", item.Path, formatBytes(item.Size))
+		}
+		output.Successf("ℹ️ %d item(s) would be removed, freeing %s (dry run, nothing changed)\n", len(items), reclaimable)
+		return nil
+	}
+
+	if !cleanupYes {
+		ok, err := utils.Confirm(fmt.Sprintf("⚠️ Remove %d item(s), freeing %s?", len(items), reclaimable))
+		if err != nil {
+			return fmt.Errorf("❌ User input error: %v", err)
+		}
+		if !ok {
+			output.Infoln("ℹ️ Cleanup cancelled")
+			return nil
+		}
+	}
+	for _, item := range items {
+		if err := os.RemoveAll(item.Path); err != nil {
+			return fmt.Errorf("❌ Failed to remove %s: %v", item.Path, err)
+		}
+		output.Infof("🗑️ Removed %s (%s)\n", item.Path, formatBytes(item.Size))
+	}
+	output.Successf("✅ Removed %d item(s), freed %s\n", len(items), reclaimable)
+	return nil
+}
+
+// deploymentsOlderThan returns the deployment directories of envDir last modified before cutoff, or all of
+// them when cutoff is zero. The newest deployment is never returned, as it holds the newest state.
+func deploymentsOlderThan(envDir string, cutoff time.Time) []string {
+	dirs := listByModTime(envDir, func(entry os.DirEntry) bool { return entry.IsDir() })
+	if len(dirs) == 0 {
+		return nil
+	}
+	return filterOlderThan(dirs[:len(dirs)-1], cutoff)
+}
+
+// filterOlderThan returns the paths last modified before cutoff, or all of them when cutoff is zero.
+func filterOlderThan(paths []string, cutoff time.Time) []string {
+	if cutoff.IsZero() {
+		return paths
+	}
+	var old []string
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && info.ModTime().Before(cutoff) {
+			old = append(old, path)
+		}
+	}
+	return old
+}
+
+// sizeCleanupItems returns the paths with their size on disk.
+func sizeCleanupItems(paths []string) []cleanupItem {
+	items := make([]cleanupItem, len(paths))
+	for i, path := range paths {
+		items[i] = cleanupItem{Path: path, Size: pathSize(path)}
+	}
+	return items
+}
+
+func totalCleanupSize(items []cleanupItem) uint64 {
+	var total uint64
+	for _, item := range items {
+		total += item.Size
+	}
+	return total
+}
+
+// pathSize returns the total size of the regular files at or under path. Entries that cannot be read are skipped.
+func pathSize(path string) uint64 {
+	var size uint64
+	filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if entry.Type().IsRegular() {
+			if info, err := entry.Info(); err == nil {
+				size += uint64(info.Size())
+			}
+		}
+		return nil
+	})
+	return size
+}
+
+// parseAge parses an age given as a number of days, such as 30d, or as a Go duration, such as 12h.
+func parseAge(s string) (time.Duration, error) {
+	var age time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("not a number of days")
+		}
+		age = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if age, err = time.ParseDuration(s); err != nil {
+			return 0, err
+		}
+	}
+	if age <= 0 {
+		return 0, fmt.Errorf("must be positive")
+	}
+	return age, nil
+}
@@ -1,49 +1,830 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
+	"strings"
+	"time"
+
+	"github.com/Facets-cloud/fctl/pkg/config"
+	"github.com/Facets-cloud/fctl/pkg/output"
+	"github.com/Facets-cloud/fctl/pkg/utils"
+	"github.com/go-openapi/runtime"
+	"github.com/hashicorp/terraform-exec/tfexec"
+	"github.com/yarlson/pin"
 )
 
-// cleanupOldReleases keeps only the last 10 deployment directories and zip files for the given envDir and baseDir.
-// It silently deletes older ones (both directories and zips) if more than 10 exist.
-func cleanupOldReleases(envDir, baseDir, envID string) {
-	// --- Cleanup Directories ---
+// apiRetryAttempts is how many times control plane calls that poll or trigger exports are attempted
+// when they fail with a transient error (see utils.RetryableOperation).
+const apiRetryAttempts = 5
+
+// stateBackupPrefix is the file name prefix of the state backups taken before apply and destroy.
+const stateBackupPrefix = "tf.tfstate.backup-"
+
+// runLogPrefix is the file name prefix of the per-run logs apply, plan, and destroy write to the deployment directory.
+const runLogPrefix = "fctl-run-"
+
+// newSpinner returns the spinner fctl shows for long-running steps, colored unless colors are disabled.
+func newSpinner(message string, opts ...pin.Option) *pin.Pin {
+	options := []pin.Option{
+		pin.WithDoneSymbol('✔'),
+		pin.WithPrefix("pin"),
+	}
+	if output.ColorEnabled() {
+		options = append(options,
+			pin.WithSpinnerColor(pin.ColorCyan),
+			pin.WithTextColor(pin.ColorYellow),
+			pin.WithDoneSymbolColor(pin.ColorGreen),
+			pin.WithPrefixColor(pin.ColorMagenta),
+			pin.WithSeparatorColor(pin.ColorGray),
+		)
+	}
+	return pin.New(message, append(options, opts...)...)
+}
+
+// cleanupOldReleases keeps only the last keep deployment directories and zip files for the given envDir and baseDir.
+// It silently deletes older ones (both directories and zips) if more than keep exist. A keep of 0 disables cleanup.
+// Only directories are removed from envDir, so the state backups kept there are left to backupLocalState.
+// The run logs in the deployment directories that are kept are trimmed to the same count.
+func cleanupOldReleases(envDir, baseDir, envID string, keep int) {
+	if keep <= 0 {
+		return
+	}
+	for _, path := range oldReleaseFiles(envDir, keep) {
+		os.RemoveAll(path)
+	}
+	for _, path := range oldExportZips(baseDir, keep) {
+		os.Remove(path)
+	}
+}
+
+// oldReleaseFiles returns the deployment directories of envDir beyond the newest keep, and the run
+// logs beyond the newest keep in each directory that is kept.
+func oldReleaseFiles(envDir string, keep int) []string {
+	dirs := listByModTime(envDir, func(entry os.DirEntry) bool { return entry.IsDir() })
+	var old []string
+	if len(dirs) > keep {
+		old = append(old, dirs[:len(dirs)-keep]...)
+		dirs = dirs[len(dirs)-keep:]
+	}
+	for _, dir := range dirs {
+		logs := listByModTime(dir, func(entry os.DirEntry) bool {
+			return !entry.IsDir() && strings.HasPrefix(entry.Name(), runLogPrefix) && strings.HasSuffix(entry.Name(), ".log")
+		})
+		if len(logs) > keep {
+			old = append(old, logs[:len(logs)-keep]...)
+		}
+	}
+	return old
+}
+
+// oldExportZips returns the exported zips (<deploymentID>.zip) in baseDir beyond the newest keep.
+func oldExportZips(baseDir string, keep int) []string {
+	zips := exportZips(baseDir)
+	if len(zips) > keep {
+		return zips[:len(zips)-keep]
+	}
+	return nil
+}
+
+// exportZips returns the exported zips (<deploymentID>.zip) in baseDir, oldest first.
+func exportZips(baseDir string) []string {
+	zipPattern := regexp.MustCompile(`[a-fA-F0-9\-]{36}\.zip$`)
+	return listByModTime(baseDir, func(entry os.DirEntry) bool {
+		return !entry.IsDir() && zipPattern.MatchString(entry.Name())
+	})
+}
+
+// parseCopyPairs parses --copy values of the form source:destination into the files to add to a zip.
+// The sources must exist.
+func parseCopyPairs(pairs []string) ([]utils.ZipAddition, error) {
+	var additions []utils.ZipAddition
+	for _, pair := range pairs {
+		source, dest, found := strings.Cut(pair, ":")
+		if !found {
+			return nil, fmt.Errorf("invalid --copy value: %s (expected format source:destination)", pair)
+		}
+		if source == "" || dest == "" {
+			return nil, fmt.Errorf("invalid --copy value: %s (source and destination required)", pair)
+		}
+		if _, err := os.Stat(source); err != nil {
+			return nil, fmt.Errorf("failed to stat source %s: %w", source, err)
+		}
+		additions = append(additions, utils.ZipAddition{Source: source, Dest: dest})
+	}
+	return additions, nil
+}
+
+// listLocalEnvironments returns the IDs of the environments in baseDir that have at least one
+// extracted deployment.
+func listLocalEnvironments(baseDir string) ([]string, error) {
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var envIDs []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if matches, _ := filepath.Glob(filepath.Join(baseDir, entry.Name(), "*", "tfexport")); len(matches) > 0 {
+			envIDs = append(envIDs, entry.Name())
+		}
+	}
+	return envIDs, nil
+}
+
+// listByModTime returns the paths of the entries of dir that match, oldest first. Deployment IDs
+// are UUIDs, so their names say nothing about when they were created.
+func listByModTime(dir string, match func(os.DirEntry) bool) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	type entryTime struct {
+		path    string
+		modTime time.Time
+	}
+	var matched []entryTime
+	for _, entry := range entries {
+		if !match(entry) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		matched = append(matched, entryTime{filepath.Join(dir, entry.Name()), info.ModTime()})
+	}
+	sort.SliceStable(matched, func(i, j int) bool { return matched[i].modTime.Before(matched[j].modTime) })
+	paths := make([]string, len(matched))
+	for i, m := range matched {
+		paths[i] = m.path
+	}
+	return paths
+}
+
+// runLog is the log file of an apply, plan, or destroy run. While it is open, all output,
+// including Terraform's, is copied to it.
+type runLog struct {
+	path    string
+	file    *os.File
+	command string
+	start   time.Time
+}
+
+// openRunLog creates <deployDir>/fctl-run-<timestamp>.log, or appends to logPath when given,
+// writes a header for the run, and starts copying all output to it.
+func openRunLog(paths *deploymentPaths, logPath, command string) (*runLog, error) {
+	start := time.Now()
+	if logPath == "" {
+		logPath = filepath.Join(paths.DeployDir, runLogPrefix+start.Format("20060102T150405")+".log")
+	}
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %v", err)
+	}
+	file, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %v", err)
+	}
+	fmt.Fprintf(file, "fctl %s started at %s\nEnvironment ID: %s\nDeployment ID: %s\n\n", command, start.Format(time.RFC3339), paths.EnvID, paths.DeploymentID)
+	output.SetLogFile(file)
+	return &runLog{path: logPath, file: file, command: command, start: start}, nil
+}
+
+// Close writes the final status of the run to the log, stops copying output to it, and prints its path.
+func (l *runLog) Close(runErr error) {
+	status := "succeeded"
+	if runErr != nil {
+		status = fmt.Sprintf("failed: %v", strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(runErr.Error()), "❌")))
+	}
+	fmt.Fprintf(l.file, "\nfctl %s %s after %s (finished at %s)\n", l.command, status, utils.FormatDuration(time.Since(l.start)), time.Now().Format(time.RFC3339))
+	output.SetLogFile(nil)
+	l.file.Close()
+	output.Infof("📜 Log file: %s\n", l.path)
+}
+
+// backupLocalState copies the workspace state of a deployment to <envDir>/tf.tfstate.backup-<timestamp>
+// before apply or destroy changes it, and keeps only the newest keep backups (0 keeps all). It returns the backup path,
+// or "" if there is no state yet.
+func backupLocalState(envDir, tfWorkDir, envID string, keep int) (string, error) {
+	statePath := filepath.Join(tfWorkDir, "terraform.tfstate.d", envID, "terraform.tfstate")
+	if _, err := os.Stat(statePath); os.IsNotExist(err) {
+		return "", nil
+	}
+	backupPath := filepath.Join(envDir, stateBackupPrefix+time.Now().Format("20060102T150405"))
+	if err := utils.CopyFile(statePath, backupPath); err != nil {
+		return "", err
+	}
+
 	entries, err := os.ReadDir(envDir)
-	if err == nil {
-		var dirs []string
-		for _, entry := range entries {
-			if entry.IsDir() {
-				dirs = append(dirs, entry.Name())
-			}
+	if err != nil {
+		return backupPath, nil
+	}
+	var backups []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), stateBackupPrefix) {
+			backups = append(backups, entry.Name())
 		}
-		// Sort by name (assuming name encodes time, as in deploymentID)
-		sort.Strings(dirs)
-		if len(dirs) > 10 {
-			for _, dir := range dirs[:len(dirs)-10] {
-				os.RemoveAll(filepath.Join(envDir, dir))
-			}
+	}
+	// The timestamp suffix sorts chronologically
+	sort.Strings(backups)
+	if keep > 0 && len(backups) > keep {
+		for _, name := range backups[:len(backups)-keep] {
+			os.Remove(filepath.Join(envDir, name))
 		}
 	}
+	return backupPath, nil
+}
 
-	// --- Cleanup Zip Files ---
-	// Zips are stored in the current working directory, matching pattern: <deploymentID>.zip (UUID format)
-	zipPattern := regexp.MustCompile(`[a-fA-F0-9\-]{36}\.zip$`)
-	files, err := os.ReadDir(baseDir)
-	if err == nil {
-		var zips []string
-		for _, entry := range files {
-			if !entry.IsDir() && zipPattern.MatchString(entry.Name()) {
-				zips = append(zips, entry.Name())
+// newBackendConfigFromFlags builds the backend configuration for apply/plan/destroy.
+// Values are layered: TF_BACKEND_* environment variables, then the Vault secret at
+// TF_BACKEND_<TYPE>_VAULT_PATH, then --backend-config-file, then --backend-config pairs.
+func newBackendConfigFromFlags() (*config.BackendConfig, error) {
+	configVars := make(map[string]string)
+	if backendConfigFile != "" {
+		fileVars, err := config.LoadBackendConfigFile(backendConfigFile)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range fileVars {
+			configVars[k] = v
+		}
+	}
+	flagVars, err := config.ParseBackendConfigPairs(backendConfigPairs)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range flagVars {
+		configVars[k] = v
+	}
+	backendConfig, err := config.NewBackendConfig(backendType, configVars)
+	if err != nil || backendConfig == nil {
+		return backendConfig, err
+	}
+
+	vaultVars, err := config.ReadVaultBackendVars(backendConfig.Type, vaultAddr, vaultToken)
+	if err != nil {
+		return nil, err
+	}
+	if len(vaultVars) > 0 {
+		output.Infof("🔐 Read %d %s backend variable(s) from Vault\n", len(vaultVars), backendConfig.Type)
+	}
+	for k, v := range vaultVars {
+		if _, explicit := configVars[k]; !explicit {
+			backendConfig.ConfigVars[k] = v
+		}
+	}
+	return backendConfig, nil
+}
+
+// resolveVarFiles checks that every --var-file exists and returns their absolute paths.
+// Terraform runs inside the extracted tfexport directory, so relative paths must be
+// resolved against the caller's working directory first.
+func resolveVarFiles(paths []string) ([]string, error) {
+	var resolved []string
+	for _, p := range paths {
+		absPath, err := filepath.Abs(p)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve %s: %w", p, err)
+		}
+		info, err := os.Stat(absPath)
+		if err != nil {
+			return nil, fmt.Errorf("variables file %s: %w", p, err)
+		}
+		if info.IsDir() {
+			return nil, fmt.Errorf("variables file %s is a directory", p)
+		}
+		resolved = append(resolved, absPath)
+	}
+	return resolved, nil
+}
+
+// validateVars checks that every --var value is a name=value assignment.
+func validateVars(vars []string) error {
+	for _, v := range vars {
+		name, _, found := strings.Cut(v, "=")
+		if !found {
+			return fmt.Errorf("%q is missing '=' (expected name=value)", v)
+		}
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("%q has an empty variable name (expected name=value)", v)
+		}
+	}
+	return nil
+}
+
+// openExportZip checks an exported zip against --checksum, when given, decrypts it with passphrase if it was
+// encrypted with 'fctl repackage --encrypt', and verifies the integrity of every entry so a corrupted archive
+// fails before any extraction. It returns the path of the zip to extract, which is a temporary file for an
+// encrypted zip, and a function that removes the temporary file.
+func openExportZip(zipPath, checksum, passphrase string) (string, func(), error) {
+	noop := func() {}
+	if checksum != "" {
+		if err := utils.VerifyChecksum(zipPath, checksum); err != nil {
+			return "", noop, err
+		}
+	}
+	encrypted, err := utils.IsEncryptedZip(zipPath)
+	if err != nil {
+		return "", noop, err
+	}
+	if !encrypted {
+		return zipPath, noop, utils.VerifyZip(zipPath)
+	}
+	if passphrase == "" {
+		return "", noop, fmt.Errorf("%s is encrypted; pass the passphrase with --decrypt", zipPath)
+	}
+
+	output.Infoln("🔓 Decrypting zip...")
+	tempDir, err := os.MkdirTemp("", "fctl-decrypt-*")
+	if err != nil {
+		return "", noop, fmt.Errorf("failed to create temp dir: %v", err)
+	}
+	cleanup := func() { os.RemoveAll(tempDir) }
+	// The deployment ID is read from the file name, so the decrypted zip keeps it
+	decrypted := filepath.Join(tempDir, strings.TrimSuffix(filepath.Base(zipPath), ".enc"))
+	if err := utils.DecryptFile(zipPath, decrypted, passphrase); err != nil {
+		cleanup()
+		return "", noop, fmt.Errorf("failed to decrypt %s: %v", zipPath, err)
+	}
+	if err := utils.VerifyZip(decrypted); err != nil {
+		cleanup()
+		return "", noop, err
+	}
+	return decrypted, cleanup, nil
+}
+
+// resolvePlanFile returns the absolute path of a saved plan file. Relative paths are
+// resolved against the deployment directory, and the result must stay inside it.
+func resolvePlanFile(deployDir, planFile string) (string, error) {
+	if !filepath.IsAbs(planFile) {
+		planFile = filepath.Join(deployDir, planFile)
+	}
+	planFile = filepath.Clean(planFile)
+	rel, err := filepath.Rel(deployDir, planFile)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("plan file %s must be inside the deployment directory %s", planFile, deployDir)
+	}
+	return planFile, nil
+}
+
+// extractedZipFile is written to the deployment directory with the SHA-256 of the zip that was last
+// extracted there. The extracted .tf files are rewritten for prevent_destroy, so they cannot be compared
+// with the zip to tell whether it changed.
+const extractedZipFile = "extracted-zip.sha256"
+
+// planZipSuffix is appended to the name of a plan saved with 'plan --out' for the file that records the
+// SHA-256 of the zip the plan was made from.
+const planZipSuffix = ".zip.sha256"
+
+// extractDeployment extracts zipPath to the deployment directory, fixes permissions, and records the
+// digest of the zip in extractedZipFile.
+func extractDeployment(zipPath string, paths *deploymentPaths) error {
+	if err := utils.ExtractZip(zipPath, paths.DeployDir); err != nil {
+		return fmt.Errorf("failed to extract zip: %v", err)
+	}
+	if err := utils.FixPermissions(paths.TFWorkDir); err != nil {
+		return fmt.Errorf("failed to fix permissions: %v", err)
+	}
+	return writeZipDigest(zipPath, filepath.Join(paths.DeployDir, extractedZipFile))
+}
+
+// zipChangedSinceExtract reports whether zipPath differs from the zip last extracted to the deployment
+// directory. Deployments extracted before the digest was recorded count as changed.
+func zipChangedSinceExtract(zipPath string, paths *deploymentPaths) (bool, error) {
+	return zipDiffersFromDigest(zipPath, filepath.Join(paths.DeployDir, extractedZipFile))
+}
+
+// writeZipDigest writes the SHA-256 of zipPath to digestFile
+func writeZipDigest(zipPath, digestFile string) error {
+	digest, err := utils.HashFile(zipPath)
+	if err != nil {
+		return fmt.Errorf("failed to hash %s: %v", zipPath, err)
+	}
+	if err := os.WriteFile(digestFile, []byte(digest+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to record the digest of %s: %v", zipPath, err)
+	}
+	return nil
+}
+
+// zipDiffersFromDigest reports whether the SHA-256 of zipPath differs from the one recorded in
+// digestFile by writeZipDigest. A missing digest file counts as different.
+func zipDiffersFromDigest(zipPath, digestFile string) (bool, error) {
+	recorded, err := os.ReadFile(digestFile)
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return true, err
+	}
+	digest, err := utils.HashFile(zipPath)
+	if err != nil {
+		return true, fmt.Errorf("failed to hash %s: %v", zipPath, err)
+	}
+	return strings.TrimSpace(string(recorded)) != digest, nil
+}
+
+// selectExistingState decides which state a new deployment starts from when earlier deployments exist.
+// With --non-interactive it never reads stdin: tf.tfstate is used if present, otherwise the state starts fresh.
+func selectExistingState(existingDeployments []string, tfStatePath string) (bool, string, error) {
+	if !NonInteractiveFlag {
+		return utils.PromptUser(existingDeployments, tfStatePath)
+	}
+	if _, err := os.Stat(tfStatePath); err == nil {
+		output.Infoln("ℹ️ Non-interactive mode: using tf.tfstate from the last release")
+		return true, "__USE_TF_TFSTATE__", nil
+	}
+	output.Infoln("ℹ️ Non-interactive mode: no tf.tfstate found, starting with a fresh state")
+	return false, "", nil
+}
+
+// terraformContext returns the context for the Terraform commands of apply, plan, and destroy.
+// It is cancelled after --timeout, which makes tfexec interrupt Terraform so it can release its lock.
+func terraformContext() (context.Context, context.CancelFunc) {
+	if tfTimeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), tfTimeout)
+}
+
+// terraformTimedOut reports whether ctx was cancelled because --timeout elapsed.
+func terraformTimedOut(ctx context.Context) bool {
+	return errors.Is(ctx.Err(), context.DeadlineExceeded)
+}
+
+// terraformError wraps the error of a Terraform step, reporting a --timeout separately from a failure.
+func terraformError(ctx context.Context, step string, err error) error {
+	if terraformTimedOut(ctx) {
+		return fmt.Errorf("❌ Terraform %s timed out after %s", step, tfTimeout)
+	}
+	if strings.Contains(err.Error(), "prevent_destroy") {
+		return fmt.Errorf("❌ Terraform %s failed: %v. Resources are protected with prevent_destroy = true; re-run with --allow-destroy to destroy or replace them", step, err)
+	}
+	return fmt.Errorf("❌ Terraform %s failed: %v", step, err)
+}
+
+// refreshPlanOptions maps --refresh-only and --no-refresh to plan options. The flags are mutually exclusive.
+func refreshPlanOptions() []tfexec.PlanOption {
+	switch {
+	case refreshOnly:
+		output.Infoln("🔄 Refresh-only: only the state will be updated to match real infrastructure")
+		return []tfexec.PlanOption{tfexec.RefreshOnly(true)}
+	case noRefresh:
+		output.Warnf("⚠️ Skipping refresh: changes made outside of Terraform are not detected, so the plan may be wrong\n")
+		return []tfexec.PlanOption{tfexec.Refresh(false)}
+	}
+	return nil
+}
+
+// terraformRunOptions maps --target, --var-file, --var, and --parallelism to options for T, which is
+// tfexec.PlanOption or tfexec.DestroyOption. Every option built here implements both.
+func terraformRunOptions[T any](targets, varFiles, vars []string, parallelism int) []T {
+	var options []any
+	if len(targets) > 0 {
+		output.Infof("🎯 Targeting %d module(s): %s\n", len(targets), strings.Join(targets, ", "))
+	}
+	for _, target := range targets {
+		options = append(options, tfexec.Target(target))
+	}
+	for _, varFile := range varFiles {
+		output.Infof("📄 Using variables file: %s\n", varFile)
+		options = append(options, tfexec.VarFile(varFile))
+	}
+	for _, v := range vars {
+		options = append(options, tfexec.Var(v))
+	}
+	options = append(options, tfexec.Parallelism(parallelism))
+	typed := make([]T, 0, len(options))
+	for _, option := range options {
+		typed = append(typed, option.(T))
+	}
+	return typed
+}
+
+// validateTargets rejects empty --target entries, such as the one in "a,,b", and addresses with spaces,
+// which are usually two addresses passed as one argument. Spaces inside quoted keys, as in
+// module.app["my key"], are allowed.
+func validateTargets(targets []string) error {
+	quotedKey := regexp.MustCompile(`"[^"]*"`)
+	for _, target := range targets {
+		if strings.TrimSpace(target) == "" {
+			return fmt.Errorf("empty target address in %q", strings.Join(targets, ","))
+		}
+		if strings.ContainsAny(quotedKey.ReplaceAllString(target, ""), " \t") {
+			return fmt.Errorf("target address %q contains spaces; pass each address with its own --target", target)
+		}
+	}
+	return nil
+}
+
+// validateReplaceAddrs rejects empty --replace addresses and addresses that are also given with --target.
+// Targeting a resource that is being replaced is redundant, and usually means --target was meant to limit
+// the apply to something else.
+func validateReplaceAddrs(replaces, targets []string) error {
+	for _, addr := range replaces {
+		if strings.TrimSpace(addr) == "" {
+			return fmt.Errorf("empty resource address")
+		}
+		if slices.Contains(targets, addr) {
+			return fmt.Errorf("%s is also given with --target; replacing a resource already includes it in the apply", addr)
+		}
+	}
+	return nil
+}
+
+// validateOutputFormat checks the value of an --output flag for list commands.
+func validateOutputFormat(format string) error {
+	if format != "table" && format != "json" {
+		return fmt.Errorf("❌ Invalid --output value: %s (expected table or json)", format)
+	}
+	return nil
+}
+
+// printJSON writes v to stdout as indented JSON.
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// formatTimestamp renders t for table output, using "-" for unset times.
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+// isControlPlaneDown reports whether err is an HTTP 503 from the control plane.
+func isControlPlaneDown(err error) bool {
+	apiErr, ok := err.(*runtime.APIError)
+	return ok && apiErr.Code == 503
+}
+
+// deploymentPaths are the local directories apply/plan/destroy use for a deployment.
+type deploymentPaths struct {
+	EnvID        string
+	DeploymentID string
+	BaseDir      string // ~/.facets, or --base-dir
+	EnvDir       string // <BaseDir>/<envID>
+	DeployDir    string // <BaseDir>/<envID>/<deploymentID>
+	TFWorkDir    string // <BaseDir>/<envID>/<deploymentID>/tfexport
+}
+
+// resolveBaseDir returns the directory deployments are extracted to: --base-dir, else FCTL_BASE_DIR,
+// else ~/.facets. Credentials and config always stay in ~/.facets. FCTL_BASE_DIR and base_dir in
+// ~/.facets/fctl.ini are applied to --base-dir before a command runs; FCTL_BASE_DIR is also read here
+// for shell completion, which runs without that.
+func resolveBaseDir() (string, error) {
+	baseDir := BaseDirFlag
+	if baseDir == "" {
+		baseDir = os.Getenv("FCTL_BASE_DIR")
+	}
+	if baseDir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %v", err)
+		}
+		return filepath.Join(homeDir, ".facets"), nil
+	}
+	absBaseDir, err := filepath.Abs(baseDir)
+	if err != nil {
+		return "", fmt.Errorf("could not resolve base directory %s: %v", baseDir, err)
+	}
+	return absBaseDir, nil
+}
+
+// newDeploymentPaths returns the local directories of a deployment of an environment.
+func newDeploymentPaths(envID, deploymentID string) (*deploymentPaths, error) {
+	baseDir, err := resolveBaseDir()
+	if err != nil {
+		return nil, err
+	}
+	envDir := filepath.Join(baseDir, envID)
+	deployDir := filepath.Join(envDir, deploymentID)
+	return &deploymentPaths{
+		EnvID:        envID,
+		DeploymentID: deploymentID,
+		BaseDir:      baseDir,
+		EnvDir:       envDir,
+		DeployDir:    deployDir,
+		TFWorkDir:    filepath.Join(deployDir, "tfexport"),
+	}, nil
+}
+
+// resolveDeploymentPaths reads the deployment ID from the zip filename and the environment ID
+// from its deploymentcontext.json, and returns the matching local directories.
+func resolveDeploymentPaths(zipPath string) (*deploymentPaths, error) {
+	deploymentID, err := utils.ExtractDeploymentID(zipPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract deployment ID: %v", err)
+	}
+
+	// Unzip to a temp dir to read deploymentcontext.json
+	tempDir, err := os.MkdirTemp("", "fctl-unzip-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	if err := utils.ExtractZip(zipPath, tempDir); err != nil {
+		return nil, fmt.Errorf("failed to extract zip: %v", err)
+	}
+	envID, err := utils.ExtractEnvIDFromDeploymentContext(tempDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract environment ID from deploymentcontext.json: %v", err)
+	}
+	return newDeploymentPaths(envID, deploymentID)
+}
+
+// resolveLocalDeploymentPaths returns the directories of a deployment that was already extracted
+// for envID. When deploymentID is empty, the most recently modified deployment is used.
+func resolveLocalDeploymentPaths(envID, deploymentID string) (*deploymentPaths, error) {
+	if deploymentID == "" {
+		envPaths, err := newDeploymentPaths(envID, "")
+		if err != nil {
+			return nil, err
+		}
+		deployments, err := utils.ListExistingDeployments(envPaths.EnvDir, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list deployments in %s: %v", envPaths.EnvDir, err)
+		}
+		// Skip directories without an extracted configuration, such as state-backups
+		for i := len(deployments) - 1; i >= 0; i-- {
+			if _, err := os.Stat(filepath.Join(envPaths.EnvDir, deployments[i], "tfexport")); err == nil {
+				deploymentID = deployments[i]
+				break
 			}
 		}
-		sort.Strings(zips)
-		if len(zips) > 10 {
-			for _, zip := range zips[:len(zips)-10] {
-				os.Remove(filepath.Join(baseDir, zip))
+		if deploymentID == "" {
+			return nil, fmt.Errorf("no local deployments found for environment %s in %s", envID, envPaths.EnvDir)
+		}
+	}
+	return newDeploymentPaths(envID, deploymentID)
+}
+
+// openDeploymentWorkspace returns a Terraform executor for a deployment that has already been
+// extracted by apply/plan/destroy, with the environment's workspace selected.
+func openDeploymentWorkspace(zipPath string) (*tfexec.Terraform, *deploymentPaths, error) {
+	paths, err := resolveDeploymentPaths(zipPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := os.Stat(paths.TFWorkDir); os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("no local deployment found at %s; run 'fctl apply --zip %s' first", paths.TFWorkDir, zipPath)
+	}
+	tf, err := openWorkspace(paths)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tf, paths, nil
+}
+
+// openWorkspace returns a Terraform executor for the extracted deployment at paths,
+// with the environment's workspace selected.
+func openWorkspace(paths *deploymentPaths) (*tfexec.Terraform, error) {
+	tf, err := tfexec.NewTerraform(paths.TFWorkDir, "terraform")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create terraform executor: %v", err)
+	}
+	if err := tf.WorkspaceSelect(context.Background(), paths.EnvID); err != nil {
+		return nil, fmt.Errorf("failed to select workspace %s: %v", paths.EnvID, err)
+	}
+	return tf, nil
+}
+
+// prepareDeploymentWorkspace sets up the deployment directory for zipPath the way apply does when it
+// is missing: the state to start from is chosen (or taken from statePath), the zip is extracted, and
+// prevent_destroy is written. Terraform is then initialized, with backendConfig when given, and the
+// environment's workspace is selected.
+func prepareDeploymentWorkspace(zipPath, statePath string, backendConfig *config.BackendConfig) (*tfexec.Terraform, *deploymentPaths, error) {
+	paths, err := resolveDeploymentPaths(zipPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := os.Stat(paths.TFWorkDir); os.IsNotExist(err) {
+		if err := os.MkdirAll(paths.DeployDir, 0755); err != nil {
+			return nil, nil, fmt.Errorf("failed to create directories: %v", err)
+		}
+		if backendConfig == nil && statePath == "" {
+			tfStatePath := filepath.Join(paths.EnvDir, "tf.tfstate")
+			existingDeployments, err := utils.ListExistingDeployments(paths.EnvDir, paths.DeploymentID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to list existing deployments: %v", err)
+			}
+			if len(existingDeployments) > 0 {
+				proceed, selected, err := selectExistingState(existingDeployments, tfStatePath)
+				if err != nil {
+					return nil, nil, fmt.Errorf("user input error: %v", err)
+				}
+				if proceed && selected == "__USE_TF_TFSTATE__" {
+					statePath = tfStatePath
+				} else if proceed {
+					if err := utils.CopyStateFromPreviousDeployment(paths.EnvDir, paths.DeploymentID, paths.EnvID, selected); err != nil {
+						return nil, nil, fmt.Errorf("failed to copy state file: %v", err)
+					}
+				}
 			}
 		}
+		output.Infoln("📦 Extracting terraform configuration...")
+		if err := extractDeployment(zipPath, paths); err != nil {
+			return nil, nil, err
+		}
+		if err := utils.UpdatePreventDestroyInTFs(paths.TFWorkDir, !AllowDestroyFlag); err != nil {
+			return nil, nil, fmt.Errorf("failed to update prevent_destroy in .tf files: %v", err)
+		}
+	}
+
+	if statePath != "" && backendConfig == nil {
+		output.Infoln("📝 Copying provided state file...")
+		destPath := filepath.Join(paths.TFWorkDir, "terraform.tfstate.d", paths.EnvID, "terraform.tfstate")
+		if err := utils.CopyFile(statePath, destPath); err != nil {
+			return nil, nil, fmt.Errorf("failed to copy state file: %v", err)
+		}
+	}
+	if backendConfig != nil {
+		output.Infof("🔄 Writing backend.tf.json for %s backend...\n", backendConfig.Type)
+		if err := backendConfig.WriteBackendTFJSON(paths.TFWorkDir); err != nil {
+			return nil, nil, fmt.Errorf("failed to write backend.tf.json: %v", err)
+		}
+	}
+
+	tf, err := tfexec.NewTerraform(paths.TFWorkDir, "terraform")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create terraform executor: %v", err)
+	}
+	output.Infoln("🔧 Initializing terraform...")
+	if err := tf.Init(context.Background()); err != nil {
+		return nil, nil, fmt.Errorf("terraform init failed: %v", err)
+	}
+	if err := tf.WorkspaceSelect(context.Background(), paths.EnvID); err != nil {
+		if err := tf.WorkspaceNew(context.Background(), paths.EnvID); err != nil {
+			return nil, nil, fmt.Errorf("failed to create workspace: %v", err)
+		}
+	}
+	return tf, paths, nil
+}
+
+// uploadReleaseMetadataFile uploads <deployDir>/release-metadata.json to the control plane as the release
+// metadata of the deployment.
+func uploadReleaseMetadataFile(deployDir, envID, deploymentID string) error {
+	f, err := os.Open(filepath.Join(deployDir, "release-metadata.json"))
+	if err != nil {
+		return fmt.Errorf("failed to open release metadata file: %v", err)
+	}
+	defer f.Close()
+	var requestBody bytes.Buffer
+	writer := multipart.NewWriter(&requestBody)
+	part, err := writer.CreateFormFile("file", filepath.Base(f.Name()))
+	if err != nil {
+		return fmt.Errorf("failed to create multipart form file: %v", err)
+	}
+	if _, err := io.Copy(part, f); err != nil {
+		return fmt.Errorf("failed to copy file to multipart writer: %v", err)
+	}
+	writer.Close()
+
+	clientConfig := config.GetClientConfig("")
+	if clientConfig == nil {
+		return fmt.Errorf("could not get client configuration")
+	}
+	uploadURL := clientConfig.ControlPlaneURL + "/cc-ui/v1/clusters/" + envID + "/deployments/" + deploymentID + "/upload-release-metadata"
+
+	req, err := http.NewRequest("POST", uploadURL, &requestBody)
+	if err != nil {
+		return fmt.Errorf("failed to create upload request: %v", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.SetBasicAuth(clientConfig.Username, clientConfig.Token)
+
+	httpClient := &http.Client{}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload release metadata: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 503 {
+		return fmt.Errorf("control plane is down. Please try again later. (HTTP 503)")
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("upload failed with status: %s\n%s", resp.Status, string(body))
 	}
+	return nil
 }
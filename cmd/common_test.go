@@ -0,0 +1,603 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Facets-cloud/fctl/pkg/output"
+	"github.com/Facets-cloud/fctl/pkg/utils"
+	"github.com/hashicorp/terraform-exec/tfexec"
+)
+
+// setFlag sets a package-level flag variable for the duration of a test
+func setFlag[T any](t *testing.T, flag *T, value T) {
+	t.Helper()
+	old := *flag
+	*flag = value
+	t.Cleanup(func() { *flag = old })
+}
+
+func TestZipDiffersFromDigest(t *testing.T) {
+	dir := t.TempDir()
+	zipPath := filepath.Join(dir, "export.zip")
+	if err := os.WriteFile(zipPath, []byte("first"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	digestFile := filepath.Join(dir, "p.tfplan"+planZipSuffix)
+
+	if different, err := zipDiffersFromDigest(zipPath, digestFile); err != nil || !different {
+		t.Fatalf("without a digest file: got (%v, %v), want (true, nil)", different, err)
+	}
+	if err := writeZipDigest(zipPath, digestFile); err != nil {
+		t.Fatal(err)
+	}
+	if different, err := zipDiffersFromDigest(zipPath, digestFile); err != nil || different {
+		t.Fatalf("same zip: got (%v, %v), want (false, nil)", different, err)
+	}
+	if err := os.WriteFile(zipPath, []byte("second"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if different, err := zipDiffersFromDigest(zipPath, digestFile); err != nil || !different {
+		t.Fatalf("changed zip: got (%v, %v), want (true, nil)", different, err)
+	}
+}
+
+// TestNewBackendConfigFromFlagsPrecedence checks that each source overrides the ones before it:
+// environment variables, then Vault, then --backend-config-file, then --backend-config
+func TestNewBackendConfigFromFlagsPrecedence(t *testing.T) {
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data": {"key": "vault", "region": "vault", "access_key": "vault", "secret_key": "vault"}}`))
+	}))
+	defer vault.Close()
+
+	t.Setenv("TF_BACKEND_TYPE", "")
+	for _, name := range []string{"BUCKET", "KEY", "REGION", "ACCESS_KEY", "SECRET_KEY"} {
+		t.Setenv("TF_BACKEND_S3_"+name, "env")
+	}
+	t.Setenv("TF_BACKEND_S3_VAULT_PATH", "secret/terraform")
+	file := filepath.Join(t.TempDir(), "backend.hcl")
+	if err := os.WriteFile(file, []byte("region = \"file\"\naccess_key = \"file\"\nsecret_key = \"file\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	setFlag(t, &backendType, "s3")
+	setFlag(t, &backendConfigFile, file)
+	setFlag(t, &backendConfigPairs, []string{"secret_key=flag"})
+	setFlag(t, &vaultAddr, vault.URL)
+	setFlag(t, &vaultToken, "t0ken")
+
+	backendConfig, err := newBackendConfigFromFlags()
+	if err != nil {
+		t.Fatalf("newBackendConfigFromFlags() error = %v", err)
+	}
+	want := map[string]string{"bucket": "env", "key": "vault", "region": "file", "access_key": "file", "secret_key": "flag"}
+	if !reflect.DeepEqual(backendConfig.ConfigVars, want) {
+		t.Errorf("ConfigVars = %v, want %v", backendConfig.ConfigVars, want)
+	}
+}
+
+func TestNewBackendConfigFromFlagsErrors(t *testing.T) {
+	t.Setenv("TF_BACKEND_TYPE", "")
+	tests := []struct {
+		name  string
+		typ   string
+		pairs []string
+		file  string
+	}{
+		{name: "malformed pair", typ: "s3", pairs: []string{"bucket"}},
+		{name: "missing file", typ: "s3", file: filepath.Join(t.TempDir(), "missing.json")},
+		{name: "variables without a backend", pairs: []string{"bucket=state"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setFlag(t, &backendType, tt.typ)
+			setFlag(t, &backendConfigPairs, tt.pairs)
+			setFlag(t, &backendConfigFile, tt.file)
+			if _, err := newBackendConfigFromFlags(); err == nil {
+				t.Error("newBackendConfigFromFlags() succeeded")
+			}
+		})
+	}
+}
+
+func TestResolveVarFiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.WriteFile("prod.tfvars", []byte(`region = "eu-west-1"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir("vars", 0755); err != nil {
+		t.Fatal(err)
+	}
+	absFile := filepath.Join(dir, "prod.tfvars")
+
+	tests := []struct {
+		name    string
+		paths   []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "none", paths: nil, want: nil},
+		{name: "relative path is made absolute", paths: []string{"prod.tfvars"}, want: []string{absFile}},
+		{name: "absolute path", paths: []string{absFile}, want: []string{absFile}},
+		{name: "missing file", paths: []string{"prod.tfvars", "missing.tfvars"}, wantErr: true},
+		{name: "directory", paths: []string{"vars"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveVarFiles(tt.paths)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveVarFiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolveVarFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateVars(t *testing.T) {
+	tests := []struct {
+		name    string
+		vars    []string
+		wantErr string
+	}{
+		{name: "none"},
+		{name: "assignments", vars: []string{"region=eu-west-1", "replicas=3"}},
+		{name: "empty value", vars: []string{"suffix="}},
+		{name: "value with equals signs", vars: []string{`tags={"a"="b"}`}},
+		{name: "missing equals sign", vars: []string{"region=eu-west-1", "replicas"}, wantErr: `"replicas" is missing '='`},
+		{name: "empty name", vars: []string{" =3"}, wantErr: `" =3" has an empty variable name`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateVars(tt.vars)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateVars() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateVars() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// makeAged creates a directory, or a file when content is not nil, at path with a modification time age ago
+func makeAged(t *testing.T, path string, content []byte, age time.Duration) {
+	t.Helper()
+	if content == nil {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+	} else if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(-age)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCleanupOldReleases(t *testing.T) {
+	const (
+		oldest = "11111111-1111-1111-1111-111111111111"
+		middle = "22222222-2222-2222-2222-222222222222"
+		newest = "33333333-3333-3333-3333-333333333333"
+	)
+	tests := []struct {
+		name        string
+		keep        int
+		wantRemoved []string
+	}{
+		{name: "keep 2", keep: 2, wantRemoved: []string{oldest}},
+		{name: "keep 1", keep: 1, wantRemoved: []string{oldest, middle}},
+		{name: "keep more than exist", keep: 5},
+		{name: "0 disables cleanup", keep: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDir := t.TempDir()
+			envDir := filepath.Join(baseDir, "env1")
+			for i, id := range []string{oldest, middle, newest} {
+				age := time.Duration(3-i) * time.Hour
+				makeAged(t, filepath.Join(envDir, id), nil, age)
+				makeAged(t, filepath.Join(baseDir, id+".zip"), []byte("zip"), age)
+			}
+			// State backups and other files in envDir and baseDir are not releases
+			makeAged(t, filepath.Join(envDir, "tf.tfstate.backup-20240101T000000"), []byte("{}"), 10*time.Hour)
+			makeAged(t, filepath.Join(baseDir, "notes.zip"), []byte("zip"), 10*time.Hour)
+
+			cleanupOldReleases(envDir, baseDir, "env1", tt.keep)
+
+			for _, id := range []string{oldest, middle, newest} {
+				removed := slices.Contains(tt.wantRemoved, id)
+				for _, path := range []string{filepath.Join(envDir, id), filepath.Join(baseDir, id+".zip")} {
+					if _, err := os.Stat(path); os.IsNotExist(err) != removed {
+						t.Errorf("%s: removed = %v, want %v", path, os.IsNotExist(err), removed)
+					}
+				}
+			}
+			for _, path := range []string{filepath.Join(envDir, "tf.tfstate.backup-20240101T000000"), filepath.Join(baseDir, "notes.zip")} {
+				if _, err := os.Stat(path); err != nil {
+					t.Errorf("%s was removed", path)
+				}
+			}
+		})
+	}
+}
+
+func TestOldReleaseFilesTrimsRunLogs(t *testing.T) {
+	envDir := t.TempDir()
+	deployDir := filepath.Join(envDir, "33333333-3333-3333-3333-333333333333")
+	makeAged(t, deployDir, nil, 0)
+	for i := 0; i < 3; i++ {
+		makeAged(t, filepath.Join(deployDir, fmt.Sprintf("%s2024010%dT000000.log", runLogPrefix, i)), []byte("log"), time.Duration(3-i)*time.Hour)
+	}
+	makeAged(t, filepath.Join(deployDir, "terraform.log"), []byte("log"), 10*time.Hour)
+
+	got := oldReleaseFiles(envDir, 2)
+	want := []string{filepath.Join(deployDir, runLogPrefix+"20240100T000000.log")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("oldReleaseFiles() = %v, want %v", got, want)
+	}
+}
+
+func TestSelectExistingStateNonInteractive(t *testing.T) {
+	setFlag(t, &NonInteractiveFlag, true)
+	existing := []string{"11111111-1111-1111-1111-111111111111"}
+
+	tfStatePath := filepath.Join(t.TempDir(), "tf.tfstate")
+	useState, selected, err := selectExistingState(existing, tfStatePath)
+	if err != nil || useState || selected != "" {
+		t.Errorf("without tf.tfstate: got (%v, %q, %v), want a fresh state", useState, selected, err)
+	}
+
+	if err := os.WriteFile(tfStatePath, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	useState, selected, err = selectExistingState(existing, tfStatePath)
+	if err != nil || !useState || selected != "__USE_TF_TFSTATE__" {
+		t.Errorf("with tf.tfstate: got (%v, %q, %v), want tf.tfstate", useState, selected, err)
+	}
+}
+
+func TestRefreshPlanOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		refreshOnly bool
+		noRefresh   bool
+		want        []tfexec.PlanOption
+	}{
+		{name: "default refreshes", want: nil},
+		{name: "--refresh-only", refreshOnly: true, want: []tfexec.PlanOption{tfexec.RefreshOnly(true)}},
+		{name: "--no-refresh", noRefresh: true, want: []tfexec.PlanOption{tfexec.Refresh(false)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setFlag(t, &refreshOnly, tt.refreshOnly)
+			setFlag(t, &noRefresh, tt.noRefresh)
+			if got := refreshPlanOptions(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("refreshPlanOptions() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTerraformRunOptions(t *testing.T) {
+	targets := []string{"module.app", "module.db"}
+	varFiles := []string{"/tmp/prod.tfvars"}
+	vars := []string{"region=us-east-1"}
+
+	plan := terraformRunOptions[tfexec.PlanOption](targets, varFiles, vars, 5)
+	wantPlan := []tfexec.PlanOption{
+		tfexec.Target("module.app"),
+		tfexec.Target("module.db"),
+		tfexec.VarFile("/tmp/prod.tfvars"),
+		tfexec.Var("region=us-east-1"),
+		tfexec.Parallelism(5),
+	}
+	if !reflect.DeepEqual(plan, wantPlan) {
+		t.Errorf("terraformRunOptions[PlanOption]() = %#v, want %#v", plan, wantPlan)
+	}
+
+	destroy := terraformRunOptions[tfexec.DestroyOption](targets, nil, nil, 10)
+	wantDestroy := []tfexec.DestroyOption{tfexec.Target("module.app"), tfexec.Target("module.db"), tfexec.Parallelism(10)}
+	if !reflect.DeepEqual(destroy, wantDestroy) {
+		t.Errorf("terraformRunOptions[DestroyOption]() = %#v, want %#v", destroy, wantDestroy)
+	}
+}
+
+func TestResolveBaseDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	cwd := t.TempDir()
+	t.Chdir(cwd)
+	tests := []struct {
+		name string
+		flag string
+		env  string
+		want string
+	}{
+		{name: "default", want: filepath.Join(home, ".facets")},
+		{name: "environment", env: "/data/fctl", want: "/data/fctl"},
+		{name: "flag wins over environment", flag: "/srv/fctl", env: "/data/fctl", want: "/srv/fctl"},
+		{name: "relative flag is made absolute", flag: "deployments", want: filepath.Join(cwd, "deployments")},
+		{name: "relative environment is made absolute", env: "./deployments/", want: filepath.Join(cwd, "deployments")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setFlag(t, &BaseDirFlag, tt.flag)
+			t.Setenv("FCTL_BASE_DIR", tt.env)
+			got, err := resolveBaseDir()
+			if err != nil {
+				t.Fatalf("resolveBaseDir() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveBaseDir() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewDeploymentPaths(t *testing.T) {
+	setFlag(t, &BaseDirFlag, "/data/fctl")
+	paths, err := newDeploymentPaths("env1", "d1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &deploymentPaths{
+		EnvID:        "env1",
+		DeploymentID: "d1",
+		BaseDir:      "/data/fctl",
+		EnvDir:       "/data/fctl/env1",
+		DeployDir:    "/data/fctl/env1/d1",
+		TFWorkDir:    "/data/fctl/env1/d1/tfexport",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("newDeploymentPaths() = %+v, want %+v", paths, want)
+	}
+}
+
+func TestRunLog(t *testing.T) {
+	tests := []struct {
+		name       string
+		logPath    string // relative to a temp dir; empty for the default
+		existing   string
+		runErr     error
+		wantStatus string
+	}{
+		{name: "default path", wantStatus: "fctl apply succeeded after "},
+		{name: "failed run", runErr: fmt.Errorf("❌ Terraform apply failed: exit status 1\n"), wantStatus: "fctl apply failed: Terraform apply failed: exit status 1 after "},
+		{name: "--log-file appends", logPath: "logs/apply.log", existing: "previous run\n", wantStatus: "fctl apply succeeded after "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			paths := &deploymentPaths{EnvID: "env1", DeploymentID: "d1", DeployDir: filepath.Join(dir, "env1", "d1")}
+			logPath := ""
+			if tt.logPath != "" {
+				logPath = filepath.Join(dir, tt.logPath)
+				if tt.existing != "" {
+					if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+						t.Fatal(err)
+					}
+					if err := os.WriteFile(logPath, []byte(tt.existing), 0600); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
+			l, err := openRunLog(paths, logPath, "apply")
+			if err != nil {
+				t.Fatalf("openRunLog() error = %v", err)
+			}
+			defer output.SetLogFile(nil)
+			if logPath == "" {
+				if filepath.Dir(l.path) != paths.DeployDir || !strings.HasPrefix(filepath.Base(l.path), runLogPrefix) || filepath.Ext(l.path) != ".log" {
+					t.Errorf("default log path = %s, want %s/%s<timestamp>.log", l.path, paths.DeployDir, runLogPrefix)
+				}
+			} else if l.path != logPath {
+				t.Errorf("log path = %s, want %s", l.path, logPath)
+			}
+			output.Warnf("⚠️ careful\n")
+			l.Close(tt.runErr)
+			if output.LogWriter() != io.Discard {
+				t.Error("Close() did not stop copying output to the log")
+			}
+
+			content, err := os.ReadFile(l.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := string(content)
+			wantHeader := tt.existing + "fctl apply started at "
+			if !strings.HasPrefix(got, wantHeader) {
+				t.Errorf("log does not start with %q:\n%s", wantHeader, got)
+			}
+			for _, want := range []string{"Environment ID: env1\nDeployment ID: d1\n\n", "⚠️ careful\n", "\n" + tt.wantStatus} {
+				if !strings.Contains(got, want) {
+					t.Errorf("log does not contain %q:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		targets []string
+		wantErr bool
+	}{
+		{name: "none"},
+		{name: "resources and modules", targets: []string{"aws_s3_bucket.logs", "module.cache"}},
+		{name: "quoted key with a space", targets: []string{`aws_s3_bucket.this["my bucket"]`}},
+		{name: "empty address", targets: []string{"aws_s3_bucket.logs", " "}, wantErr: true},
+		{name: "space-separated addresses", targets: []string{"aws_s3_bucket.logs module.cache"}, wantErr: true},
+		{name: "tab outside a key", targets: []string{"module.cache\t"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateTargets(tt.targets); (err != nil) != tt.wantErr {
+				t.Errorf("validateTargets(%q) error = %v, wantErr %v", tt.targets, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateReplaceAddrs(t *testing.T) {
+	tests := []struct {
+		name     string
+		replaces []string
+		targets  []string
+		wantErr  bool
+	}{
+		{name: "none"},
+		{name: "replace only", replaces: []string{"aws_instance.web"}},
+		{name: "replace and a different target", replaces: []string{"aws_instance.web"}, targets: []string{"module.cache"}},
+		{name: "empty address", replaces: []string{""}, wantErr: true},
+		{name: "also targeted", replaces: []string{"aws_instance.web"}, targets: []string{"aws_instance.web"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateReplaceAddrs(tt.replaces, tt.targets); (err != nil) != tt.wantErr {
+				t.Errorf("validateReplaceAddrs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolvePlanFile(t *testing.T) {
+	deployDir := "/data/fctl/env1/d1"
+	tests := []struct {
+		name     string
+		planFile string
+		want     string
+		wantErr  bool
+	}{
+		{name: "relative", planFile: "release.tfplan", want: "/data/fctl/env1/d1/release.tfplan"},
+		{name: "relative subdirectory", planFile: "plans/../release.tfplan", want: "/data/fctl/env1/d1/release.tfplan"},
+		{name: "absolute inside", planFile: "/data/fctl/env1/d1/tfexport/release.tfplan", want: "/data/fctl/env1/d1/tfexport/release.tfplan"},
+		{name: "relative outside", planFile: "../d2/release.tfplan", wantErr: true},
+		{name: "absolute outside", planFile: "/tmp/release.tfplan", wantErr: true},
+		{name: "sibling with the same prefix", planFile: "/data/fctl/env1/d10/release.tfplan", wantErr: true},
+		{name: "parent directory", planFile: "..", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolvePlanFile(deployDir, tt.planFile)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolvePlanFile(%q) error = %v, wantErr %v", tt.planFile, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolvePlanFile(%q) = %q, want %q", tt.planFile, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCopyPairs(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "main.tf")
+	if err := os.WriteFile(source, []byte("# main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		pairs   []string
+		want    []utils.ZipAddition
+		wantErr bool
+	}{
+		{name: "none"},
+		{name: "source and destination", pairs: []string{source + ":tfexport/main.tf"}, want: []utils.ZipAddition{{Source: source, Dest: "tfexport/main.tf"}}},
+		{name: "no separator", pairs: []string{source}, wantErr: true},
+		{name: "empty destination", pairs: []string{source + ":"}, wantErr: true},
+		{name: "missing source", pairs: []string{source + ".missing:tfexport/main.tf"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCopyPairs(tt.pairs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCopyPairs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseCopyPairs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUploadReleaseMetadataFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		noFile  bool
+		wantErr string
+	}{
+		{name: "uploaded", status: http.StatusOK},
+		{name: "control plane down", status: http.StatusServiceUnavailable, wantErr: "HTTP 503"},
+		{name: "rejected", status: http.StatusBadRequest, wantErr: "upload failed with status: 400 Bad Request\nbad metadata"},
+		{name: "no metadata file", noFile: true, wantErr: "failed to open release metadata file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath, gotFile string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				if f, _, err := r.FormFile("file"); err == nil {
+					data, _ := io.ReadAll(f)
+					gotFile = string(data)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte("bad metadata"))
+			}))
+			defer server.Close()
+
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			if err := os.MkdirAll(filepath.Join(home, ".facets"), 0700); err != nil {
+				t.Fatal(err)
+			}
+			creds := fmt.Sprintf("[default]\ncontrol_plane_url = %s\nusername = u\ntoken = t\n", server.URL)
+			if err := os.WriteFile(filepath.Join(home, ".facets", "credentials"), []byte(creds), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(home, ".facets", "config"), []byte("[default]\nprofile = default\n"), 0600); err != nil {
+				t.Fatal(err)
+			}
+			deployDir := t.TempDir()
+			if !tt.noFile {
+				if err := os.WriteFile(filepath.Join(deployDir, "release-metadata.json"), []byte(`[{"name": "web"}]`), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := uploadReleaseMetadataFile(deployDir, "env1", "d1")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("uploadReleaseMetadataFile() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("uploadReleaseMetadataFile() error = %v", err)
+			}
+			if want := "/cc-ui/v1/clusters/env1/deployments/d1/upload-release-metadata"; gotPath != want {
+				t.Errorf("uploaded to %s, want %s", gotPath, want)
+			}
+			if gotFile != `[{"name": "web"}]` {
+				t.Errorf("uploaded file = %q, want the release metadata", gotFile)
+			}
+		})
+	}
+}
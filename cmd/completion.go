@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/Facets-cloud/facets-sdk-go/facets/client/ui_stack_controller"
+	"github.com/Facets-cloud/fctl/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion <bash|zsh|fish|powershell>",
+	Short: "Generate the autocompletion script for the specified shell",
+	Long: `Generate the autocompletion script for fctl for the specified shell and write it to stdout.
+
+Besides commands and flags, the script completes values that fctl looks up when you press tab: --profile from your credentials file, --project and --environment-id from the control plane (using the selected profile), and --environment-id and --deployment of the state and unlock commands from the local deployments in the base directory.
+
+To load completions in the current shell:
+
+  bash:        source <(fctl completion bash)
+  zsh:         source <(fctl completion zsh)
+  fish:        fctl completion fish | source
+  powershell:  fctl completion powershell | Out-String | Invoke-Expression
+
+To load them for every new session, write the script to your shell's completion directory, e.g.:
+
+  bash:  fctl completion bash > /etc/bash_completion.d/fctl
+  zsh:   fctl completion zsh > "${fpath[1]}/_fctl"
+  fish:  fctl completion fish > ~/.config/fish/completions/fctl.fish`,
+	ValidArgs: []string{"bash", "zsh", "fish", "powershell"},
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	// The script goes to stdout and must work before login
+	Annotations: map[string]string{skipAuthAnnotation: "true", noBannerAnnotation: "true"},
+	RunE:        runCompletion,
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+}
+
+func runCompletion(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+	switch args[0] {
+	case "bash":
+		return rootCmd.GenBashCompletionV2(out, true)
+	case "zsh":
+		return rootCmd.GenZshCompletion(out)
+	case "fish":
+		return rootCmd.GenFishCompletion(out, true)
+	case "powershell":
+		return rootCmd.GenPowerShellCompletionWithDesc(out)
+	}
+	return fmt.Errorf("❌ Unsupported shell: %s", args[0])
+}
+
+// isCompletionRequest reports whether cmd is Cobra's hidden command that shells call to get completions.
+func isCompletionRequest(cmd *cobra.Command) bool {
+	return cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd
+}
+
+// completeProfiles completes --profile with the profiles in the credentials file.
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	profiles, err := config.ListProfiles()
+	if err != nil {
+		cobra.CompDebugln(err.Error(), false)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for _, p := range profiles {
+		names = append(names, fmt.Sprintf("%s\t%s", p.Name, p.ControlPlaneURL))
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSettingKeys completes the key argument of the config subcommands with the settings.
+func completeSettingKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var keys []string
+	for _, s := range config.Settings {
+		keys = append(keys, fmt.Sprintf("%s\t%s", s.Key, s.Description))
+	}
+	return keys, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProjects completes --project with the projects (stacks) of the control plane.
+func completeProjects(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	profile, _ := cmd.Flags().GetString("profile")
+	client, auth, err := config.GetClient(profile, false)
+	if err != nil {
+		cobra.CompDebugln(err.Error(), false)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	stacksResp, err := client.UIStackController.GetStacks(ui_stack_controller.NewGetStacksParams(), auth)
+	if err != nil {
+		cobra.CompDebugln(err.Error(), false)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for _, stack := range stacksResp.Payload {
+		names = append(names, fmt.Sprintf("%s\t%s", stack.Name, stack.Cloud))
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeEnvironmentIDs completes --environment-id with the environments of the project given by
+// --project, or of every project when the command has no --project or it is not set.
+func completeEnvironmentIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	profile, _ := cmd.Flags().GetString("profile")
+	client, auth, err := config.GetClient(profile, false)
+	if err != nil {
+		cobra.CompDebugln(err.Error(), false)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var projects []string
+	if project, _ := cmd.Flags().GetString("project"); project != "" {
+		projects = []string{project}
+	} else {
+		stacksResp, err := client.UIStackController.GetStacks(ui_stack_controller.NewGetStacksParams(), auth)
+		if err != nil {
+			cobra.CompDebugln(err.Error(), false)
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		for _, stack := range stacksResp.Payload {
+			projects = append(projects, stack.Name)
+		}
+	}
+
+	var ids []string
+	for _, project := range projects {
+		environments, err := listProjectEnvironments(client, auth, project)
+		if err != nil {
+			cobra.CompDebugln(err.Error(), false)
+			continue
+		}
+		for _, e := range environments {
+			ids = append(ids, fmt.Sprintf("%s\t%s (%s)", e.ID, e.Name, e.Project))
+		}
+	}
+	return ids, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeLocalEnvironmentIDs completes --environment-id with the environments that have a local
+// deployment in the base directory.
+func completeLocalEnvironmentIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	baseDir, err := resolveBaseDir()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	ids, _ := listLocalEnvironments(baseDir)
+	return ids, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeLocalDeploymentIDs completes --deployment with the local deployments of the environment
+// given by --environment-id.
+func completeLocalDeploymentIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	envID, _ := cmd.Flags().GetString("environment-id")
+	if envID == "" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	paths, err := newDeploymentPaths(envID, "")
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	matches, _ := filepath.Glob(filepath.Join(paths.EnvDir, "*", "tfexport"))
+	var ids []string
+	for _, match := range matches {
+		ids = append(ids, filepath.Base(filepath.Dir(match)))
+	}
+	return ids, cobra.ShellCompDirectiveNoFileComp
+}
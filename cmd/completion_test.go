@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Facets-cloud/fctl/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func TestRunCompletion(t *testing.T) {
+	// The command that parses a script without running it, where the shell has one
+	syntaxChecks := map[string][]string{
+		"bash": {"bash", "-n"},
+		"zsh":  {"zsh", "-n"},
+		"fish": {"fish", "--no-execute"},
+	}
+	for _, shell := range []string{"bash", "zsh", "fish", "powershell"} {
+		t.Run(shell, func(t *testing.T) {
+			var buf bytes.Buffer
+			cmd := &cobra.Command{}
+			cmd.SetOut(&buf)
+			if err := runCompletion(cmd, []string{shell}); err != nil {
+				t.Fatalf("runCompletion() error = %v", err)
+			}
+			if !strings.Contains(buf.String(), "fctl") {
+				t.Errorf("the %s script does not mention fctl:\n%s", shell, buf.String())
+			}
+
+			check, ok := syntaxChecks[shell]
+			if !ok {
+				return
+			}
+			if _, err := exec.LookPath(check[0]); err != nil {
+				t.Skipf("%s is not installed, so the script's syntax is not checked", check[0])
+			}
+			script := filepath.Join(t.TempDir(), "fctl."+shell)
+			if err := os.WriteFile(script, buf.Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if out, err := exec.Command(check[0], append(check[1:], script)...).CombinedOutput(); err != nil {
+				t.Errorf("%s rejected the %s script: %v\n%s", strings.Join(check, " "), shell, err, out)
+			}
+		})
+	}
+	if err := runCompletion(&cobra.Command{}, []string{"tcsh"}); err == nil {
+		t.Error("runCompletion(tcsh) succeeded")
+	}
+}
+
+func TestCompleteSettingKeys(t *testing.T) {
+	keys, directive := completeSettingKeys(configGetCmd, nil, "")
+	if len(keys) != len(config.Settings) || directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Fatalf("completeSettingKeys() = %v, %v", keys, directive)
+	}
+	for i, s := range config.Settings {
+		if !strings.HasPrefix(keys[i], s.Key+"\t") {
+			t.Errorf("completion %q does not start with %q and a tab", keys[i], s.Key)
+		}
+	}
+	if keys, _ := completeSettingKeys(configSetCmd, []string{"parallelism"}, ""); keys != nil {
+		t.Errorf("the value of a setting was completed with %v", keys)
+	}
+}
+
+func TestCompleteProfiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	creds := "[default]\ncontrol_plane_url = https://a.example.com\n\n[staging]\ncontrol_plane_url = https://b.example.com\n"
+	if err := os.MkdirAll(filepath.Join(home, ".facets"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".facets", "credentials"), []byte(creds), 0600); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := completeProfiles(rootCmd, nil, "")
+	want := []string{"default\thttps://a.example.com", "staging\thttps://b.example.com"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("completeProfiles() = %q, want %q", got, want)
+	}
+}
+
+func TestCompleteLocalDeployments(t *testing.T) {
+	baseDir := t.TempDir()
+	setFlag(t, &BaseDirFlag, baseDir)
+	for _, dir := range []string{"env1/d1/tfexport", "env1/d2/tfexport", "env2/d3/tfexport", "env3/not-extracted"} {
+		if err := os.MkdirAll(filepath.Join(baseDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	envIDs, _ := completeLocalEnvironmentIDs(rootCmd, nil, "")
+	if want := []string{"env1", "env2"}; !reflect.DeepEqual(envIDs, want) {
+		t.Errorf("completeLocalEnvironmentIDs() = %v, want %v", envIDs, want)
+	}
+
+	cmd := &cobra.Command{Use: "unlock"}
+	cmd.Flags().String("environment-id", "", "")
+	if ids, _ := completeLocalDeploymentIDs(cmd, nil, ""); ids != nil {
+		t.Errorf("completeLocalDeploymentIDs() without --environment-id = %v, want none", ids)
+	}
+	if err := cmd.Flags().Set("environment-id", "env1"); err != nil {
+		t.Fatal(err)
+	}
+	ids, _ := completeLocalDeploymentIDs(cmd, nil, "")
+	if want := []string{"d1", "d2"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("completeLocalDeploymentIDs() = %v, want %v", ids, want)
+	}
+}
+
+func TestIsCompletionRequest(t *testing.T) {
+	tests := map[string]bool{
+		cobra.ShellCompRequestCmd:       true,
+		cobra.ShellCompNoDescRequestCmd: true,
+		"completion":                    false,
+		"apply":                         false,
+	}
+	for name, want := range tests {
+		if got := isCompletionRequest(&cobra.Command{Use: name}); got != want {
+			t.Errorf("isCompletionRequest(%s) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Facets-cloud/fctl/pkg/config"
+	"github.com/Facets-cloud/fctl/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var configListJSON bool
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage fctl's settings.",
+	Long: `Manage the settings in ~/.facets/fctl.ini. Settings are defaults for fctl's flags, such as the base directory or Terraform's parallelism; a flag that is passed, or set with its FCTL_* environment variable, takes precedence over its setting. Credentials and the default profile are kept apart, in ~/.facets/credentials and ~/.facets/config, and are managed with 'fctl login' and 'fctl profile'.
+
+Settings:
+  base_dir         (string) Default of --base-dir
+  keep_releases    (int)    Default of --keep-releases
+  parallelism      (int)    Default of --parallelism for apply, plan, destroy, rollback, and drift detect
+  no_color         (bool)   Default of --no-color
+  non_interactive  (bool)   Default of --non-interactive`,
+	// Settings are local and needed before logging in, e.g. for no_color
+	Annotations: map[string]string{skipAuthAnnotation: "true"},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:               "set <key> <value>",
+	Short:             "Set a setting.",
+	Long:              `Validate a value for a setting and store it in ~/.facets/fctl.ini. Numbers must be non-negative, booleans are true or false, and base_dir is stored as an absolute path.`,
+	Args:              cobra.ExactArgs(2),
+	RunE:              runConfigSet,
+	ValidArgsFunction: completeSettingKeys,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:               "get <key>",
+	Short:             "Print the value of a setting.",
+	Long:              `Print the value of a setting from ~/.facets/fctl.ini. When the setting is not set, nothing is printed and the exit status is 1.`,
+	Args:              cobra.ExactArgs(1),
+	Annotations:       map[string]string{noBannerAnnotation: "true"},
+	RunE:              runConfigGet,
+	ValidArgsFunction: completeSettingKeys,
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:               "unset <key>",
+	Short:             "Remove a setting.",
+	Long:              `Remove a setting from ~/.facets/fctl.ini, so its flag falls back to the built-in default.`,
+	Args:              cobra.ExactArgs(1),
+	RunE:              runConfigUnset,
+	ValidArgsFunction: completeSettingKeys,
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all settings and their values.",
+	Long:  `List every setting with its type and its value in ~/.facets/fctl.ini, or "-" when it is not set.`,
+	RunE:  runConfigList,
+}
+
+// settingSummary is the per-setting record printed by 'config list --json'.
+type settingSummary struct {
+	Key         string `json:"key"`
+	Type        string `json:"type"`
+	Value       string `json:"value,omitempty"`
+	Set         bool   `json:"set"`
+	Description string `json:"description"`
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configListCmd)
+
+	configListCmd.Flags().BoolVar(&configListJSON, "json", false, "Print the settings as a JSON array")
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	if args[1] == "" {
+		return fmt.Errorf("❌ The value of %s must not be empty; use 'fctl config unset %s' to remove it", args[0], args[0])
+	}
+	value, err := config.SetSetting(args[0], args[1])
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+	output.Successf("✅ Set %s to %s\n", args[0], value)
+	return nil
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	if _, err := config.LookupSetting(args[0]); err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+	// Invalid settings were already reported by applySettings
+	values, _, err := config.LoadSettings()
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+	value, ok := values[args[0]]
+	if !ok {
+		exitCode = 1
+		return nil
+	}
+	fmt.Println(value)
+	return nil
+}
+
+func runConfigUnset(cmd *cobra.Command, args []string) error {
+	if _, err := config.SetSetting(args[0], ""); err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+	output.Successf("✅ Unset %s\n", args[0])
+	return nil
+}
+
+func runConfigList(cmd *cobra.Command, args []string) error {
+	// Invalid settings were already reported by applySettings
+	values, _, err := config.LoadSettings()
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+	summaries := make([]settingSummary, 0, len(config.Settings))
+	for _, s := range config.Settings {
+		value, ok := values[s.Key]
+		summaries = append(summaries, settingSummary{Key: s.Key, Type: s.Type, Value: value, Set: ok, Description: s.Description})
+	}
+	if configListJSON {
+		return printJSON(summaries)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tTYPE\tVALUE\tDESCRIPTION")
+	for _, s := range summaries {
+		value := s.Value
+		if !s.Set {
+			value = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Key, s.Type, value, s.Description)
+	}
+	return w.Flush()
+}
+
+// applySettings sets the flags of cmd that were not passed to their values in ~/.facets/fctl.ini.
+// A setting is skipped when its flag was set from an FCTL_* environment variable by BindEnvToFlags.
+// An unreadable file or an invalid setting is only a warning, so that 'fctl config' can still fix it.
+func applySettings(cmd *cobra.Command) error {
+	// keep_releases in ~/.facets/config predates the settings file
+	if moved, err := config.MigrateKeepReleases(); err != nil {
+		output.Warnf("⚠️ %v\n", err)
+	} else if moved != "" {
+		output.Warnf("⚠️ Moved keep_releases = %s from ~/.facets/config to ~/.facets/fctl.ini\n", moved)
+	}
+	values, invalid, err := config.LoadSettings()
+	if err != nil {
+		output.Warnf("⚠️ Ignoring settings: %v\n", err)
+		return nil
+	}
+	for _, err := range invalid {
+		output.Warnf("⚠️ Ignoring setting: %v\n", err)
+	}
+	for _, s := range config.Settings {
+		value, ok := values[s.Key]
+		if !ok {
+			continue
+		}
+		if cmd.Root().PersistentFlags().Lookup(s.Flag) != nil && os.Getenv(flagEnvVar(s.Flag)) != "" {
+			continue
+		}
+		// Setting the value directly leaves the flag marked as not passed
+		if flag := cmd.Flags().Lookup(s.Flag); flag != nil && !flag.Changed {
+			if err := flag.Value.Set(value); err != nil {
+				return fmt.Errorf("invalid %s setting %q: %v", s.Key, value, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Facets-cloud/facets-sdk-go/facets/client/ui_deployment_controller"
+	"github.com/Facets-cloud/fctl/pkg/config"
+	"github.com/Facets-cloud/fctl/pkg/output"
+	"github.com/Facets-cloud/fctl/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	deploymentsEnvironmentID string
+	deploymentsReleaseType   string
+	deploymentsStatus        string
+	deploymentsLimit         int
+	deploymentsOutputFormat  string
+	deploymentsJSON          bool
+	deploymentsDeploymentID  string
+	deploymentsFollow        bool
+)
+
+var deploymentsCmd = &cobra.Command{
+	Use:   "deployments",
+	Short: "Browse the deployments of a Facets environment.",
+	Long:  `Browse the deployments (releases) of a Facets environment, including past Terraform exports.`,
+}
+
+var deploymentsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List deployments for an environment, newest first.",
+	Long:  `List the deployments of an environment with their ID, release type, status, creation time, and duration, newest first. Use --release-type TERRAFORM_EXPORT to find exports that can be downloaded again.`,
+	RunE:  runDeploymentsList,
+}
+
+var deploymentsLogsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Print the error logs of a deployment.",
+	Long:  `Print the error logs recorded for a deployment, such as a failed Terraform export, with their timestamps. Use --follow to keep polling every 5 seconds while the deployment is IN_PROGRESS, like 'kubectl logs -f'; new entries are printed as they appear and a final status line is printed once the deployment finishes.`,
+	RunE:  runDeploymentsLogs,
+}
+
+// deploymentsLogsPollInterval is how often 'deployments logs --follow' checks for new entries.
+const deploymentsLogsPollInterval = 5 * time.Second
+
+// deploymentSummary is the per-deployment record printed by 'deployments list'.
+type deploymentSummary struct {
+	ID          string `json:"id"`
+	ReleaseType string `json:"release_type"`
+	Status      string `json:"status"`
+	CreatedOn   string `json:"created_on"`
+	Duration    string `json:"duration"`
+
+	created time.Time // orders the deployments; CreatedOn is its formatted form
+}
+
+func init() {
+	rootCmd.AddCommand(deploymentsCmd)
+	deploymentsCmd.AddCommand(deploymentsListCmd)
+	deploymentsCmd.AddCommand(deploymentsLogsCmd)
+
+	deploymentsListCmd.Flags().StringVarP(&deploymentsEnvironmentID, "environment-id", "e", "", "The environment to list deployments for (required)")
+	deploymentsListCmd.Flags().StringVar(&deploymentsReleaseType, "release-type", "", "Only show deployments of this release type (e.g. TERRAFORM_EXPORT)")
+	deploymentsListCmd.Flags().StringVar(&deploymentsStatus, "status", "", "Only show deployments with this status (e.g. SUCCEEDED, FAILED, IN_PROGRESS)")
+	deploymentsListCmd.Flags().IntVar(&deploymentsLimit, "limit", 0, "Maximum number of deployments to show (0 for no limit)")
+	deploymentsListCmd.Flags().StringVarP(&deploymentsOutputFormat, "output", "o", "table", "Output format: table or json")
+	deploymentsListCmd.Flags().BoolVar(&deploymentsJSON, "json", false, "Print the deployments as a JSON array (same as --output json)")
+
+	deploymentsListCmd.MarkFlagRequired("environment-id")
+	deploymentsListCmd.RegisterFlagCompletionFunc("environment-id", completeEnvironmentIDs)
+
+	deploymentsLogsCmd.Flags().StringVarP(&deploymentsEnvironmentID, "environment-id", "e", "", "The environment of the deployment (required)")
+	deploymentsLogsCmd.Flags().StringVarP(&deploymentsDeploymentID, "deployment-id", "d", "", "The deployment to print logs for (required)")
+	deploymentsLogsCmd.Flags().BoolVarP(&deploymentsFollow, "follow", "f", false, "Keep printing new log entries until the deployment finishes")
+
+	deploymentsLogsCmd.MarkFlagRequired("environment-id")
+	deploymentsLogsCmd.RegisterFlagCompletionFunc("environment-id", completeEnvironmentIDs)
+	deploymentsLogsCmd.MarkFlagRequired("deployment-id")
+}
+
+func runDeploymentsList(cmd *cobra.Command, args []string) error {
+	if deploymentsJSON {
+		deploymentsOutputFormat = "json"
+	}
+	if err := validateOutputFormat(deploymentsOutputFormat); err != nil {
+		return err
+	}
+	if deploymentsLimit < 0 {
+		return fmt.Errorf("❌ --limit must not be negative")
+	}
+
+	profile, _ := cmd.Flags().GetString("profile")
+	client, auth, err := config.GetClient(profile, false)
+	if err != nil {
+		return fmt.Errorf("❌ Could not get client: %v", err)
+	}
+
+	params := ui_deployment_controller.NewGetDeploymentsParams()
+	params.ClusterID = deploymentsEnvironmentID
+	deploymentsResp, err := client.UIDeploymentController.GetDeployments(params, auth)
+	if err != nil {
+		if isControlPlaneDown(err) {
+			output.Errorf("🔴 The Facets control plane is currently unavailable (HTTP 503). Please try again later.\n")
+		}
+		return fmt.Errorf("❌ Could not get deployments: %v", err)
+	}
+
+	var deployments []deploymentSummary
+	for _, d := range deploymentsResp.Payload.Deployments {
+		deployments = append(deployments, deploymentSummary{
+			ID:          d.ID,
+			ReleaseType: d.ReleaseType,
+			Status:      d.Status,
+			CreatedOn:   formatTimestamp(time.Time(d.CreatedOn)),
+			Duration:    utils.FormatDuration(time.Duration(d.TimeTakenInSeconds) * time.Second),
+			created:     time.Time(d.CreatedOn),
+		})
+	}
+	summaries := filterDeployments(deployments, deploymentsReleaseType, deploymentsStatus, deploymentsLimit)
+
+	if deploymentsOutputFormat == "json" {
+		return printJSON(summaries)
+	}
+
+	if len(summaries) == 0 {
+		output.Infoln("ℹ️ No deployments found.")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tRELEASE TYPE\tSTATUS\tCREATED\tDURATION")
+	for _, d := range summaries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", d.ID, d.ReleaseType, d.Status, d.CreatedOn, d.Duration)
+	}
+	return w.Flush()
+}
+
+// filterDeployments returns the deployments of the given release type and status (any when empty),
+// newest first, up to limit (0 for no limit). deployments is sorted in place.
+func filterDeployments(deployments []deploymentSummary, releaseType, status string, limit int) []deploymentSummary {
+	sort.SliceStable(deployments, func(i, j int) bool {
+		return deployments[i].created.After(deployments[j].created)
+	})
+
+	var filtered []deploymentSummary
+	for _, d := range deployments {
+		if releaseType != "" && !strings.EqualFold(d.ReleaseType, releaseType) {
+			continue
+		}
+		if status != "" && !strings.EqualFold(d.Status, status) {
+			continue
+		}
+		filtered = append(filtered, d)
+		if limit > 0 && len(filtered) == limit {
+			break
+		}
+	}
+	return filtered
+}
+
+func runDeploymentsLogs(cmd *cobra.Command, args []string) error {
+	profile, _ := cmd.Flags().GetString("profile")
+	client, auth, err := config.GetClient(profile, false)
+	if err != nil {
+		return fmt.Errorf("❌ Could not get client: %v", err)
+	}
+
+	params := ui_deployment_controller.NewGetDeploymentParams()
+	params.ClusterID = deploymentsEnvironmentID
+	params.DeploymentID = deploymentsDeploymentID
+
+	printed := 0
+	for {
+		var deploymentResp *ui_deployment_controller.GetDeploymentOK
+		err := utils.RetryableOperation(context.Background(), apiRetryAttempts, func() error {
+			var err error
+			deploymentResp, err = client.UIDeploymentController.GetDeployment(params, auth)
+			return err
+		})
+		if err != nil {
+			if isControlPlaneDown(err) {
+				output.Errorf("🔴 The Facets control plane is currently unavailable (HTTP 503). Please try again later.\n")
+			}
+			return fmt.Errorf("❌ Could not get deployment %s: %v", deploymentsDeploymentID, err)
+		}
+		deployment := deploymentResp.Payload
+
+		// Logs only grow while a deployment runs, so print just the entries not seen yet
+		for _, log := range deployment.ErrorLogs[min(printed, len(deployment.ErrorLogs)):] {
+			if log == nil {
+				continue
+			}
+			if ts := time.Time(log.Timestamp); !ts.IsZero() {
+				fmt.Printf("[%s] %s\n", formatTimestamp(ts), log.ErrorMessage)
+			} else {
+				fmt.Println(log.ErrorMessage)
+			}
+		}
+		printed = max(printed, len(deployment.ErrorLogs))
+
+		if !deploymentsFollow || deployment.Status != "IN_PROGRESS" {
+			printDeploymentStatus(deploymentsDeploymentID, deployment.Status, printed)
+			return nil
+		}
+		time.Sleep(deploymentsLogsPollInterval)
+	}
+}
+
+// printDeploymentStatus prints the closing line of 'deployments logs'.
+func printDeploymentStatus(deploymentID, status string, logCount int) {
+	switch status {
+	case "SUCCEEDED":
+		output.Infof("✅ Deployment %s SUCCEEDED (%d log entries)\n", deploymentID, logCount)
+	case "FAILED":
+		output.Errorf("❌ Deployment %s FAILED (%d log entries)\n", deploymentID, logCount)
+	default:
+		output.Infof("ℹ️ Deployment %s is %s (%d log entries)\n", deploymentID, status, logCount)
+	}
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFilterDeployments(t *testing.T) {
+	now := time.Now()
+	deployments := func() []deploymentSummary {
+		return []deploymentSummary{
+			{ID: "old-export", ReleaseType: "TERRAFORM_EXPORT", Status: "SUCCEEDED", created: now.Add(-3 * time.Hour)},
+			{ID: "new-release", ReleaseType: "RELEASE", Status: "FAILED", created: now.Add(-1 * time.Hour)},
+			{ID: "mid-export", ReleaseType: "TERRAFORM_EXPORT", Status: "FAILED", created: now.Add(-2 * time.Hour)},
+		}
+	}
+	tests := []struct {
+		name        string
+		releaseType string
+		status      string
+		limit       int
+		want        []string
+	}{
+		{name: "all, newest first", want: []string{"new-release", "mid-export", "old-export"}},
+		{name: "release type is case-insensitive", releaseType: "terraform_export", want: []string{"mid-export", "old-export"}},
+		{name: "status", status: "FAILED", want: []string{"new-release", "mid-export"}},
+		{name: "release type and status", releaseType: "TERRAFORM_EXPORT", status: "failed", want: []string{"mid-export"}},
+		{name: "limit applies after filtering", releaseType: "TERRAFORM_EXPORT", limit: 1, want: []string{"mid-export"}},
+		{name: "no match", status: "IN_PROGRESS", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, d := range filterDeployments(deployments(), tt.releaseType, tt.status, tt.limit) {
+				got = append(got, d.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterDeployments() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeploymentSummaryJSON(t *testing.T) {
+	data, err := json.Marshal(deploymentSummary{
+		ID:          "d1",
+		ReleaseType: "TERRAFORM_EXPORT",
+		Status:      "SUCCEEDED",
+		CreatedOn:   "2024-01-02 03:04:05",
+		Duration:    "1m30s",
+		created:     time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"id":"d1","release_type":"TERRAFORM_EXPORT","status":"SUCCEEDED","created_on":"2024-01-02 03:04:05","duration":"1m30s"}`
+	if string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+}
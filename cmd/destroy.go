@@ -1,16 +1,11 @@
 package cmd
 
 import (
-	"bytes"
-	"context"
 	"fmt"
-	"io"
-	"mime/multipart"
-	"net/http"
 	"os"
 	"path/filepath"
 
-	"github.com/Facets-cloud/fctl/pkg/config"
+	"github.com/Facets-cloud/fctl/pkg/output"
 	"github.com/Facets-cloud/fctl/pkg/utils"
 	"github.com/hashicorp/terraform-exec/tfexec"
 	"github.com/spf13/cobra"
@@ -19,7 +14,7 @@ import (
 var destroyCmd = &cobra.Command{
 	Use:   "destroy",
 	Short: "Destroy resources for a Terraform export in your Facets environment.",
-	Long:  `Destroy all resources managed by a Terraform export in your Facets environment. This command mimics 'terraform destroy', supporting state file management and selective module targeting.`,
+	Long:  `Destroy all resources managed by a Terraform export in your Facets environment. This command mimics 'terraform destroy', supporting state file management and selective module targeting. Terraform runs 10 resource operations at once; use --parallelism to change that, for example to lower it for cloud accounts with strict API rate limits.`,
 	RunE:  runDestroy,
 }
 
@@ -28,19 +23,33 @@ func init() {
 
 	// Add flags - reusing the same flags as plan/apply
 	destroyCmd.Flags().StringVarP(&zipPath, "zip", "z", "", "Path to the exported zip file (required)")
-	destroyCmd.Flags().StringVarP(&targetAddr, "target", "t", "", "Module target address for selective releases")
+	destroyCmd.Flags().StringSliceVarP(&targetAddrs, "target", "t", nil, "Module target address for selective releases. Can be specified multiple times or comma-separated.")
 	destroyCmd.Flags().StringVarP(&statePath, "state", "s", "", "Path to the state file")
+	destroyCmd.Flags().StringVar(&backendType, "backend", "", "Terraform backend type for state (s3, gcs, azurerm, consul). Falls back to TF_BACKEND_TYPE")
+	destroyCmd.Flags().StringArrayVar(&backendConfigPairs, "backend-config", nil, "Backend variable in the form key=value, overriding TF_BACKEND_<TYPE>_<KEY>. Can be specified multiple times.")
+	destroyCmd.Flags().StringVar(&backendConfigFile, "backend-config-file", "", "Path to a JSON or HCL file of backend variables (key = value). --backend-config values take precedence")
+	destroyCmd.Flags().StringVar(&vaultAddr, "vault-addr", "", "Address of the Vault server to read backend variables from when TF_BACKEND_<TYPE>_VAULT_PATH is set. Falls back to VAULT_ADDR")
+	destroyCmd.Flags().StringVar(&vaultToken, "vault-token", "", "Token for --vault-addr. Falls back to VAULT_TOKEN")
+	destroyCmd.Flags().StringArrayVar(&varFiles, "var-file", nil, "Path to a Terraform variables file (.tfvars). Can be specified multiple times.")
+	destroyCmd.Flags().StringArrayVar(&tfVars, "var", nil, "Set a Terraform variable, in the form name=value. Can be specified multiple times.")
+	destroyCmd.Flags().StringVar(&decryptPassphrase, "decrypt", "", "Passphrase of a zip encrypted with 'fctl repackage --encrypt'")
+	destroyCmd.Flags().StringVar(&zipChecksum, "checksum", "", "Expected digest of the zip file, in the form sha256:<hex>")
+	destroyCmd.Flags().IntVar(&tfParallelism, "parallelism", 10, "Number of concurrent Terraform operations. Lower it for cloud accounts with strict API rate limits")
+	destroyCmd.Flags().DurationVar(&tfTimeout, "timeout", 0, "Stop Terraform if it runs longer than this (e.g. 90m, 2h); 0 means no limit")
+	destroyCmd.Flags().BoolVar(&noStateBackup, "no-backup", false, "Do not back up the local state before destroying")
+	destroyCmd.Flags().IntVar(&stateBackupCount, "backup-count", 5, "Number of local state backups to keep per environment (0 keeps all)")
+	destroyCmd.Flags().StringVar(&runLogPath, "log-file", "", "Write the run log to this file instead of <deployment-dir>/fctl-run-<timestamp>.log")
 	destroyCmd.Flags().BoolVar(&uploadReleaseMetadata, "upload-release-metadata", false, "Upload release metadata to control plane after apply")
+	destroyCmd.Flags().BoolVar(&jsonOutput, "json", false, "Suppress progress output and print a single JSON result when done")
 
 	destroyCmd.MarkFlagRequired("zip")
 }
 
-func runDestroy(cmd *cobra.Command, args []string) error {
-	allowDestroy, _ := cmd.Flags().GetBool("allow-destroy")
-	fmt.Println("🔥 Starting terraform destroy process...")
+func runDestroy(cmd *cobra.Command, args []string) (err error) {
+	output.Infoln("🔥 Starting terraform destroy process...")
 
 	// Initialize backend configuration
-	backendConfig, err := config.NewBackendConfig()
+	backendConfig, err := newBackendConfigFromFlags()
 	if err != nil {
 		return fmt.Errorf("❌ Failed to initialize backend configuration: %v", err)
 	}
@@ -50,47 +59,56 @@ func runDestroy(cmd *cobra.Command, args []string) error {
 		if err := backendConfig.Validate(); err != nil {
 			return fmt.Errorf("❌ Invalid backend configuration: %v", err)
 		}
-		fmt.Printf("🔐 Using %s backend for state management\n", backendConfig.Type)
+		output.Infof("🔐 Using %s backend for state management\n", backendConfig.Type)
 	}
 
-	// Extract deployment ID from zip filename
-	deploymentID, err := utils.ExtractDeploymentID(zipPath)
+	// Validate variables before doing any extraction work
+	resolvedVarFiles, err := resolveVarFiles(varFiles)
 	if err != nil {
-		return fmt.Errorf("❌ Failed to extract deployment ID: %v", err)
+		return fmt.Errorf("❌ Invalid --var-file: %v", err)
 	}
-
-	// Unzip to a temp dir to read deploymentcontext.json
-	tempDir, err := os.MkdirTemp("", "fctl-unzip-*")
-	if err != nil {
-		return fmt.Errorf("❌ Failed to create temp dir: %v", err)
+	if err := validateVars(tfVars); err != nil {
+		return fmt.Errorf("❌ Invalid --var: %v", err)
+	}
+	if err := validateTargets(targetAddrs); err != nil {
+		return fmt.Errorf("❌ Invalid --target: %v", err)
 	}
-	defer os.RemoveAll(tempDir)
-	if err := utils.ExtractZip(zipPath, tempDir); err != nil {
-		return fmt.Errorf("❌ Failed to extract zip: %v", err)
+	if tfParallelism < 1 {
+		return fmt.Errorf("❌ Invalid --parallelism: must be at least 1, got %d", tfParallelism)
 	}
-	envID, err := utils.ExtractEnvIDFromDeploymentContext(tempDir)
+
+	// Verify the zip before anything is extracted
+	exportZip, removeDecrypted, err := openExportZip(zipPath, zipChecksum, decryptPassphrase)
 	if err != nil {
-		return fmt.Errorf("❌ Failed to extract environment ID from deploymentcontext.json: %v", err)
+		return fmt.Errorf("❌ %v", err)
 	}
-	fmt.Printf("🌍 Environment ID: %s\n", envID)
-	fmt.Printf("🆔 Deployment ID: %s\n", deploymentID)
+	defer removeDecrypted()
 
-	// Create base directory structure
-	homeDir, err := os.UserHomeDir()
+	// Resolve the environment and the local directories for this deployment
+	paths, err := resolveDeploymentPaths(exportZip)
 	if err != nil {
-		return fmt.Errorf("❌ Failed to get home directory: %v", err)
+		return fmt.Errorf("❌ %v", err)
 	}
-	baseDir := filepath.Join(homeDir, ".facets")
-	envDir := filepath.Join(baseDir, envID)
+	envID, deploymentID := paths.EnvID, paths.DeploymentID
+	output.Infof("🌍 Environment ID: %s\n", envID)
+	output.Infof("🆔 Deployment ID: %s\n", deploymentID)
+	output.Result().EnvironmentID = envID
+	output.Result().DeploymentID = deploymentID
 
 	// Cleanup old releases (directories and zips)
-	cleanupOldReleases(envDir, baseDir, envID)
+	cleanupOldReleases(paths.EnvDir, paths.BaseDir, envID, KeepReleasesFlag)
 
-	deployDir := filepath.Join(envDir, deploymentID)
-	tfWorkDir := filepath.Join(deployDir, "tfexport")
+	envDir, deployDir, tfWorkDir := paths.EnvDir, paths.DeployDir, paths.TFWorkDir
+
+	runLog, err := openRunLog(paths, runLogPath, "destroy")
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+	defer func() { runLog.Close(err) }()
+	output.Result().OutputPath = deployDir
 
 	// Create directories
-	fmt.Printf("📁 Creating deployment directory for environment %s and deployment %s...\n", envID, deploymentID)
+	output.Infof("📁 Creating deployment directory for environment %s and deployment %s...\n", envID, deploymentID)
 	if err := os.MkdirAll(deployDir, 0755); err != nil {
 		return fmt.Errorf("❌ Failed to create directories: %v", err)
 	}
@@ -106,13 +124,13 @@ func runDestroy(cmd *cobra.Command, args []string) error {
 				return fmt.Errorf("❌ Failed to list existing deployments: %v", err)
 			}
 			if len(existingDeployments) > 0 {
-				proceed, selectedDeployment, err := utils.PromptUser(existingDeployments, tfStatePath)
+				proceed, selectedDeployment, err := selectExistingState(existingDeployments, tfStatePath)
 				if err != nil {
 					return fmt.Errorf("❌ User input error: %v", err)
 				}
 				if proceed {
 					if selectedDeployment == "__USE_TF_TFSTATE__" {
-						fmt.Println("📝 Using tf.tfstate for this deployment...")
+						output.Infoln("📝 Using tf.tfstate for this deployment...")
 						stateDir := filepath.Join(tfWorkDir, "terraform.tfstate.d", envID)
 						if err := os.MkdirAll(stateDir, 0755); err != nil {
 							return fmt.Errorf("❌ Failed to create state directory: %v", err)
@@ -122,7 +140,7 @@ func runDestroy(cmd *cobra.Command, args []string) error {
 							return fmt.Errorf("❌ Failed to copy tf.tfstate: %v", err)
 						}
 					} else {
-						fmt.Println("🔄 User chose to proceed with state file from existing deployment")
+						output.Infoln("🔄 User chose to proceed with state file from existing deployment")
 						if err := utils.CopyStateFromPreviousDeployment(envDir, deploymentID, envID, selectedDeployment); err != nil {
 							return fmt.Errorf("❌ Failed to copy state file: %v", err)
 						}
@@ -130,46 +148,37 @@ func runDestroy(cmd *cobra.Command, args []string) error {
 				}
 			}
 		} else {
-			fmt.Printf("ℹ️  Using %s backend for state management\n", backendConfig.Type)
+			output.Infof("ℹ️  Using %s backend for state management\n", backendConfig.Type)
 		}
 		// Now extract zip contents to deployDir
-		fmt.Println("📦 Extracting terraform configuration...")
-		if err := utils.ExtractZip(zipPath, deployDir); err != nil {
-			return fmt.Errorf("❌ Failed to extract zip: %v", err)
-		}
-		// Fix permissions after extraction
-		if err := utils.FixPermissions(tfWorkDir); err != nil {
-			return fmt.Errorf("❌ Failed to fix permissions: %v", err)
+		output.Infoln("📦 Extracting terraform configuration...")
+		if err := extractDeployment(exportZip, paths); err != nil {
+			return fmt.Errorf("❌ %v", err)
 		}
 	} else {
-		fmt.Println("♻️ Using existing deployment directory")
-		// Check if zip contents differ from deployDir
-		different, err := utils.IsZipDifferentFromDir(zipPath, deployDir)
+		output.Infoln("♻️ Using existing deployment directory")
+		// Check if the zip changed since it was extracted to deployDir
+		different, err := zipChangedSinceExtract(exportZip, paths)
 		if err != nil {
-			return fmt.Errorf("❌ Failed to compare zip and directory: %v", err)
+			return fmt.Errorf("❌ Failed to compare zip with the deployment directory: %v", err)
 		}
 		if different {
-			fmt.Println("📦 Changes detected in zip, extracting to deployment directory...")
-			if err := utils.ExtractZip(zipPath, deployDir); err != nil {
-				return fmt.Errorf("❌ Failed to extract zip: %v", err)
-			}
-			// Fix permissions after extraction
-			if err := utils.FixPermissions(tfWorkDir); err != nil {
-				return fmt.Errorf("❌ Failed to fix permissions: %v", err)
+			output.Infoln("📦 Changes detected in zip, extracting to deployment directory...")
+			if err := extractDeployment(exportZip, paths); err != nil {
+				return fmt.Errorf("❌ %v", err)
 			}
 		} else {
-			fmt.Println("✅ No changes detected in zip, skipping extraction.")
+			output.Infoln("✅ No changes detected in zip, skipping extraction.")
 		}
 	}
-	if allowDestroy {
-		fmt.Println("🔒 Enforcing prevent_destroy = false in all Terraform resources...")
-		if err := utils.UpdatePreventDestroyInTFs(tfWorkDir); err != nil {
-			return fmt.Errorf("❌ Failed to update prevent_destroy in .tf files: %v", err)
-		}
+	// Nothing can be destroyed while prevent_destroy = true, so destroy always implies --allow-destroy
+	output.Infoln("🔓 Setting prevent_destroy = false in all Terraform resources (implied by destroy)...")
+	if err := utils.UpdatePreventDestroyInTFs(tfWorkDir, false); err != nil {
+		return fmt.Errorf("❌ Failed to update prevent_destroy in .tf files: %v", err)
 	}
 
 	// Initialize terraform
-	fmt.Println("🔧 Initializing terraform...")
+	output.Infoln("🔧 Initializing terraform...")
 	tf, err := tfexec.NewTerraform(tfWorkDir, "terraform")
 	if err != nil {
 		return fmt.Errorf("❌ Failed to create terraform executor: %v", err)
@@ -177,12 +186,12 @@ func runDestroy(cmd *cobra.Command, args []string) error {
 
 	// set logging for terraform
 	tf.SetLog("INFO")
-	tf.SetStderr(os.Stdout)
-	tf.SetStdout(os.Stdout)
+	tf.SetStderr(output.Writer())
+	tf.SetStdout(output.Writer())
 
 	// Handle state file
 	if statePath != "" && backendConfig == nil {
-		fmt.Println("📝 Copying provided state file...")
+		output.Infoln("📝 Copying provided state file...")
 		stateDir := filepath.Join(tfWorkDir, "terraform.tfstate.d", envID)
 		if err := os.MkdirAll(stateDir, 0755); err != nil {
 			return fmt.Errorf("❌ Failed to create state directory: %v", err)
@@ -196,129 +205,97 @@ func runDestroy(cmd *cobra.Command, args []string) error {
 
 	// Initialize terraform with backend configuration if provided
 	if backendConfig != nil {
-		fmt.Printf("🔄 Writing backend.tf.json for %s backend...\n", backendConfig.Type)
+		output.Infof("🔄 Writing backend.tf.json for %s backend...\n", backendConfig.Type)
 		if err := backendConfig.WriteBackendTFJSON(tfWorkDir); err != nil {
 			return fmt.Errorf("❌ Failed to write backend.tf.json: %v", err)
 		}
 	}
-	if err := tf.Init(context.Background()); err != nil {
-		return fmt.Errorf("❌ Terraform init failed: %v", err)
+	ctx, cancel := terraformContext()
+	defer cancel()
+	if err := tf.Init(ctx); err != nil {
+		return terraformError(ctx, "init", err)
 	}
 
 	// Select workspace/environment
-	if err := tf.WorkspaceSelect(context.Background(), envID); err != nil {
+	if err := tf.WorkspaceSelect(ctx, envID); err != nil {
 		// If workspace doesn't exist, create it
-		if err := tf.WorkspaceNew(context.Background(), envID); err != nil {
+		if err := tf.WorkspaceNew(ctx, envID); err != nil {
 			return fmt.Errorf("❌ Failed to create workspace: %v", err)
 		}
 	}
 
 	// Run terraform destroy
-	destroyOptions := []tfexec.DestroyOption{}
-	if targetAddr != "" {
-		fmt.Printf("🎯 Targeting module: %s\n", targetAddr)
-		destroyOptions = append(destroyOptions, tfexec.Target(targetAddr))
+	destroyOptions := terraformRunOptions[tfexec.DestroyOption](targetAddrs, resolvedVarFiles, tfVars, tfParallelism)
+
+	if backendConfig == nil && !noStateBackup {
+		backupPath, err := backupLocalState(envDir, tfWorkDir, envID, stateBackupCount)
+		if err != nil {
+			return fmt.Errorf("❌ Failed to back up state: %v", err)
+		}
+		if backupPath != "" {
+			output.Infof("💾 State backed up to: %s\n", backupPath)
+		}
 	}
 
-	fmt.Println("💥 Running terraform destroy...")
-	if err := tf.Destroy(context.Background(), destroyOptions...); err != nil {
+	output.Infoln("💥 Running terraform destroy...")
+	if err := tf.Destroy(ctx, destroyOptions...); err != nil {
 		if backendConfig == nil {
-			fmt.Printf("💾 State file location: %s/terraform.tfstate.d/%s/terraform.tfstate\n", tfWorkDir, envID)
+			output.Infof("💾 State file location: %s/terraform.tfstate.d/%s/terraform.tfstate\n", tfWorkDir, envID)
 			// Save latest state for this environment
 			latestStatePath := filepath.Join(envDir, "tf.tfstate")
 			currentStatePath := filepath.Join(tfWorkDir, "terraform.tfstate.d", envID, "terraform.tfstate")
 			if _, err := os.Stat(currentStatePath); err == nil {
 				if err := utils.CopyFile(currentStatePath, latestStatePath); err != nil {
-					fmt.Printf("⚠️ Warning: Failed to save latest state: %v\n", err)
+					output.Warnf("⚠️ Warning: Failed to save latest state: %v\n", err)
 				} else {
-					fmt.Printf("📝 Latest state saved to: %s\n", latestStatePath)
+					output.Infof("📝 Latest state saved to: %s\n", latestStatePath)
 				}
 			}
 		}
-		return fmt.Errorf("❌ Terraform destroy failed: %v", err)
+		if terraformTimedOut(ctx) {
+			// Record whatever was destroyed before Terraform was stopped
+			output.Infoln("📊 Generating release metadata from the partial state...")
+			if err := utils.GenerateReleaseMetadata(tf, deployDir); err != nil {
+				output.Warnf("⚠️ Warning: Failed to generate release metadata: %v\n", err)
+			}
+		}
+		return terraformError(ctx, "destroy", err)
 	}
 
 	// Generate release metadata
-	fmt.Println("📊 Generating release metadata...")
+	output.Infoln("📊 Generating release metadata...")
 	if err := utils.GenerateReleaseMetadata(tf, deployDir); err != nil {
-		fmt.Printf("⚠️ Warning: Failed to generate release metadata: %v\n", err)
+		output.Warnf("⚠️ Warning: Failed to generate release metadata: %v\n", err)
 	}
 
-	// Upload release metadata if flag is set
+	// Upload release metadata if flag is set. A failed upload fails the command, but only after the
+	// state has been saved below.
+	var uploadErr error
 	if uploadReleaseMetadata {
-		fmt.Println("☁️ Uploading release metadata to control plane...")
-		metadataFile := filepath.Join(deployDir, "release-metadata.json")
-		f, err := os.Open(metadataFile)
-		if err != nil {
-			fmt.Printf("❌ Failed to open release metadata file: %v\n", err)
-		} else {
-			defer f.Close()
-			var requestBody bytes.Buffer
-			writer := multipart.NewWriter(&requestBody)
-			part, err := writer.CreateFormFile("file", filepath.Base(f.Name()))
-			if err != nil {
-				fmt.Printf("❌ Failed to create multipart form file: %v\n", err)
-				return nil
-			}
-			_, err = io.Copy(part, f)
-			if err != nil {
-				fmt.Printf("❌ Failed to copy file to multipart writer: %v\n", err)
-				return nil
-			}
-			writer.Close()
-
-			// Build the upload URL (replace with actual endpoint if needed)
-			clientConfig := config.GetClientConfig("") // use the correct profile if needed
-			if clientConfig == nil {
-				fmt.Printf("❌ Could not get client configuration\n")
-				return nil
-			}
-			uploadURL := clientConfig.ControlPlaneURL + "/cc-ui/v1/clusters/" + envID + "/deployments/" + deploymentID + "/upload-release-metadata"
-
-			req, err := http.NewRequest("POST", uploadURL, &requestBody)
-			if err != nil {
-				fmt.Printf("❌ Failed to create upload request: %v\n", err)
-				return nil
-			}
-			req.Header.Set("Content-Type", writer.FormDataContentType())
-			req.SetBasicAuth(clientConfig.Username, clientConfig.Token)
-
-			httpClient := &http.Client{}
-			resp, err := httpClient.Do(req)
-			if err != nil {
-				fmt.Printf("❌ Failed to upload release metadata: %v\n", err)
-				return nil
-			}
-			defer resp.Body.Close()
-
-			if resp.StatusCode == 503 {
-				fmt.Printf("❌ Control plane is down. Please try again later. (HTTP 503)\n")
-				return nil
-			}
-			if resp.StatusCode != http.StatusOK {
-				body, _ := io.ReadAll(resp.Body)
-				fmt.Printf("❌ Upload failed with status: %s\n%s\n", resp.Status, string(body))
-			} else {
-				fmt.Println("✅ Release metadata uploaded to control plane.")
-			}
+		output.Infoln("☁️ Uploading release metadata to control plane...")
+		if uploadErr = uploadReleaseMetadataFile(deployDir, envID, deploymentID); uploadErr == nil {
+			output.Infoln("✅ Release metadata uploaded to control plane.")
 		}
 	}
 
-	fmt.Printf("✅ Successfully destroyed terraform-managed resources!\n")
-	fmt.Printf("📍 Deployment directory: %s\n", deployDir)
+	output.Successf("✅ Successfully destroyed terraform-managed resources!\n")
+	output.Infof("📍 Deployment directory: %s\n", deployDir)
 	if backendConfig == nil {
-		fmt.Printf("💾 State file location: %s/terraform.tfstate.d/%s/terraform.tfstate\n", tfWorkDir, envID)
+		output.Infof("💾 State file location: %s/terraform.tfstate.d/%s/terraform.tfstate\n", tfWorkDir, envID)
 		// Save latest state for this environment
 		latestStatePath := filepath.Join(envDir, "tf.tfstate")
 		currentStatePath := filepath.Join(tfWorkDir, "terraform.tfstate.d", envID, "terraform.tfstate")
 		if _, err := os.Stat(currentStatePath); err == nil {
 			if err := utils.CopyFile(currentStatePath, latestStatePath); err != nil {
-				fmt.Printf("⚠️ Warning: Failed to save latest state: %v\n", err)
+				output.Warnf("⚠️ Warning: Failed to save latest state: %v\n", err)
 			} else {
-				fmt.Printf("📝 Latest state saved to: %s\n", latestStatePath)
+				output.Infof("📝 Latest state saved to: %s\n", latestStatePath)
 			}
 		}
 	}
 
+	if uploadErr != nil {
+		return fmt.Errorf("❌ Terraform destroy succeeded, but the release metadata upload failed: %v", uploadErr)
+	}
 	return nil
 }
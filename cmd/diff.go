@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Facets-cloud/fctl/pkg/output"
+	"github.com/Facets-cloud/fctl/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffOldZip    string
+	diffNewZip    string
+	diffStatsOnly bool
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show the changes to the Terraform files between two exported zips.",
+	Long: `Extract two exported zips and print a unified diff of every .tf and .tf.json file that was added, removed, or changed between them (anything under .terraform/ is skipped). Use it to review what a new export changes before applying it.
+
+With --stats-only, only the number of added and removed lines per file is printed, like 'git diff --stat'.`,
+	Annotations: map[string]string{skipAuthAnnotation: "true"},
+	RunE:        runDiff,
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringVar(&diffOldZip, "old", "", "Path to the older exported zip file (required)")
+	diffCmd.Flags().StringVar(&diffNewZip, "new", "", "Path to the newer exported zip file (required)")
+	diffCmd.Flags().BoolVar(&diffStatsOnly, "stats-only", false, "Print only the number of added and removed lines per file")
+
+	diffCmd.MarkFlagRequired("old")
+	diffCmd.MarkFlagRequired("new")
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	oldFiles, err := readZipTerraformFiles(diffOldZip)
+	if err != nil {
+		return fmt.Errorf("❌ Failed to read %s: %v", diffOldZip, err)
+	}
+	newFiles, err := readZipTerraformFiles(diffNewZip)
+	if err != nil {
+		return fmt.Errorf("❌ Failed to read %s: %v", diffNewZip, err)
+	}
+
+	var names []string
+	for name := range oldFiles {
+		names = append(names, name)
+	}
+	for name := range newFiles {
+		if _, ok := oldFiles[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	type fileStat struct {
+		name           string
+		added, removed int
+	}
+	var stats []fileStat
+	totalAdded, totalRemoved := 0, 0
+	for _, name := range names {
+		before, after := oldFiles[name], newFiles[name]
+		if before == after {
+			continue
+		}
+		added, removed := utils.DiffStat(before, after)
+		stats = append(stats, fileStat{name, added, removed})
+		totalAdded += added
+		totalRemoved += removed
+		if !diffStatsOnly {
+			printColoredDiff(utils.UnifiedDiff(name, before, after))
+		}
+	}
+
+	if len(stats) == 0 {
+		output.Infoln("✅ No differences in the Terraform files")
+		return nil
+	}
+	if diffStatsOnly {
+		width := 0
+		for _, s := range stats {
+			width = max(width, len(s.name))
+		}
+		for _, s := range stats {
+			bar := ""
+			if n := scaledDiffBar(s.added, totalAdded+totalRemoved); n > 0 {
+				bar += output.Colorize(output.Green, strings.Repeat("+", n))
+			}
+			if n := scaledDiffBar(s.removed, totalAdded+totalRemoved); n > 0 {
+				bar += output.Colorize(output.Red, strings.Repeat("-", n))
+			}
+			fmt.Printf(" %-*s | %5d %s\n", width, s.name, s.added+s.removed, bar)
+		}
+	}
+	output.Successf("📊 %d file(s) changed, %d insertion(s)(+), %d deletion(s)(-)\n", len(stats), totalAdded, totalRemoved)
+	return nil
+}
+
+// diffBarWidth is the width of the +/- bar of --stats-only for the file with the most changed lines
+const diffBarWidth = 40
+
+// scaledDiffBar returns the length of the bar for n changed lines, scaled down when total lines changed
+// would not fit in diffBarWidth. Any change is shown with at least one character.
+func scaledDiffBar(n, total int) int {
+	if n == 0 || total <= diffBarWidth {
+		return n
+	}
+	return max(n*diffBarWidth/total, 1)
+}
+
+// printColoredDiff prints a unified diff with added lines in green, removed lines in red, and hunk headers in magenta.
+func printColoredDiff(diff string) {
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "):
+			fmt.Println(line)
+		case strings.HasPrefix(line, "@@"):
+			fmt.Println(output.Colorize(output.Magenta, line))
+		case strings.HasPrefix(line, "+"):
+			fmt.Println(output.Colorize(output.Green, line))
+		case strings.HasPrefix(line, "-"):
+			fmt.Println(output.Colorize(output.Red, line))
+		default:
+			fmt.Println(line)
+		}
+	}
+}
+
+// readZipTerraformFiles extracts zipPath to a temporary directory and returns the contents of its .tf and
+// .tf.json files, keyed by their path in the zip. Anything under .terraform/ is skipped.
+func readZipTerraformFiles(zipPath string) (map[string]string, error) {
+	tempDir, err := os.MkdirTemp("", "fctl-diff-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	if err := utils.ExtractZip(zipPath, tempDir); err != nil {
+		return nil, fmt.Errorf("failed to extract zip: %v", err)
+	}
+
+	files := make(map[string]string)
+	err = filepath.WalkDir(tempDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".terraform" {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !(strings.HasSuffix(path, ".tf") || strings.HasSuffix(path, ".tf.json")) {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		relPath, _ := filepath.Rel(tempDir, path)
+		files[filepath.ToSlash(relPath)] = string(content)
+		return nil
+	})
+	return files, err
+}
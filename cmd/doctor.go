@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Facets-cloud/fctl/pkg/config"
+	"github.com/Facets-cloud/fctl/pkg/output"
+	"github.com/Facets-cloud/fctl/pkg/utils"
+	"github.com/spf13/cobra"
+	"gopkg.in/ini.v1"
+)
+
+// minFreeDiskSpace is the free space in the base directory below which doctor warns
+const minFreeDiskSpace = 1 << 30
+
+// tokenExpiryWarning is how soon before a token expires doctor starts warning about it
+const tokenExpiryWarning = 24 * time.Hour
+
+type doctorStatus int
+
+const (
+	doctorOK doctorStatus = iota
+	doctorWarn
+	doctorFail
+)
+
+// doctorCheck is the result of one doctor check. Fix is the command or step that resolves a warning or failure.
+type doctorCheck struct {
+	Name   string
+	Status doctorStatus
+	Detail string
+	Fix    string
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check fctl's prerequisites and configuration and suggest fixes.",
+	Long: `Check everything fctl needs and print a report with ✅, ⚠️, or ❌ per check: the profile, the credentials file, the token's expiry, connectivity to the control plane, the Terraform binary, and the permissions and free disk space of the base directory. Every warning and failure is followed by the command or step that fixes it.
+
+The command exits with 1 if any check fails; warnings do not change the exit code.`,
+	// doctor reports authentication problems itself instead of failing before it runs
+	Annotations: map[string]string{skipAuthAnnotation: "true"},
+	RunE:        runDoctor,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	profileFlag, _ := cmd.Flags().GetString("profile")
+
+	var checks []doctorCheck
+	profileName, profileCheck := checkDoctorProfile(profileFlag)
+	checks = append(checks, profileCheck)
+	credentialsCheck, profile := checkDoctorCredentials(profileName)
+	checks = append(checks, credentialsCheck)
+	if profile != nil {
+		checks = append(checks, checkDoctorTokenExpiry(profile), checkDoctorControlPlane(profile))
+	}
+	checks = append(checks, checkDoctorTerraform())
+	checks = append(checks, checkDoctorBaseDir()...)
+
+	failures, warnings := 0, 0
+	for _, c := range checks {
+		printDoctorCheck(c)
+		switch c.Status {
+		case doctorFail:
+			failures++
+		case doctorWarn:
+			warnings++
+		}
+	}
+	fmt.Println()
+	if failures > 0 {
+		return fmt.Errorf("❌ %d check(s) failed, %d warning(s)", failures, warnings)
+	}
+	if warnings > 0 {
+		fmt.Printf("⚠️ No failures, %d warning(s)\n", warnings)
+		return nil
+	}
+	fmt.Println("✅ fctl is ready to use")
+	return nil
+}
+
+// printDoctorCheck prints a check with its icon in the check's color, followed by the fix when there is one.
+func printDoctorCheck(c doctorCheck) {
+	icon, color := "✅", output.Green
+	switch c.Status {
+	case doctorWarn:
+		icon, color = "⚠️", output.Yellow
+	case doctorFail:
+		icon, color = "❌", output.Red
+	}
+	fmt.Printf("%s %s: %s\n", icon, output.Colorize(color, c.Name), c.Detail)
+	if c.Fix != "" && c.Status != doctorOK {
+		fmt.Printf("   → %s\n", c.Fix)
+	}
+}
+
+// checkDoctorProfile checks that a profile is given with --profile or set as the default.
+func checkDoctorProfile(profileFlag string) (string, doctorCheck) {
+	check := doctorCheck{Name: "Profile"}
+	profileName, err := config.ResolveProfileName(profileFlag)
+	if err != nil {
+		check.Status = doctorFail
+		check.Detail = err.Error()
+		check.Fix = "Run 'fctl login' to create a profile, or 'fctl profile set-default <name>' to choose one"
+		return "", check
+	}
+	check.Detail = profileName
+	if profileFlag == "" {
+		check.Detail += " (default)"
+	}
+	return profileName, check
+}
+
+// checkDoctorCredentials checks that the credentials file exists, parses, and has a complete entry for the profile.
+func checkDoctorCredentials(profileName string) (doctorCheck, *config.Profile) {
+	check := doctorCheck{Name: "Credentials", Fix: "Run 'fctl login' to write your credentials"}
+	credsPath, err := config.CredentialsPath()
+	if err != nil {
+		check.Status = doctorFail
+		check.Detail = err.Error()
+		return check, nil
+	}
+	if _, err := os.Stat(credsPath); os.IsNotExist(err) {
+		check.Status = doctorFail
+		check.Detail = fmt.Sprintf("%s does not exist", credsPath)
+		return check, nil
+	}
+	if _, err := ini.Load(credsPath); err != nil {
+		check.Status = doctorFail
+		check.Detail = fmt.Sprintf("%s could not be parsed: %v", credsPath, err)
+		check.Fix = fmt.Sprintf("Fix or remove %s, then run 'fctl login'", credsPath)
+		return check, nil
+	}
+	if profileName == "" {
+		check.Detail = credsPath
+		return check, nil
+	}
+
+	profile, err := config.GetProfile(profileName)
+	if err != nil {
+		check.Status = doctorFail
+		check.Detail = err.Error()
+		check.Fix = fmt.Sprintf("Run 'fctl login --profile %s' to create it", profileName)
+		return check, nil
+	}
+	if profile.ControlPlaneURL == "" || profile.Username == "" || profile.Token == "" {
+		check.Status = doctorFail
+		check.Detail = fmt.Sprintf("profile '%s' is missing one of control_plane_url, username, or token", profileName)
+		check.Fix = fmt.Sprintf("Run 'fctl login --profile %s' to log in again", profileName)
+		return check, nil
+	}
+	check.Detail = fmt.Sprintf("%s (user: %s)", credsPath, profile.Username)
+	return check, profile
+}
+
+// checkDoctorTokenExpiry checks that the profile's token has not expired and warns when it expires soon.
+func checkDoctorTokenExpiry(profile *config.Profile) doctorCheck {
+	check := doctorCheck{Name: "Token", Fix: fmt.Sprintf("Run 'fctl login --profile %s' to refresh your token", profile.Name)}
+	if profile.TokenExpiry == "" {
+		check.Detail = "no expiry recorded"
+		return check
+	}
+	expiry, err := time.Parse(time.RFC3339, profile.TokenExpiry)
+	if err != nil {
+		check.Status = doctorFail
+		check.Detail = fmt.Sprintf("could not parse token_expiry: %v", err)
+		return check
+	}
+	remaining := time.Until(expiry)
+	switch {
+	case remaining <= 0:
+		check.Status = doctorFail
+		check.Detail = fmt.Sprintf("expired at %s", formatTimestamp(expiry))
+	case remaining < tokenExpiryWarning:
+		check.Status = doctorWarn
+		check.Detail = fmt.Sprintf("expires in %s, at %s", utils.FormatDuration(remaining), formatTimestamp(expiry))
+	default:
+		check.Detail = fmt.Sprintf("valid until %s", formatTimestamp(expiry))
+	}
+	return check
+}
+
+// checkDoctorControlPlane checks that the control plane is reachable and accepts the profile's token.
+func checkDoctorControlPlane(profile *config.Profile) doctorCheck {
+	check := doctorCheck{Name: "Control plane"}
+	client, auth, err := config.GetClient(profile.Name, true)
+	if err != nil {
+		check.Status = doctorFail
+		check.Detail = err.Error()
+		check.Fix = fmt.Sprintf("Run 'fctl login --profile %s' to log in again", profile.Name)
+		return check
+	}
+	latency, _, err := pingControlPlane(client, auth)
+	if err != nil {
+		check.Status = doctorFail
+		switch code, rejected := authRejectedCode(err); {
+		case rejected:
+			check.Detail = fmt.Sprintf("%s is reachable but rejected the token (HTTP %d)", profile.ControlPlaneURL, code)
+			check.Fix = fmt.Sprintf("Run 'fctl login --profile %s' to refresh your token", profile.Name)
+		case isControlPlaneDown(err):
+			check.Detail = fmt.Sprintf("%s is unavailable (HTTP 503)", profile.ControlPlaneURL)
+			check.Fix = "Wait for the control plane to come back, then run 'fctl doctor' again"
+		default:
+			check.Detail = fmt.Sprintf("%s is not reachable: %v", profile.ControlPlaneURL, err)
+			check.Fix = "Check your network, VPN, or proxy settings, and the control_plane_url of the profile"
+		}
+		return check
+	}
+	check.Detail = fmt.Sprintf("%s (latency: %dms)", profile.ControlPlaneURL, latency.Milliseconds())
+	return check
+}
+
+// checkDoctorTerraform checks that a terraform binary is on PATH and can report its version.
+func checkDoctorTerraform() doctorCheck {
+	check := doctorCheck{Name: "Terraform", Fix: "Install Terraform from https://developer.hashicorp.com/terraform/install and make sure it is on your PATH"}
+	execPath, version, err := terraformVersion()
+	if err != nil {
+		check.Status = doctorFail
+		check.Detail = err.Error()
+		return check
+	}
+	check.Detail = fmt.Sprintf("%s (v%s)", execPath, version)
+	return check
+}
+
+// checkDoctorBaseDir checks that the base directory is writable and has enough free disk space.
+func checkDoctorBaseDir() []doctorCheck {
+	permCheck := doctorCheck{Name: "Base directory"}
+	baseDir, err := resolveBaseDir()
+	if err != nil {
+		permCheck.Status = doctorFail
+		permCheck.Detail = err.Error()
+		permCheck.Fix = "Pass --base-dir or set FCTL_BASE_DIR to a writable directory"
+		return []doctorCheck{permCheck}
+	}
+
+	// A base directory that doesn't exist yet is created by apply, so check the closest existing parent
+	existingDir := baseDir
+	for {
+		if _, err := os.Stat(existingDir); err == nil {
+			break
+		}
+		parent := filepath.Dir(existingDir)
+		if parent == existingDir {
+			break
+		}
+		existingDir = parent
+	}
+
+	if f, err := os.CreateTemp(existingDir, ".fctl-doctor-*"); err != nil {
+		permCheck.Status = doctorFail
+		permCheck.Detail = fmt.Sprintf("%s is not writable: %v", existingDir, err)
+		permCheck.Fix = fmt.Sprintf("Run 'chmod u+rwx %s', or pass --base-dir or set FCTL_BASE_DIR to a writable directory", existingDir)
+	} else {
+		f.Close()
+		os.Remove(f.Name())
+		permCheck.Detail = fmt.Sprintf("%s (writable)", baseDir)
+		if existingDir != baseDir {
+			permCheck.Detail = fmt.Sprintf("%s (will be created in %s, which is writable)", baseDir, existingDir)
+		}
+	}
+
+	diskCheck := doctorCheck{Name: "Disk space"}
+	free, err := utils.FreeDiskSpace(existingDir)
+	switch {
+	case err != nil:
+		diskCheck.Status = doctorWarn
+		diskCheck.Detail = fmt.Sprintf("could not determine free space in %s: %v", existingDir, err)
+	case free < minFreeDiskSpace:
+		diskCheck.Status = doctorWarn
+		diskCheck.Detail = fmt.Sprintf("only %s free in %s", formatBytes(free), existingDir)
+		diskCheck.Fix = "Free up space, or pass --base-dir or set FCTL_BASE_DIR to a directory on a larger disk. Terraform providers alone can take several hundred MB per deployment"
+	default:
+		diskCheck.Detail = fmt.Sprintf("%s free in %s", formatBytes(free), existingDir)
+	}
+	return []doctorCheck{permCheck, diskCheck}
+}
+
+// formatBytes formats a byte count with a binary unit, such as "1.5 GiB".
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
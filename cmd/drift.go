@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Facets-cloud/fctl/pkg/output"
+	"github.com/Facets-cloud/fctl/pkg/utils"
+	"github.com/hashicorp/terraform-exec/tfexec"
+	"github.com/spf13/cobra"
+)
+
+var (
+	driftDetect      bool
+	driftFailOnDrift bool
+	driftOutputFile  string
+)
+
+var driftCmd = &cobra.Command{
+	Use:   "drift",
+	Short: "Find changes made to infrastructure outside of Terraform.",
+	Long:  `Compare the real infrastructure of an environment with its Terraform state, without changing either.`,
+}
+
+var driftDetectCmd = &cobra.Command{
+	Use:   "detect",
+	Short: "Report the resources that drifted from the state of an export.",
+	Long: `Run a refresh-only plan ('terraform plan -refresh-only') for an exported zip, with the same workspace and state handling as 'fctl plan', and print a table of the resources whose real infrastructure differs from the state: their type, name, and the attributes that changed. Nothing is applied and the state is not updated, so it is safe to run on a schedule in CI.
+
+With --fail-on-drift the exit status is:
+  0 - no drift
+  1 - errored
+  2 - drift was found
+
+Use --output-file to write the report as JSON. Attribute values are left out of the report, as they can hold secrets.`,
+	RunE: runDriftDetect,
+}
+
+// driftReport is the report written by 'drift detect --output-file'.
+type driftReport struct {
+	EnvironmentID string                  `json:"environment_id"`
+	DeploymentID  string                  `json:"deployment_id"`
+	DetectedAt    string                  `json:"detected_at"`
+	Drifted       []utils.DriftedResource `json:"drifted"`
+}
+
+func init() {
+	rootCmd.AddCommand(driftCmd)
+	driftCmd.AddCommand(driftDetectCmd)
+
+	// The plan is run by 'fctl plan', so these are its flags
+	driftDetectCmd.Flags().StringVarP(&zipPath, "zip", "z", "", "Path to the exported zip file (required)")
+	driftDetectCmd.Flags().StringSliceVarP(&targetAddrs, "target", "t", nil, "Module target address to limit the check to. Can be specified multiple times or comma-separated.")
+	driftDetectCmd.Flags().StringVarP(&statePath, "state", "s", "", "Path to the state file")
+	driftDetectCmd.Flags().StringVar(&backendType, "backend", "", "Terraform backend type for state (s3, gcs, azurerm, consul). Falls back to TF_BACKEND_TYPE")
+	driftDetectCmd.Flags().StringArrayVar(&backendConfigPairs, "backend-config", nil, "Backend variable in the form key=value, overriding TF_BACKEND_<TYPE>_<KEY>. Can be specified multiple times.")
+	driftDetectCmd.Flags().StringVar(&backendConfigFile, "backend-config-file", "", "Path to a JSON or HCL file of backend variables (key = value). --backend-config values take precedence")
+	driftDetectCmd.Flags().StringVar(&vaultAddr, "vault-addr", "", "Address of the Vault server to read backend variables from when TF_BACKEND_<TYPE>_VAULT_PATH is set. Falls back to VAULT_ADDR")
+	driftDetectCmd.Flags().StringVar(&vaultToken, "vault-token", "", "Token for --vault-addr. Falls back to VAULT_TOKEN")
+	driftDetectCmd.Flags().StringArrayVar(&varFiles, "var-file", nil, "Path to a Terraform variables file (.tfvars). Can be specified multiple times.")
+	driftDetectCmd.Flags().StringArrayVar(&tfVars, "var", nil, "Set a Terraform variable, in the form name=value. Can be specified multiple times.")
+	driftDetectCmd.Flags().StringVar(&decryptPassphrase, "decrypt", "", "Passphrase of a zip encrypted with 'fctl repackage --encrypt'")
+	driftDetectCmd.Flags().StringVar(&zipChecksum, "checksum", "", "Expected digest of the zip file, in the form sha256:<hex>")
+	driftDetectCmd.Flags().IntVar(&tfParallelism, "parallelism", 10, "Number of concurrent Terraform operations. Lower it for cloud accounts with strict API rate limits")
+	driftDetectCmd.Flags().DurationVar(&tfTimeout, "timeout", 0, "Stop Terraform if it runs longer than this (e.g. 30m); 0 means no limit")
+	driftDetectCmd.Flags().StringVar(&runLogPath, "log-file", "", "Write the run log to this file instead of <deployment-dir>/fctl-run-<timestamp>.log")
+	driftDetectCmd.Flags().BoolVar(&driftFailOnDrift, "fail-on-drift", false, "Exit with 2 when drift is found")
+	driftDetectCmd.Flags().StringVar(&driftOutputFile, "output-file", "", "Write the drift report to this file as JSON")
+
+	driftDetectCmd.MarkFlagRequired("zip")
+}
+
+func runDriftDetect(cmd *cobra.Command, args []string) error {
+	// runPlan calls reportDrift with the refresh-only plan instead of summarizing it
+	driftDetect = true
+	refreshOnly = true
+	planSummaryOnly = true
+	return runPlan(cmd, args)
+}
+
+// reportDrift prints the resources of the refresh-only plan in planFile that drifted from the state,
+// and writes them to --output-file
+func reportDrift(ctx context.Context, tf *tfexec.Terraform, planFile string, paths *deploymentPaths) error {
+	plan, err := tf.ShowPlanFile(ctx, planFile)
+	if err != nil {
+		return terraformError(ctx, "show", err)
+	}
+	drifted := utils.FindDrift(plan)
+
+	if driftOutputFile != "" {
+		report := driftReport{
+			EnvironmentID: paths.EnvID,
+			DeploymentID:  paths.DeploymentID,
+			DetectedAt:    time.Now().UTC().Format(time.RFC3339),
+			Drifted:       drifted,
+		}
+		if report.Drifted == nil {
+			report.Drifted = []utils.DriftedResource{}
+		}
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("❌ Failed to encode drift report: %v", err)
+		}
+		if err := os.WriteFile(driftOutputFile, data, 0644); err != nil {
+			return fmt.Errorf("❌ Failed to write drift report: %v", err)
+		}
+		output.Infof("📝 Drift report saved to: %s\n", driftOutputFile)
+	}
+
+	if len(drifted) == 0 {
+		output.Successf("✅ No drift: the state matches real infrastructure\n")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tNAME\tMODULE\tCHANGED ATTRIBUTES")
+	for _, d := range drifted {
+		module := d.Module
+		if module == "" {
+			module = "-"
+		}
+		changed := strings.Join(d.ChangedAttributes, ", ")
+		if d.Action == "delete" {
+			changed = "(deleted)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", d.Type, d.Name, module, changed)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	output.Successf("🌊 %d resource(s) changed outside of Terraform\n", len(drifted))
+	if driftFailOnDrift {
+		exitCode = 2
+	}
+	return nil
+}
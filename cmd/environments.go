@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Facets-cloud/facets-sdk-go/facets/client"
+	"github.com/Facets-cloud/facets-sdk-go/facets/client/ui_stack_controller"
+	"github.com/Facets-cloud/fctl/pkg/config"
+	"github.com/Facets-cloud/fctl/pkg/output"
+	"github.com/go-openapi/runtime"
+	"github.com/spf13/cobra"
+)
+
+var (
+	environmentsProject      string
+	environmentsOutputFormat string
+	environmentsJSON         bool
+)
+
+var environmentsCmd = &cobra.Command{
+	Use:   "environments",
+	Short: "Browse the environments (clusters) of your Facets projects.",
+	Long:  `Browse the environments (clusters) of your Facets projects. Use the sub-commands to discover environment IDs for flags such as --environment-id.`,
+}
+
+var environmentsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List environments for a project, or for every project.",
+	Long:  `List the environments (clusters) of a project with their name, ID, cloud, and status. When --project is omitted, environments of every project are listed, grouped by project.`,
+	RunE:  runEnvironmentsList,
+}
+
+// environmentSummary is the per-environment record printed by 'environments list'.
+type environmentSummary struct {
+	Project string `json:"project"`
+	Name    string `json:"name"`
+	ID      string `json:"id"`
+	Cloud   string `json:"cloud"`
+	Status  string `json:"status"`
+}
+
+func init() {
+	rootCmd.AddCommand(environmentsCmd)
+	environmentsCmd.AddCommand(environmentsListCmd)
+
+	environmentsListCmd.Flags().StringVar(&environmentsProject, "project", "", "The project (stack) name to list environments for (default: all projects)")
+	environmentsListCmd.Flags().StringVarP(&environmentsOutputFormat, "output", "o", "table", "Output format: table or json")
+	environmentsListCmd.Flags().BoolVar(&environmentsJSON, "json", false, "Print the environments as a JSON array (same as --output json)")
+	environmentsListCmd.RegisterFlagCompletionFunc("project", completeProjects)
+}
+
+func runEnvironmentsList(cmd *cobra.Command, args []string) error {
+	if environmentsJSON {
+		environmentsOutputFormat = "json"
+	}
+	if err := validateOutputFormat(environmentsOutputFormat); err != nil {
+		return err
+	}
+
+	profile, _ := cmd.Flags().GetString("profile")
+	client, auth, err := config.GetClient(profile, false)
+	if err != nil {
+		return fmt.Errorf("❌ Could not get client: %v", err)
+	}
+
+	stacksResp, err := client.UIStackController.GetStacks(ui_stack_controller.NewGetStacksParams(), auth)
+	if err != nil {
+		if isControlPlaneDown(err) {
+			output.Errorf("🔴 The Facets control plane is currently unavailable (HTTP 503). Please try again later.\n")
+		}
+		return fmt.Errorf("❌ Could not get projects (stacks): %v", err)
+	}
+	var projects []string
+	for _, stack := range stacksResp.Payload {
+		if environmentsProject == "" || stack.Name == environmentsProject {
+			projects = append(projects, stack.Name)
+		}
+	}
+	// Check the project up front, as export does, rather than surfacing the API error for an unknown stack
+	if environmentsProject != "" && len(projects) == 0 {
+		return fmt.Errorf("❌ Project (stack) not found: %s. Run 'fctl projects list' to see available projects", environmentsProject)
+	}
+
+	var environments []environmentSummary
+	for _, project := range projects {
+		projectEnvs, err := listProjectEnvironments(client, auth, project)
+		if err != nil {
+			return err
+		}
+		environments = append(environments, projectEnvs...)
+	}
+
+	if environmentsOutputFormat == "json" {
+		return printJSON(environments)
+	}
+
+	if len(environments) == 0 {
+		output.Infoln("ℹ️ No environments found.")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tNAME\tID\tCLOUD\tSTATUS")
+	for _, e := range environments {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.Project, e.Name, e.ID, e.Cloud, e.Status)
+	}
+	return w.Flush()
+}
+
+// listProjectEnvironments fetches the environments (clusters) of a single project.
+func listProjectEnvironments(client *client.Facets, auth runtime.ClientAuthInfoWriter, project string) ([]environmentSummary, error) {
+	params := ui_stack_controller.NewGetClustersParams()
+	params.StackName = project
+	clustersResp, err := client.UIStackController.GetClusters(params, auth)
+	if err != nil {
+		if isControlPlaneDown(err) {
+			output.Errorf("🔴 The Facets control plane is currently unavailable (HTTP 503). Please try again later.\n")
+		}
+		return nil, fmt.Errorf("❌ Could not get environments (clusters) for project %s: %v", project, err)
+	}
+
+	var environments []environmentSummary
+	for _, cluster := range clustersResp.Payload {
+		var name string
+		if cluster.Name != nil {
+			name = *cluster.Name
+		}
+		environments = append(environments, environmentSummary{
+			Project: project,
+			Name:    name,
+			ID:      cluster.ID,
+			Cloud:   cluster.Cloud,
+			Status:  cluster.ClusterState,
+		})
+	}
+	return environments, nil
+}
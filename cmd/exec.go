@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/spf13/cobra"
+)
+
+var execZipPath string
+
+var execCmd = &cobra.Command{
+	Use:   "exec --zip <exported-zip-file> -- <terraform args...>",
+	Short: "Run any Terraform command inside an applied export's workspace.",
+	Long: `Run an arbitrary Terraform command, such as 'taint', 'untaint', or 'test', inside the workspace of a deployment that was applied with 'fctl apply'. The workspace is located from the exported zip in the same way as apply (~/.facets/<environment-id>/<deployment-id>/tfexport) and the environment's Terraform workspace is selected first.
+
+Everything after -- is passed to Terraform unchanged, with stdin, stdout, and stderr connected directly. fctl exits with Terraform's exit code.`,
+	Example:     `  fctl exec --zip 3f2b9c1e-8d4a-4b7e-9f1a-2c3d4e5f6a7b.zip -- taint 'module.redis.aws_elasticache_cluster.this'`,
+	Args:        cobra.MinimumNArgs(1),
+	Annotations: map[string]string{skipAuthAnnotation: "true", noBannerAnnotation: "true"},
+	RunE:        runExec,
+}
+
+func init() {
+	rootCmd.AddCommand(execCmd)
+
+	execCmd.Flags().StringVarP(&execZipPath, "zip", "z", "", "Path to the exported zip file (required)")
+
+	execCmd.MarkFlagRequired("zip")
+}
+
+func runExec(cmd *cobra.Command, args []string) error {
+	tf, paths, err := openDeploymentWorkspace(execZipPath)
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+
+	terraformCmd := exec.Command(tf.ExecPath(), args...)
+	terraformCmd.Dir = paths.TFWorkDir
+	terraformCmd.Stdin = os.Stdin
+	terraformCmd.Stdout = os.Stdout
+	terraformCmd.Stderr = os.Stderr
+	if err := terraformCmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			// Terraform has already reported the problem; just pass its exit code through
+			exitCode = exitErr.ExitCode()
+			return nil
+		}
+		return fmt.Errorf("❌ Failed to run terraform: %v", err)
+	}
+	return nil
+}
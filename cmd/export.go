@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -15,6 +16,7 @@ import (
 	"github.com/Facets-cloud/facets-sdk-go/facets/client/ui_deployment_controller"
 	"github.com/Facets-cloud/facets-sdk-go/facets/client/ui_stack_controller"
 	"github.com/Facets-cloud/fctl/pkg/config"
+	"github.com/Facets-cloud/fctl/pkg/output"
 	"github.com/Facets-cloud/fctl/pkg/utils"
 	"github.com/go-openapi/runtime"
 	"github.com/hashicorp/terraform-exec/tfexec"
@@ -26,6 +28,7 @@ import (
 type progressWriter struct {
 	total      int64
 	downloaded int64
+	offset     int64 // bytes already on disk when a resumed download started
 	startTime  time.Time
 	avgTime    time.Duration
 	lastUpdate time.Time
@@ -46,7 +49,7 @@ func (pw *progressWriter) Write(p []byte) (int, error) {
 
 	// Calculate current speed in MB/s
 	elapsed := time.Since(pw.startTime)
-	speed := float64(pw.downloaded) / elapsed.Seconds() / 1024 / 1024 // MB/s
+	speed := float64(pw.downloaded-pw.offset) / elapsed.Seconds() / 1024 / 1024 // MB/s
 
 	if pw.total > 0 {
 		percentage := float64(pw.downloaded) / float64(pw.total) * 100
@@ -117,12 +120,146 @@ func getHistoricalDeploymentTime(client *client.Facets, auth runtime.ClientAuthI
 	return total / time.Duration(len(deploymentTimes))
 }
 
+// downloadError is a failed download attempt. Transient failures (network errors, 5xx) are retryable.
+type downloadError struct {
+	err       error
+	retryable bool
+}
+
+func (e *downloadError) Error() string {
+	return e.err.Error()
+}
+
+// exportChecksumHeader is the response header in which the control plane sends the SHA-256 (hex) of the export
+const exportChecksumHeader = "X-Content-SHA256"
+
+// downloadExport downloads the export at url into path, retrying transient failures up to
+// retries times with exponential backoff. Every retry re-issues the request from scratch. Unless
+// resume is false, each attempt continues from what earlier attempts or runs left in the partial file.
+func downloadExport(url, username, token, path string, retries int, resume bool, progress *progressWriter) error {
+	backoff := 2 * time.Second
+	for attempt := 0; ; attempt++ {
+		if !resume {
+			os.Remove(path + ".partial")
+		}
+		err := downloadExportOnce(url, username, token, path, progress)
+		if err == nil {
+			return nil
+		}
+		var dlErr *downloadError
+		if !errors.As(err, &dlErr) || !dlErr.retryable || attempt >= retries {
+			return err
+		}
+		progress.spinner.UpdateMessage(fmt.Sprintf("🔁 Download attempt %d of %d failed (%v). Retrying in %s...", attempt+1, retries+1, err, backoff))
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// downloadExportOnce makes a single attempt at downloading the export at url into path.
+// Data is written to path+".partial"; if a partial file is left over from an earlier attempt
+// or run, the download resumes from its end with a Range request when the server supports it.
+// When the server sends the export's checksum, the complete file is verified against it before
+// it is moved to path.
+func downloadExportOnce(url, username, token, path string, progress *progressWriter) error {
+	partialPath := path + ".partial"
+	var offset int64
+	if info, err := os.Stat(partialPath); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("could not create download request: %v", err)
+	}
+	req.Header.Add("Accept", "*/*")
+	req.SetBasicAuth(username, token)
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	httpClient := &http.Client{}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return &downloadError{err: fmt.Errorf("request failed: %v", err), retryable: true}
+	}
+	defer resp.Body.Close()
+
+	var file *os.File
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		file, err = os.OpenFile(partialPath, os.O_WRONLY|os.O_APPEND, 0644)
+		progress.spinner.UpdateMessage(fmt.Sprintf("📥 Resuming download at %.2f MB...", float64(offset)/1024/1024))
+	case http.StatusOK:
+		// The server ignored the Range header (or there was nothing to resume), so start over
+		offset = 0
+		file, err = os.Create(partialPath)
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The partial file doesn't match the export on the server; discard it and retry from scratch
+		os.Remove(partialPath)
+		return &downloadError{err: fmt.Errorf("server rejected resume at byte %d", offset), retryable: true}
+	default:
+		return &downloadError{
+			err:       fmt.Errorf("server returned %s", resp.Status),
+			retryable: resp.StatusCode >= 500,
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("could not create export file: %v", err)
+	}
+	defer file.Close()
+
+	expectedSize := int64(-1)
+	if resp.ContentLength >= 0 {
+		expectedSize = offset + resp.ContentLength
+	}
+	progress.total = expectedSize
+	progress.downloaded = offset
+	progress.offset = offset
+	progress.startTime = time.Now()
+	progress.lastUpdate = time.Now()
+
+	// Copy the response body to the file while tracking progress
+	written, err := io.Copy(file, io.TeeReader(resp.Body, progress))
+	if err != nil {
+		return &downloadError{err: fmt.Errorf("transfer interrupted: %v", err), retryable: true}
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("could not write export file: %v", err)
+	}
+
+	if expectedSize >= 0 && offset+written != expectedSize {
+		os.Remove(partialPath)
+		return &downloadError{
+			err:       fmt.Errorf("downloaded %d bytes, expected %d", offset+written, expectedSize),
+			retryable: true,
+		}
+	}
+	if checksum := resp.Header.Get(exportChecksumHeader); checksum != "" {
+		if err := utils.VerifyChecksum(partialPath, "sha256:"+checksum); err != nil {
+			// The whole file is suspect, so don't resume from it
+			os.Remove(partialPath)
+			return &downloadError{err: err, retryable: true}
+		}
+	} else {
+		output.Debugf("No %s header in the download response, skipping checksum verification\n", exportChecksumHeader)
+	}
+	if err := os.Rename(partialPath, path); err != nil {
+		return fmt.Errorf("could not move export into place: %v", err)
+	}
+	return nil
+}
+
 // Recursively set user rwx permissions on all files and directories
 func ensureWritable(path string) error {
 	return filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			// Chmod would change the target instead
+			return nil
+		}
 		return os.Chmod(p, 0700)
 	})
 }
@@ -130,26 +267,36 @@ func ensureWritable(path string) error {
 var exportCopyPairs []string // --copy source:destination
 var exportUploadReleaseMetadata bool
 var allowDestroy bool
+var downloadRetries int
+var noResume bool
+var exportDereferenceSymlinks bool
+var exportTimeout time.Duration
 
 var exportCmd = &cobra.Command{
 	Use:   "export",
 	Short: "Export a Facets environment as a Terraform configuration.",
 	Long:  `Export your Facets project environment as a Terraform configuration zip file. This enables you to manage infrastructure as code, perform offline planning, and apply changes in a controlled manner. Supports adding files to the zip via --copy source:destination pairs.`,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Failures are printed as they happen, by the spinner or output.Errorf, so Cobra must not repeat them
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+
 		environment, _ := cmd.Flags().GetString("environment-id")
 		project, _ := cmd.Flags().GetString("project")
 		envName, _ := cmd.Flags().GetString("env-name")
 		includeProviders, _ := cmd.Flags().GetBool("include-providers")
 
-		s := pin.New("🚀 Initializing export...",
-			pin.WithSpinnerColor(pin.ColorCyan),
-			pin.WithTextColor(pin.ColorYellow),
-			pin.WithDoneSymbol('✔'),
-			pin.WithDoneSymbolColor(pin.ColorGreen),
-			pin.WithPrefix("pin"),
-			pin.WithPrefixColor(pin.ColorMagenta),
-			pin.WithSeparatorColor(pin.ColorGray),
-		)
+		s := newSpinner("🚀 Initializing export...", pin.WithWriter(output.Writer()))
+
+		// fail stops the spinner with msg and returns it as the command's error. The spinner writes nowhere
+		// in quiet and JSON mode, so the error is printed to stderr instead.
+		fail := func(msg string) error {
+			s.Fail(msg)
+			if output.Quiet() || output.JSON() {
+				output.Errorf("%s\n", msg)
+			}
+			return errors.New(msg)
+		}
 
 		cancel := s.Start(context.Background())
 		defer cancel()
@@ -157,9 +304,7 @@ var exportCmd = &cobra.Command{
 		profile, _ := cmd.Flags().GetString("profile")
 		client, auth, err := config.GetClient(profile, false)
 		if err != nil {
-			s.Fail("❌ Error fetching client")
-			fmt.Printf("🔴 Could not get client: %v\n", err)
-			return
+			return fail(fmt.Sprintf("❌ Error fetching client: %v", err))
 		}
 
 		// If environment is not provided, but project and env-name are, resolve environment ID
@@ -169,13 +314,10 @@ var exportCmd = &cobra.Command{
 			stackParams := ui_stack_controller.NewGetStacksParams()
 			stacksResp, err := client.UIStackController.GetStacks(stackParams, auth)
 			if err != nil {
-				s.Fail("❌ Error fetching projects (stacks)")
-				if stacksResp.Code() == 503 {
-					fmt.Printf("🔴 Control plane is unreachable or down (HTTP 503)\n")
-				} else {
-					fmt.Printf("🔴 Could not get stacks: %v\n", err)
+				if isControlPlaneDown(err) {
+					return fail("❌ Error fetching projects (stacks): control plane is unreachable or down (HTTP 503)")
 				}
-				return
+				return fail(fmt.Sprintf("❌ Error fetching projects (stacks): %v", err))
 			}
 			var foundStackName string
 			for _, stack := range stacksResp.Payload {
@@ -185,21 +327,17 @@ var exportCmd = &cobra.Command{
 				}
 			}
 			if foundStackName == "" {
-				s.Fail("❌ Project (stack) not found: " + project)
-				return
+				return fail("❌ Project (stack) not found: " + project)
 			}
 			// 2. Get all clusters (environments) for the stack
 			clusterParams := ui_stack_controller.NewGetClustersParams()
 			clusterParams.StackName = foundStackName
 			clustersResp, err := client.UIStackController.GetClusters(clusterParams, auth)
 			if err != nil {
-				s.Fail("❌ Error fetching environments (clusters) for project: " + foundStackName)
-				if clustersResp.Code() == 503 {
-					fmt.Printf("🔴 Control plane is unreachable or down (HTTP 503)\n")
-				} else {
-					fmt.Printf("🔴 Could not get clusters: %v\n", err)
+				if isControlPlaneDown(err) {
+					return fail("❌ Error fetching environments (clusters) for project " + foundStackName + ": control plane is unreachable or down (HTTP 503)")
 				}
-				return
+				return fail(fmt.Sprintf("❌ Error fetching environments (clusters) for project %s: %v", foundStackName, err))
 			}
 			var foundEnvID string
 			for _, cluster := range clustersResp.Payload {
@@ -209,16 +347,16 @@ var exportCmd = &cobra.Command{
 				}
 			}
 			if foundEnvID == "" {
-				s.Fail("❌ Environment not found: " + envName)
-				return
+				return fail("❌ Environment not found: " + envName)
 			}
 			environment = foundEnvID
 			s.UpdateMessage("✅ Resolved environment ID: " + environment)
 		}
 
+		output.Result().EnvironmentID = environment
+
 		if environment == "" {
-			s.Fail("❌ Environment ID is required (either --environment-id or --project and --env-name)")
-			return
+			return fail("❌ Environment ID is required (either --environment-id or --project and --env-name)")
 		}
 
 		// Get average deployment time from history
@@ -235,13 +373,9 @@ var exportCmd = &cobra.Command{
 		if err != nil {
 			// Check for control plane down (HTTP 503)
 			if apiErr, ok := err.(*runtime.APIError); ok && apiErr.Code == 503 {
-				s.Fail("❌ Control plane is down. Please try again later.")
-				fmt.Println("🔴 The Facets control plane is currently unavailable (HTTP 503). Please try again later.")
-				return
+				return fail("❌ The Facets control plane is currently unavailable (HTTP 503). Please try again later.")
 			}
-			s.Fail("❌ Error fetching deployments")
-			fmt.Printf("🔴 Could not get deployments: %v\n", err)
-			return
+			return fail(fmt.Sprintf("❌ Error fetching deployments: %v", err))
 		}
 
 		var runningExportID string
@@ -270,41 +404,59 @@ var exportCmd = &cobra.Command{
 			// 2. No running export, trigger a new one
 			params := ui_deployment_controller.NewTriggerTerraformExportParams()
 			params.ClusterID = environment
-			response, err := client.UIDeploymentController.TriggerTerraformExport(params, auth)
+			var response *ui_deployment_controller.TriggerTerraformExportOK
+			err := utils.RetryableOperation(context.Background(), apiRetryAttempts, func() error {
+				var err error
+				response, err = client.UIDeploymentController.TriggerTerraformExport(params, auth)
+				return err
+			})
 			if err != nil {
-				s.Fail("❌ Error triggering Terraform Export")
-				fmt.Printf("🔴 Could not trigger terraform export: %v\n", err)
-				return
+				return fail(fmt.Sprintf("❌ Error triggering Terraform Export: %v", err))
 			}
 			if response.IsCode(200) && response.Payload.Status == "IN_PROGRESS" {
 				s.UpdateMessage("🦄 Terraform export triggered with id: " + response.Payload.ID + timeEstimateMsg)
 				deploymentID = response.Payload.ID
 				deploymentStartTime = time.Now()
 			} else {
-				s.Fail("❌ Could not trigger terraform export: response code " + strconv.Itoa(response.Code()) + " and payload: " + response.Payload.ID + " and status: " + response.Payload.Status)
-				return
+				return fail("❌ Could not trigger terraform export: response code " + strconv.Itoa(response.Code()) + " and payload: " + response.Payload.ID + " and status: " + response.Payload.Status)
 			}
 		}
 
-		// 3. Wait for the export to complete
+		output.Result().DeploymentID = deploymentID
+
+		// 3. Wait for the export to complete, up to --timeout if one is set
+		waitCtx := context.Background()
+		if exportTimeout > 0 {
+			var cancelWait context.CancelFunc
+			waitCtx, cancelWait = context.WithTimeout(waitCtx, exportTimeout)
+			defer cancelWait()
+		}
 		for {
-			time.Sleep(5 * time.Second)
+			select {
+			case <-waitCtx.Done():
+				return fail(fmt.Sprintf("❌ Timed out after %s waiting for Terraform export %s to complete. Check its status with 'fctl deployments list -e %s'", exportTimeout, deploymentID, environment))
+			case <-time.After(5 * time.Second):
+			}
 			getDeploymentParams := ui_deployment_controller.NewGetDeploymentParams()
 			getDeploymentParams.ClusterID = environment
 			getDeploymentParams.DeploymentID = deploymentID
-			deploymentStatus, err := client.UIDeploymentController.GetDeployment(getDeploymentParams, auth)
+			var deploymentStatus *ui_deployment_controller.GetDeploymentOK
+			err := utils.RetryableOperation(waitCtx, apiRetryAttempts, func() error {
+				var err error
+				deploymentStatus, err = client.UIDeploymentController.GetDeployment(getDeploymentParams, auth)
+				return err
+			})
 			if err != nil {
-				s.Fail("❌ Could not get deployment status")
-				fmt.Printf("🔴 Could not get deployment status: %v\n", err)
-				return
+				return fail(fmt.Sprintf("❌ Could not get deployment status: %v", err))
 			}
 			if deploymentStatus.Payload.Status == "SUCCEEDED" || deploymentStatus.Payload.Status == "FAILED" {
 				if deploymentStatus.Payload.Status == "FAILED" {
-					s.Fail("❌ Terraform export failed")
+					exportErr := fail("❌ Terraform export failed")
 					for _, log := range deploymentStatus.Payload.ErrorLogs {
-						fmt.Printf("🔴 Error logs : %v,", log.ErrorMessage)
+						output.Errorf("🔴 Error logs : %v,", log.ErrorMessage)
 					}
-					return
+					output.Errorf("\n👉 View the logs with: fctl deployments logs -e %s -d %s\n", environment, deploymentID)
+					return exportErr
 				}
 				break
 			} else {
@@ -323,173 +475,104 @@ var exportCmd = &cobra.Command{
 		// 4. Download the export for the completed deployment
 		clientConfig := config.GetClientConfig(profile)
 		if clientConfig == nil {
-			s.Fail("❌ Could not get client configuration")
-			return
+			return fail("❌ Could not get client configuration")
 		}
 		s.UpdateMessage("📥 Preparing to download Terraform export...")
 
 		filename := fmt.Sprintf("%s.zip", deploymentID)
 		currentDir, err := os.Getwd()
 		if err != nil {
-			s.Fail("❌ Could not get current directory: " + err.Error())
-			return
+			return fail("❌ Could not get current directory: " + err.Error())
 		}
 
 		zipFilePath := filepath.Join(currentDir, filename)
+		output.Result().OutputPath = zipFilePath
 		downloadURL := fmt.Sprintf("%s/cc-ui/v1/clusters/%s/deployments/%s/download-terraform-export",
 			clientConfig.ControlPlaneURL,
 			environment,
 			deploymentID)
 
-		req, err := http.NewRequest("GET", downloadURL, nil)
-		if err != nil {
-			s.Fail("❌ Could not create download request: " + err.Error())
-			return
-		}
-
-		req.Header.Add("Accept", "*/*")
-		req.SetBasicAuth(clientConfig.Username, clientConfig.Token)
-
-		httpClient := &http.Client{}
-		resp, err := httpClient.Do(req)
-		if err != nil {
-			s.Fail("❌ Could not download export: " + err.Error())
-			return
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			s.Fail(fmt.Sprintf("❌ Download failed with status: %s", resp.Status))
-			return
-		}
-
-		file, err := os.Create(zipFilePath)
-		if err != nil {
-			s.Fail("❌ Could not create export file: " + err.Error())
-			return
+		// Create progress writer; the total size is set from each download attempt's response
+		progress := &progressWriter{
+			avgTime: avgTime,
+			spinner: s,
 		}
-		defer file.Close()
 
-		// Create progress writer with total size from response
-		progress := &progressWriter{
-			total:      resp.ContentLength,
-			startTime:  time.Now(),
-			avgTime:    avgTime,
-			lastUpdate: time.Now(),
-			spinner:    s,
+		if err := downloadExport(downloadURL, clientConfig.Username, clientConfig.Token, zipFilePath, downloadRetries, !noResume, progress); err != nil {
+			return fail("❌ Could not download export: " + err.Error())
 		}
 
-		// Copy the response body to the file while tracking progress
-		_, err = io.Copy(file, io.TeeReader(resp.Body, progress))
-		if err != nil {
-			s.Fail("❌ Error downloading file: " + err.Error())
-			return
+		s.UpdateMessage("🔎 Verifying export archive...")
+		if err := utils.VerifyZip(zipFilePath); err != nil {
+			// Remove the bad archive so the next run downloads it again
+			os.Remove(zipFilePath)
+			return fail("❌ " + err.Error())
 		}
 
 		// If include-providers is set, extract the zip to a temp directory
 		if includeProviders {
 			tempDir, err := os.MkdirTemp("", "fctl-tfexport-*")
 			if err != nil {
-				s.Fail("❌ Could not create temp directory: " + err.Error())
-				return
+				return fail("❌ Could not create temp directory: " + err.Error())
 			}
 			defer os.RemoveAll(tempDir)
 
 			if err := utils.ExtractZip(zipFilePath, tempDir); err != nil {
-				s.Fail("❌ Could not extract zip: " + err.Error())
-				return
+				return fail("❌ Could not extract zip: " + err.Error())
 			}
 
 			// Ensure all files/dirs are writable by the user
 			if err := ensureWritable(tempDir); err != nil {
-				s.Fail("❌ Could not set permissions: " + err.Error())
-				return
+				return fail("❌ Could not set permissions: " + err.Error())
 			}
 
 			// Run 'terraform init' in tempDir using terraform-exec
 			tf, err := tfexec.NewTerraform(fmt.Sprintf("%s/tfexport", tempDir), "terraform")
 			if err != nil {
-				s.Fail("❌ Failed to create terraform executor: " + err.Error())
-				return
+				return fail("❌ Failed to create terraform executor: " + err.Error())
 			}
 			tf.SetStdout(io.Discard)
 			tf.SetStderr(io.Discard)
 			if err := tf.Init(context.Background()); err != nil {
-				s.Fail("❌ 'terraform init' failed: " + err.Error())
-				return
+				return fail("❌ 'terraform init' failed: " + err.Error())
+			}
+
+			// ensureWritable made every file executable, so restore the modes before they are zipped
+			if err := utils.FixPermissions(tempDir); err != nil {
+				return fail("❌ Could not set permissions: " + err.Error())
 			}
 
 			// Re-zip the directory, replacing the original zip
-			if err := utils.ZipDir(tempDir, zipFilePath); err != nil {
-				s.Fail("❌ Could not re-zip directory: " + err.Error())
-				return
+			if err := utils.ZipDirWithOptions(tempDir, zipFilePath, utils.ZipOptions{DereferenceSymlinks: exportDereferenceSymlinks}); err != nil {
+				return fail("❌ Could not re-zip directory: " + err.Error())
 			}
 		}
 
-		// If --copy is set, extract zip, copy files, and re-zip
+		// If --copy is set, add the files to the zip without extracting it
 		if len(exportCopyPairs) > 0 {
-			tempDir, err := os.MkdirTemp("", "fctl-export-copy-*")
+			additions, err := parseCopyPairs(exportCopyPairs)
 			if err != nil {
-				s.Fail("❌ Could not create temp directory for --copy: " + err.Error())
-				return
-			}
-			defer os.RemoveAll(tempDir)
-			if err := utils.ExtractZip(zipFilePath, tempDir); err != nil {
-				s.Fail("❌ Could not extract zip for --copy: " + err.Error())
-				return
+				return fail("❌ " + err.Error())
 			}
 			s.UpdateMessage("📄 Copying files to zip structure...")
-			for _, pair := range exportCopyPairs {
-				sepIdx := -1
-				for i, c := range pair {
-					if c == ':' {
-						sepIdx = i
-						break
-					}
-				}
-				if sepIdx == -1 {
-					s.Fail(fmt.Sprintf("❌ Invalid --copy value: %s (expected format source:destination)", pair))
-					return
-				}
-				source := pair[:sepIdx]
-				dest := pair[sepIdx+1:]
-				if source == "" || dest == "" {
-					s.Fail(fmt.Sprintf("❌ Invalid --copy value: %s (source and destination required)", pair))
-					return
-				}
-				destPath := filepath.Join(tempDir, dest)
-				srcInfo, err := os.Stat(source)
-				if err != nil {
-					s.Fail(fmt.Sprintf("❌ Failed to stat source: %s", source))
-					return
-				}
-				if srcInfo.IsDir() {
-					if err := utils.CopyDir(source, destPath); err != nil {
-						s.Fail(fmt.Sprintf("❌ Failed to copy directory: %s", source))
-						return
-					}
-				} else {
-					if err := utils.CopyFile(source, destPath); err != nil {
-						s.Fail(fmt.Sprintf("❌ Failed to copy file: %s", source))
-						return
-					}
-				}
-			}
-			if err := utils.ZipDir(tempDir, zipFilePath); err != nil {
-				s.Fail("❌ Could not re-zip after --copy: " + err.Error())
-				return
+			if err := utils.RewriteZip(zipFilePath, zipFilePath, utils.ZipEdit{Add: additions}); err != nil {
+				return fail("❌ Could not add files for --copy: " + err.Error())
 			}
 		}
 
 		s.Stop(fmt.Sprintf("✅ Export completed successfully! 📁 Saved to: %s", zipFilePath))
+		if output.Quiet() {
+			// The spinner is silenced along with the rest of the progress output, but the result is not
+			output.Successf("✅ Export completed successfully! 📁 Saved to: %s\n", zipFilePath)
+		}
 
 		// Handle post-export actions
 		applyFlag, _ := cmd.Flags().GetBool("apply")
 		planFlag, _ := cmd.Flags().GetBool("plan")
 		destroyFlag, _ := cmd.Flags().GetBool("destroy")
 		if exportUploadReleaseMetadata && !(applyFlag || destroyFlag) {
-			fmt.Println("❌ --upload-release-metadata can only be used with --apply or --destroy.")
-			return
+			output.Errorf("❌ --upload-release-metadata can only be used with --apply or --destroy.\n")
+			return errors.New("❌ --upload-release-metadata can only be used with --apply or --destroy")
 		}
 		flagCount := 0
 		if applyFlag {
@@ -502,11 +585,11 @@ var exportCmd = &cobra.Command{
 			flagCount++
 		}
 		if flagCount > 1 {
-			fmt.Println("❌ Only one of --apply, --plan, or --destroy can be specified at a time.")
-			return
+			output.Errorf("❌ Only one of --apply, --plan, or --destroy can be specified at a time.\n")
+			return errors.New("❌ Only one of --apply, --plan, or --destroy can be specified at a time")
 		}
 		if applyFlag {
-			fmt.Println("\n➡️  Invoking 'fctl apply' on exported zip...")
+			output.Infoln("\n➡️  Invoking 'fctl apply' on exported zip...")
 			applyCmd.Flags().Set("zip", filename)
 			if exportUploadReleaseMetadata {
 				applyCmd.Flags().Set("upload-release-metadata", "true")
@@ -514,13 +597,13 @@ var exportCmd = &cobra.Command{
 			if allowDestroy {
 				applyCmd.Flags().Set("allow-destroy", "true")
 			}
-			err := runApply(applyCmd, []string{})
-			if err != nil {
-				fmt.Printf("❌ Error during apply: %v\n", err)
+			if err := runApply(applyCmd, []string{}); err != nil {
+				output.Errorf("❌ Error during apply: %v\n", err)
+				return err
 			}
 		}
 		if planFlag {
-			fmt.Println("\n➡️  Invoking 'fctl plan' on exported zip...")
+			output.Infoln("\n➡️  Invoking 'fctl plan' on exported zip...")
 			planCmd.Flags().Set("zip", filename)
 			if exportUploadReleaseMetadata {
 				planCmd.Flags().Set("upload-release-metadata", "true")
@@ -528,13 +611,13 @@ var exportCmd = &cobra.Command{
 			if allowDestroy {
 				planCmd.Flags().Set("allow-destroy", "true")
 			}
-			err := runPlan(planCmd, []string{})
-			if err != nil {
-				fmt.Printf("❌ Error during plan: %v\n", err)
+			if err := runPlan(planCmd, []string{}); err != nil {
+				output.Errorf("❌ Error during plan: %v\n", err)
+				return err
 			}
 		}
 		if destroyFlag {
-			fmt.Println("\n➡️  Invoking 'fctl destroy' on exported zip...")
+			output.Infoln("\n➡️  Invoking 'fctl destroy' on exported zip...")
 			destroyCmd.Flags().Set("zip", filename)
 			if exportUploadReleaseMetadata {
 				destroyCmd.Flags().Set("upload-release-metadata", "true")
@@ -542,11 +625,12 @@ var exportCmd = &cobra.Command{
 			if allowDestroy {
 				destroyCmd.Flags().Set("allow-destroy", "true")
 			}
-			err := runDestroy(destroyCmd, []string{})
-			if err != nil {
-				fmt.Printf("❌ Error during destroy: %v\n", err)
+			if err := runDestroy(destroyCmd, []string{}); err != nil {
+				output.Errorf("❌ Error during destroy: %v\n", err)
+				return err
 			}
 		}
+		return nil
 	},
 }
 
@@ -555,13 +639,21 @@ func init() {
 	exportCmd.Flags().StringP("environment-id", "e", "", "The environment to export")
 	exportCmd.Flags().String("project", "", "The project (stack) name to use for environment lookup")
 	exportCmd.Flags().String("env-name", "", "The environment (cluster) name to use for environment lookup")
+	exportCmd.RegisterFlagCompletionFunc("environment-id", completeEnvironmentIDs)
+	exportCmd.RegisterFlagCompletionFunc("project", completeProjects)
 	exportCmd.Flags().Bool("include-providers", false, "Include Terraform providers in the exported zip (runs 'terraform init' and bundles providers for airgapped use)")
+	exportCmd.Flags().BoolVar(&exportDereferenceSymlinks, "dereference-symlinks", false, "When re-zipping for --include-providers, store the files symlinks point to instead of the symlinks")
 
 	// Add mutually exclusive flags for post-export actions
 	exportCmd.Flags().Bool("apply", false, "Automatically apply the exported Terraform configuration after export")
 	exportCmd.Flags().Bool("plan", false, "Automatically run terraform plan on the exported configuration after export")
 	exportCmd.Flags().Bool("destroy", false, "Automatically destroy resources using the exported configuration after export")
 
+	exportCmd.Flags().DurationVar(&exportTimeout, "timeout", 0, "Maximum time to wait for the export to complete, e.g. 30m (0 waits indefinitely)")
+	exportCmd.Flags().BoolVar(&noResume, "no-resume", false, "Discard any partial download and always download the export from the start")
+	exportCmd.Flags().IntVar(&downloadRetries, "download-retries", 3, "Number of times to retry the export download on transient failures (network errors, 5xx), with exponential backoff")
+
 	exportCmd.Flags().StringArrayVar(&exportCopyPairs, "copy", nil, "Copy a file or directory from local into a specific path inside the zip. Format: source:destination. Can be specified multiple times.")
+	exportCmd.Flags().BoolVar(&jsonOutput, "json", false, "Suppress progress output and print a single JSON result when done")
 	exportCmd.Flags().BoolVar(&exportUploadReleaseMetadata, "upload-release-metadata", false, "Upload release metadata to control plane after apply/plan/destroy (must be used with --apply, --plan, or --destroy)")
 }
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/Facets-cloud/fctl/pkg/output"
+	"github.com/Facets-cloud/fctl/pkg/utils"
+	"github.com/hashicorp/terraform-exec/tfexec"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fmtZipPath string
+	fmtCheck   bool
+	fmtDiff    bool
+)
+
+var fmtCmd = &cobra.Command{
+	Use:         "fmt",
+	Short:       "Rewrite the Terraform files in an exported zip to canonical format.",
+	Long:        `Extract an exported zip, run 'terraform fmt' on every .tf file, and re-zip it in place. Use --check to only report which files need formatting, and --diff to print the changes as a unified diff.`,
+	Annotations: map[string]string{skipAuthAnnotation: "true"},
+	RunE:        runFmt,
+}
+
+func init() {
+	rootCmd.AddCommand(fmtCmd)
+
+	fmtCmd.Flags().StringVarP(&fmtZipPath, "zip", "z", "", "Path to the exported zip file (required)")
+	fmtCmd.Flags().BoolVar(&fmtCheck, "check", false, "Only report files that need formatting; don't modify the zip")
+	fmtCmd.Flags().BoolVar(&fmtDiff, "diff", false, "Print a unified diff of the formatting changes")
+
+	fmtCmd.MarkFlagRequired("zip")
+}
+
+func runFmt(cmd *cobra.Command, args []string) error {
+	tempDir, err := os.MkdirTemp("", "fctl-fmt-*")
+	if err != nil {
+		return fmt.Errorf("❌ Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	if err := utils.ExtractZip(fmtZipPath, tempDir); err != nil {
+		return fmt.Errorf("❌ Failed to extract zip: %v", err)
+	}
+
+	tf, err := tfexec.NewTerraform(tempDir, "terraform")
+	if err != nil {
+		return fmt.Errorf("❌ Failed to create terraform executor: %v", err)
+	}
+
+	var changed []string
+	err = filepath.WalkDir(tempDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// Skip provider plugins and modules installed by 'terraform init'
+		if d.IsDir() && d.Name() == ".terraform" {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || filepath.Ext(path) != ".tf" {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		formatted, err := tf.FormatString(context.Background(), string(content))
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		if formatted == string(content) {
+			return nil
+		}
+
+		relPath, _ := filepath.Rel(tempDir, path)
+		changed = append(changed, relPath)
+		if fmtDiff {
+			fmt.Print(utils.UnifiedDiff(relPath, string(content), formatted))
+		}
+		if !fmtCheck {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(path, []byte(formatted), info.Mode())
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("❌ Terraform fmt failed: %v", err)
+	}
+
+	if len(changed) == 0 {
+		output.Infoln("✅ All Terraform files are already formatted")
+		return nil
+	}
+	if fmtCheck {
+		for _, file := range changed {
+			output.Infof("📄 %s\n", file)
+		}
+		return fmt.Errorf("❌ %d file(s) need formatting. Run 'fctl fmt --zip %s' to fix them", len(changed), fmtZipPath)
+	}
+
+	if err := utils.ZipDir(tempDir, fmtZipPath); err != nil {
+		return fmt.Errorf("❌ Failed to re-zip: %v", err)
+	}
+	for _, file := range changed {
+		output.Infof("📄 %s\n", file)
+	}
+	output.Infof("✅ Formatted %d file(s) in %s\n", len(changed), fmtZipPath)
+	return nil
+}
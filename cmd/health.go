@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/Facets-cloud/facets-sdk-go/facets/client"
+	"github.com/Facets-cloud/facets-sdk-go/facets/client/ui_user_controller"
+	"github.com/Facets-cloud/fctl/pkg/config"
+	"github.com/go-openapi/runtime"
+	"github.com/hashicorp/terraform-exec/tfexec"
+	"github.com/spf13/cobra"
+)
+
+var healthCmd = &cobra.Command{
+	Use:   "health",
+	Short: "Check control plane connectivity, authentication, and the Terraform binary.",
+	Long:  `Check that fctl is ready to use: the profile resolves, the control plane is reachable and accepts the profile's token (measured with a round trip to the current-user API), and a Terraform binary is on PATH. Each check is printed with ✅ or ❌, and the command exits with 1 if any check fails.`,
+	// health reports authentication problems itself instead of failing before it runs
+	Annotations: map[string]string{skipAuthAnnotation: "true"},
+	RunE:        runHealth,
+}
+
+func init() {
+	rootCmd.AddCommand(healthCmd)
+}
+
+func runHealth(cmd *cobra.Command, args []string) error {
+	profileFlag, _ := cmd.Flags().GetString("profile")
+	failures := 0
+	if !checkControlPlaneHealth(profileFlag) {
+		failures++
+	}
+	if !checkTerraformHealth() {
+		failures++
+	}
+	if failures > 0 {
+		return fmt.Errorf("❌ %d health check(s) failed", failures)
+	}
+	return nil
+}
+
+// checkControlPlaneHealth resolves the profile, calls the current-user API, and prints the result.
+func checkControlPlaneHealth(profileFlag string) bool {
+	profileName, err := config.ResolveProfileName(profileFlag)
+	if err != nil {
+		fmt.Printf("❌ Profile: %v\n", err)
+		return false
+	}
+	profile, err := config.GetProfile(profileName)
+	if err != nil {
+		fmt.Printf("❌ Profile: %v\n", err)
+		return false
+	}
+	fmt.Printf("✅ Profile: %s\n", profileName)
+
+	client, auth, err := config.GetClient(profileName, false)
+	if err != nil {
+		fmt.Printf("❌ Authentication: %v. Run 'fctl login' to authenticate\n", err)
+		return false
+	}
+
+	latency, username, err := pingControlPlane(client, auth)
+	if err != nil {
+		switch code, rejected := authRejectedCode(err); {
+		case rejected:
+			fmt.Printf("✅ Control plane: %s (reachable, latency: %dms)\n", profile.ControlPlaneURL, latency.Milliseconds())
+			fmt.Printf("❌ Authentication: the control plane rejected the token (HTTP %d). Run 'fctl login' to authenticate\n", code)
+		case isControlPlaneDown(err):
+			fmt.Printf("❌ Control plane: %s is unavailable (HTTP 503)\n", profile.ControlPlaneURL)
+		default:
+			fmt.Printf("❌ Control plane: %s is not reachable: %v\n", profile.ControlPlaneURL, err)
+		}
+		return false
+	}
+
+	if username == "" {
+		username = profile.Username
+	}
+	expiry := "unknown"
+	if profile.TokenExpiry != "" {
+		if t, err := time.Parse(time.RFC3339, profile.TokenExpiry); err == nil {
+			expiry = formatTimestamp(t)
+		}
+	}
+	fmt.Printf("✅ Control plane: %s (reachable, latency: %dms), User: %s, Token expires: %s\n", profile.ControlPlaneURL, latency.Milliseconds(), username, expiry)
+	return true
+}
+
+// pingControlPlane calls the current-user API and returns its round-trip time and the username it reports.
+func pingControlPlane(client *client.Facets, auth runtime.ClientAuthInfoWriter) (time.Duration, string, error) {
+	start := time.Now()
+	userResp, err := client.UIUserController.GetCurrentUser(ui_user_controller.NewGetCurrentUserParams(), auth)
+	latency := time.Since(start)
+	if err != nil {
+		return latency, "", err
+	}
+	if userResp.Payload == nil {
+		return latency, "", nil
+	}
+	return latency, userResp.Payload.Username, nil
+}
+
+// authRejectedCode returns the HTTP status code if err is the control plane rejecting the token.
+func authRejectedCode(err error) (int, bool) {
+	var apiErr *runtime.APIError
+	if errors.As(err, &apiErr) && (apiErr.Code == 401 || apiErr.Code == 403) {
+		return apiErr.Code, true
+	}
+	return 0, false
+}
+
+// checkTerraformHealth looks up the terraform binary on PATH and prints its version.
+func checkTerraformHealth() bool {
+	execPath, version, err := terraformVersion()
+	if err != nil {
+		fmt.Printf("❌ Terraform: %v\n", err)
+		return false
+	}
+	fmt.Printf("✅ Terraform: %s (v%s)\n", execPath, version)
+	return true
+}
+
+// terraformVersion returns the path of the terraform binary on PATH and the version it reports.
+func terraformVersion() (string, string, error) {
+	execPath, err := exec.LookPath("terraform")
+	if err != nil {
+		return "", "", fmt.Errorf("no terraform binary found on PATH")
+	}
+	tf, err := tfexec.NewTerraform(os.TempDir(), execPath)
+	if err != nil {
+		return "", "", err
+	}
+	version, _, err := tf.Version(context.Background(), true)
+	if err != nil {
+		return "", "", fmt.Errorf("%s found, but 'terraform version' failed: %v", execPath, err)
+	}
+	return execPath, version.String(), nil
+}
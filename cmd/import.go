@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/Facets-cloud/fctl/pkg/output"
+	"github.com/Facets-cloud/fctl/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importZipPath string
+	importDryRun  bool
+	importAddress string
+	importID      string
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import [<address> <id>]",
+	Short: "Import an existing cloud resource into the state of an export.",
+	Long: `Bring an existing, unmanaged cloud resource under Terraform management, like 'terraform import'. The address and ID are given as arguments or with --address and --id, and the address must be declared in the exported configuration.
+
+The deployment directory is set up from the exported zip exactly as apply does (~/.facets/<environment-id>/<deployment-id>/tfexport), so an export can be imported into before it is first applied. --state and --backend work as for apply. After the import, the attributes Terraform recorded for the resource are printed.
+
+Use --dry-run to check the address against the configuration without running Terraform.`,
+	Args:        cobra.MaximumNArgs(2),
+	Annotations: map[string]string{skipAuthAnnotation: "true"},
+	RunE:        runImport,
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	importCmd.Flags().StringVarP(&importZipPath, "zip", "z", "", "Path to the exported zip file (required)")
+	importCmd.Flags().StringVar(&importAddress, "address", "", "Resource address to import into, e.g. module.storage.aws_s3_bucket.this")
+	importCmd.Flags().StringVar(&importID, "id", "", "Cloud ID of the existing resource")
+	importCmd.Flags().StringVarP(&statePath, "state", "s", "", "Path to the state file")
+	importCmd.Flags().StringVar(&backendType, "backend", "", "Terraform backend type for state (s3, gcs, azurerm, consul). Falls back to TF_BACKEND_TYPE")
+	importCmd.Flags().StringArrayVar(&backendConfigPairs, "backend-config", nil, "Backend variable in the form key=value, overriding TF_BACKEND_<TYPE>_<KEY>. Can be specified multiple times.")
+	importCmd.Flags().StringVar(&backendConfigFile, "backend-config-file", "", "Path to a JSON or HCL file of backend variables (key = value). --backend-config values take precedence")
+	importCmd.Flags().StringVar(&vaultAddr, "vault-addr", "", "Address of the Vault server to read backend variables from when TF_BACKEND_<TYPE>_VAULT_PATH is set. Falls back to VAULT_ADDR")
+	importCmd.Flags().StringVar(&vaultToken, "vault-token", "", "Token for --vault-addr. Falls back to VAULT_TOKEN")
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Only check that the address exists in the configuration")
+
+	importCmd.MarkFlagRequired("zip")
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	address, id := importAddress, importID
+	switch len(args) {
+	case 2:
+		if address != "" || id != "" {
+			return fmt.Errorf("❌ Give the address and ID either as arguments or with --address and --id, not both")
+		}
+		address, id = args[0], args[1]
+	case 1:
+		return fmt.Errorf("❌ Missing the resource ID. Usage: fctl import <address> <id> --zip <file>")
+	}
+	if address == "" || id == "" {
+		return fmt.Errorf("❌ Both an address and an ID are required (arguments or --address and --id)")
+	}
+
+	backendConfig, err := newBackendConfigFromFlags()
+	if err != nil {
+		return fmt.Errorf("❌ Failed to initialize backend configuration: %v", err)
+	}
+	if backendConfig != nil {
+		if err := backendConfig.Validate(); err != nil {
+			return fmt.Errorf("❌ Invalid backend configuration: %v", err)
+		}
+	}
+	if err := utils.VerifyZip(importZipPath); err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+
+	if importDryRun {
+		paths, err := resolveDeploymentPaths(importZipPath)
+		if err != nil {
+			return fmt.Errorf("❌ %v", err)
+		}
+		// Use the deployment directory if apply already set it up, otherwise a throwaway copy of the zip
+		configDir := paths.TFWorkDir
+		if _, err := os.Stat(configDir); os.IsNotExist(err) {
+			tempDir, err := os.MkdirTemp("", "fctl-import-*")
+			if err != nil {
+				return fmt.Errorf("❌ Failed to create temp dir: %v", err)
+			}
+			defer os.RemoveAll(tempDir)
+			if err := utils.ExtractZip(importZipPath, tempDir); err != nil {
+				return fmt.Errorf("❌ Failed to extract zip: %v", err)
+			}
+			configDir = filepath.Join(tempDir, "tfexport")
+		}
+		if err := checkImportAddress(configDir, address); err != nil {
+			return err
+		}
+		output.Infof("🔍 %s is declared in the configuration; would import %s (dry run, nothing changed)\n", address, id)
+		return nil
+	}
+
+	tf, paths, err := prepareDeploymentWorkspace(importZipPath, statePath, backendConfig)
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+	if err := checkImportAddress(paths.TFWorkDir, address); err != nil {
+		return err
+	}
+	tf.SetStdout(output.Writer())
+	tf.SetStderr(output.Writer())
+
+	output.Infof("📥 Importing %s as %s...\n", id, address)
+	if err := tf.Import(context.Background(), address, id); err != nil {
+		return fmt.Errorf("❌ Terraform import failed: %v", err)
+	}
+	output.Infof("✅ Imported %s\n", address)
+
+	tf.SetStdout(io.Discard)
+	state, err := tf.Show(context.Background())
+	if err != nil {
+		output.Warnf("⚠️ Warning: Could not read the imported resource: %v\n", err)
+		return nil
+	}
+	if state.Values != nil {
+		if resource := findStateResource(state.Values.RootModule, address); resource != nil {
+			output.Infoln("📋 Imported attributes:")
+			return printJSON(resource.AttributeValues)
+		}
+	}
+	return nil
+}
+
+// checkImportAddress verifies that address is declared in the configuration at tfWorkDir.
+func checkImportAddress(tfWorkDir, address string) error {
+	found, err := utils.ConfigHasResource(tfWorkDir, address)
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+	if !found {
+		return fmt.Errorf("❌ Address '%s' is not declared in the exported configuration", address)
+	}
+	return nil
+}
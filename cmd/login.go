@@ -10,9 +10,9 @@ import (
 
 	"github.com/Facets-cloud/facets-sdk-go/facets/client/ui_user_controller"
 	"github.com/Facets-cloud/fctl/pkg/config"
+	"github.com/Facets-cloud/fctl/pkg/output"
 	"github.com/Facets-cloud/fctl/pkg/utils"
 	"github.com/spf13/cobra"
-	"github.com/yarlson/pin"
 	"gopkg.in/ini.v1"
 )
 
@@ -54,21 +54,21 @@ var loginCmd = &cobra.Command{
 		// Prompt for missing host
 		if host == "" {
 			for {
-				fmt.Print("Enter Facets API host (control_plane_url): ")
+				output.Promptf("Enter Facets API host (control_plane_url): ")
 				input, _ := reader.ReadString('\n')
 				host = strings.TrimSpace(input)
 				if host == "" {
-					fmt.Println("❌ Host cannot be empty.")
+					output.Errorf("❌ Host cannot be empty.\n")
 					continue
 				}
 				// If no protocol, prepend https://
 				if !strings.HasPrefix(host, "http://") && !strings.HasPrefix(host, "https://") {
-					fmt.Printf("ℹ️  No protocol specified for host. Using https://%s\n", host)
+					output.Infof("ℹ️  No protocol specified for host. Using https://%s\n", host)
 					host = "https://" + host
 				}
 				parsed, err := url.Parse(host)
 				if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
-					fmt.Println("❌ Invalid URL. Please enter a valid http(s) URL, e.g. https://facetsdemo.console.facets.cloud")
+					output.Errorf("❌ Invalid URL. Please enter a valid http(s) URL, e.g. https://facetsdemo.console.facets.cloud\n")
 					host = ""
 					continue
 				}
@@ -77,22 +77,22 @@ var loginCmd = &cobra.Command{
 		} else {
 			// If no protocol, prepend https://
 			if !strings.HasPrefix(host, "http://") && !strings.HasPrefix(host, "https://") {
-				fmt.Printf("ℹ️  No protocol specified for host. Using https://%s\n", host)
+				output.Infof("ℹ️  No protocol specified for host. Using https://%s\n", host)
 				host = "https://" + host
 			}
 			parsed, err := url.Parse(host)
 			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
-				fmt.Println("❌ Invalid host provided via flag. Please provide a valid http(s) URL.")
+				output.Errorf("❌ Invalid host provided via flag. Please provide a valid http(s) URL.\n")
 				return
 			}
 		}
 		// Prompt for missing username
 		if username == "" {
-			fmt.Print("Enter Facets username: ")
+			output.Promptf("Enter Facets username: ")
 			input, _ := reader.ReadString('\n')
 			username = strings.TrimSpace(input)
 			if username == "" {
-				fmt.Println("❌ Username cannot be empty.")
+				output.Errorf("❌ Username cannot be empty.\n")
 				return
 			}
 		}
@@ -100,25 +100,17 @@ var loginCmd = &cobra.Command{
 		if token == "" {
 			input, err := utils.ReadMaskedInput("Enter Facets API token: ")
 			if err != nil {
-				fmt.Printf("❌ Error reading token: %v\n", err)
+				output.Errorf("❌ Error reading token: %v\n", err)
 				return
 			}
 			token = input
 			if token == "" {
-				fmt.Println("❌ Token cannot be empty.")
+				output.Errorf("❌ Token cannot be empty.\n")
 				return
 			}
 		}
 
-		s := pin.New("🔐 Initializing login...",
-			pin.WithSpinnerColor(pin.ColorCyan),
-			pin.WithTextColor(pin.ColorYellow),
-			pin.WithDoneSymbol('✔'),
-			pin.WithDoneSymbolColor(pin.ColorGreen),
-			pin.WithPrefix("pin"),
-			pin.WithPrefixColor(pin.ColorMagenta),
-			pin.WithSeparatorColor(pin.ColorGray),
-		)
+		s := newSpinner("🔐 Initializing login...")
 
 		cancel := s.Start(context.Background())
 		defer cancel()
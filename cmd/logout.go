@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/Facets-cloud/fctl/pkg/config"
+	"github.com/Facets-cloud/fctl/pkg/output"
+	"github.com/Facets-cloud/fctl/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var logoutAll bool
+
+var logoutCmd = &cobra.Command{
+	Use:         "logout",
+	Short:       "Remove the stored token for a profile.",
+	Long:        `Remove the stored API token and token expiry for a profile from ~/.facets/credentials. The profile's control plane URL and username are kept so 'fctl login' can re-authenticate quickly. Use --all to log out of every profile.`,
+	Annotations: map[string]string{skipAuthAnnotation: "true"},
+	RunE:        runLogout,
+}
+
+func init() {
+	rootCmd.AddCommand(logoutCmd)
+	logoutCmd.Flags().BoolVar(&logoutAll, "all", false, "Log out of every profile")
+}
+
+func runLogout(cmd *cobra.Command, args []string) error {
+	defaultProfile := config.GetDefaultProfile()
+
+	var targets []string
+	if logoutAll {
+		profiles, err := config.ListProfiles()
+		if err != nil {
+			output.Infoln("ℹ️ No credentials found, nothing to log out of.")
+			return nil
+		}
+		for _, p := range profiles {
+			targets = append(targets, p.Name)
+		}
+	} else {
+		profile, _ := cmd.Flags().GetString("profile")
+		if profile == "" {
+			profile = defaultProfile
+		}
+		targets = []string{utils.GetProfileName(profile)}
+	}
+
+	loggedOut := map[string]bool{}
+	for _, name := range targets {
+		found, err := config.ClearProfileToken(name)
+		if err != nil {
+			return fmt.Errorf("❌ Failed to log out of profile '%s': %v", name, err)
+		}
+		if found {
+			loggedOut[name] = true
+			output.Infof("👋 Logged out of profile '%s'\n", name)
+		} else {
+			output.Infof("ℹ️ Profile '%s' not found, nothing to log out of.\n", name)
+		}
+	}
+
+	if defaultProfile == "" || !loggedOut[defaultProfile] {
+		return nil
+	}
+
+	// The default profile no longer has a token; point the default at a profile that still does
+	profiles, err := config.ListProfiles()
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+	for _, p := range profiles {
+		if p.Token != "" {
+			if err := config.SetDefaultProfile(p.Name); err != nil {
+				return fmt.Errorf("❌ Failed to update default profile: %v", err)
+			}
+			output.Warnf("⚠️ Default profile '%s' was logged out; default is now '%s'.\n", defaultProfile, p.Name)
+			return nil
+		}
+	}
+	if err := config.SetDefaultProfile(""); err != nil {
+		return fmt.Errorf("❌ Failed to clear default profile: %v", err)
+	}
+	output.Warnf("⚠️ Default profile '%s' was logged out and no other profile has a token; default cleared. Run 'fctl login' to sign in again.\n", defaultProfile)
+	return nil
+}
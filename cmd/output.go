@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/Facets-cloud/fctl/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	outputZipPath string
+	outputName    string
+	outputJSON    bool
+)
+
+var outputCmd = &cobra.Command{
+	Use:   "output",
+	Short: "Print Terraform output values for an applied export.",
+	Long: `Print the Terraform output values of a deployment that was applied with 'fctl apply'. The deployment directory is located from the exported zip in the same way as apply (~/.facets/<environment-id>/<deployment-id>/tfexport).
+
+Sensitive values are hidden in the list view; name an output with --output-name or use --json to print them.`,
+	Annotations: map[string]string{skipAuthAnnotation: "true", noBannerAnnotation: "true"},
+	RunE:        runOutput,
+}
+
+func init() {
+	rootCmd.AddCommand(outputCmd)
+
+	outputCmd.Flags().StringVarP(&outputZipPath, "zip", "z", "", "Path to the exported zip file (required)")
+	outputCmd.Flags().StringVar(&outputName, "output-name", "", "Print only the output with this name")
+	outputCmd.Flags().BoolVar(&outputJSON, "json", false, "Print outputs as JSON")
+
+	outputCmd.MarkFlagRequired("zip")
+}
+
+func runOutput(cmd *cobra.Command, args []string) error {
+	tf, _, err := openDeploymentWorkspace(outputZipPath)
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+
+	outputs, err := tf.Output(context.Background())
+	if err != nil {
+		return fmt.Errorf("❌ Terraform output failed: %v", err)
+	}
+
+	if outputName != "" {
+		meta, ok := outputs[outputName]
+		if !ok {
+			return fmt.Errorf("❌ Output '%s' not found", outputName)
+		}
+		if outputJSON {
+			fmt.Println(string(meta.Value))
+			return nil
+		}
+		// Print strings without quotes so the value can be used directly in scripts
+		var s string
+		if err := json.Unmarshal(meta.Value, &s); err == nil {
+			fmt.Println(s)
+		} else {
+			fmt.Println(string(meta.Value))
+		}
+		return nil
+	}
+
+	if outputJSON {
+		return printJSON(outputs)
+	}
+
+	if len(outputs) == 0 {
+		output.Infoln("ℹ️ No outputs found.")
+		return nil
+	}
+	names := make([]string, 0, len(outputs))
+	for name := range outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		meta := outputs[name]
+		if meta.Sensitive {
+			fmt.Printf("%s = <sensitive>\n", name)
+			continue
+		}
+		fmt.Printf("%s = %s\n", name, meta.Value)
+	}
+	return nil
+}
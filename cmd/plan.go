@@ -2,11 +2,12 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 
-	"github.com/Facets-cloud/fctl/pkg/config"
+	"github.com/Facets-cloud/fctl/pkg/output"
 	"github.com/Facets-cloud/fctl/pkg/utils"
 	"github.com/hashicorp/terraform-exec/tfexec"
 	"github.com/spf13/cobra"
@@ -15,27 +16,57 @@ import (
 var planCmd = &cobra.Command{
 	Use:   "plan",
 	Short: "Preview changes for a Terraform export in your Facets environment.",
-	Long:  `Generate and review an execution plan for a Terraform export in your Facets environment. This command mimics 'terraform plan', allowing you to see what changes will be made before applying them. Supports state file management and selective module targeting.`,
-	RunE:  runPlan,
+	Long: `Generate and review an execution plan for a Terraform export in your Facets environment. This command mimics 'terraform plan', allowing you to see what changes will be made before applying them. Supports state file management and selective module targeting. Terraform runs 10 resource operations at once; use --parallelism to change that, for example to lower it for cloud accounts with strict API rate limits.
+
+With --detailed-exitcode the exit status mirrors 'terraform plan -detailed-exitcode':
+  0 - succeeded with no changes
+  1 - errored
+  2 - succeeded with changes present`,
+	RunE: runPlan,
 }
 
+var (
+	planOutPath          string
+	planDetailedExitCode bool
+	planSummaryOnly      bool
+)
+
 func init() {
 	rootCmd.AddCommand(planCmd)
 
 	// Add flags - reusing the same flags as apply command
 	planCmd.Flags().StringVarP(&zipPath, "zip", "z", "", "Path to the exported zip file (required)")
-	planCmd.Flags().StringVarP(&targetAddr, "target", "t", "", "Module target address for selective releases")
+	planCmd.Flags().StringSliceVarP(&targetAddrs, "target", "t", nil, "Module target address for selective releases. Can be specified multiple times or comma-separated.")
 	planCmd.Flags().StringVarP(&statePath, "state", "s", "", "Path to the state file")
+	planCmd.Flags().StringVar(&backendType, "backend", "", "Terraform backend type for state (s3, gcs, azurerm, consul). Falls back to TF_BACKEND_TYPE")
+	planCmd.Flags().StringArrayVar(&backendConfigPairs, "backend-config", nil, "Backend variable in the form key=value, overriding TF_BACKEND_<TYPE>_<KEY>. Can be specified multiple times.")
+	planCmd.Flags().StringVar(&backendConfigFile, "backend-config-file", "", "Path to a JSON or HCL file of backend variables (key = value). --backend-config values take precedence")
+	planCmd.Flags().StringVar(&vaultAddr, "vault-addr", "", "Address of the Vault server to read backend variables from when TF_BACKEND_<TYPE>_VAULT_PATH is set. Falls back to VAULT_ADDR")
+	planCmd.Flags().StringVar(&vaultToken, "vault-token", "", "Token for --vault-addr. Falls back to VAULT_TOKEN")
+	planCmd.Flags().StringArrayVar(&varFiles, "var-file", nil, "Path to a Terraform variables file (.tfvars). Can be specified multiple times.")
+	planCmd.Flags().StringArrayVar(&tfVars, "var", nil, "Set a Terraform variable, in the form name=value. Can be specified multiple times.")
+	planCmd.Flags().StringVar(&decryptPassphrase, "decrypt", "", "Passphrase of a zip encrypted with 'fctl repackage --encrypt'")
+	planCmd.Flags().StringVar(&zipChecksum, "checksum", "", "Expected digest of the zip file, in the form sha256:<hex>")
+	planCmd.Flags().StringVar(&planOutPath, "out", "", "Save the plan to this file, relative to the deployment directory, for use with 'fctl apply --plan-file'")
+	planCmd.Flags().BoolVar(&planDetailedExitCode, "detailed-exitcode", false, "Exit with 0 when there are no changes, 2 when there are changes, and 1 on errors")
+	planCmd.Flags().BoolVar(&planSummaryOnly, "summary-only", false, "Hide Terraform's own output and show only the per-module change summary")
+	planCmd.Flags().BoolVar(&refreshOnly, "refresh-only", false, "Only plan updates to the state to match real infrastructure, without changing any resources")
+	planCmd.Flags().BoolVar(&noRefresh, "no-refresh", false, "Skip refreshing resources before planning, for speed. Changes made outside of Terraform are not detected, so the plan may be wrong")
+	planCmd.Flags().IntVar(&tfParallelism, "parallelism", 10, "Number of concurrent Terraform operations. Lower it for cloud accounts with strict API rate limits")
+	planCmd.Flags().DurationVar(&tfTimeout, "timeout", 0, "Stop Terraform if it runs longer than this (e.g. 30m); 0 means no limit")
+	planCmd.Flags().StringVar(&runLogPath, "log-file", "", "Write the run log to this file instead of <deployment-dir>/fctl-run-<timestamp>.log")
+	planCmd.Flags().BoolVar(&jsonOutput, "json", false, "Suppress progress output and print a single JSON result when done")
 
 	planCmd.MarkFlagRequired("zip")
+	planCmd.MarkFlagsMutuallyExclusive("refresh-only", "no-refresh")
 }
 
-func runPlan(cmd *cobra.Command, args []string) error {
+func runPlan(cmd *cobra.Command, args []string) (err error) {
 	allowDestroy, _ := cmd.Flags().GetBool("allow-destroy")
-	fmt.Println("🔍 Starting terraform plan process...")
+	output.Infoln("🔍 Starting terraform plan process...")
 
 	// Initialize backend configuration
-	backendConfig, err := config.NewBackendConfig()
+	backendConfig, err := newBackendConfigFromFlags()
 	if err != nil {
 		return fmt.Errorf("❌ Failed to initialize backend configuration: %v", err)
 	}
@@ -45,47 +76,56 @@ func runPlan(cmd *cobra.Command, args []string) error {
 		if err := backendConfig.Validate(); err != nil {
 			return fmt.Errorf("❌ Invalid backend configuration: %v", err)
 		}
-		fmt.Printf("🔐 Using %s backend for state management\n", backendConfig.Type)
+		output.Infof("🔐 Using %s backend for state management\n", backendConfig.Type)
 	}
 
-	// Extract deployment ID from zip filename
-	deploymentID, err := utils.ExtractDeploymentID(zipPath)
+	// Validate variables before doing any extraction work
+	resolvedVarFiles, err := resolveVarFiles(varFiles)
 	if err != nil {
-		return fmt.Errorf("❌ Failed to extract deployment ID: %v", err)
+		return fmt.Errorf("❌ Invalid --var-file: %v", err)
 	}
-
-	// Unzip to a temp dir to read deploymentcontext.json
-	tempDir, err := os.MkdirTemp("", "fctl-unzip-*")
-	if err != nil {
-		return fmt.Errorf("❌ Failed to create temp dir: %v", err)
+	if err := validateVars(tfVars); err != nil {
+		return fmt.Errorf("❌ Invalid --var: %v", err)
+	}
+	if err := validateTargets(targetAddrs); err != nil {
+		return fmt.Errorf("❌ Invalid --target: %v", err)
 	}
-	defer os.RemoveAll(tempDir)
-	if err := utils.ExtractZip(zipPath, tempDir); err != nil {
-		return fmt.Errorf("❌ Failed to extract zip: %v", err)
+	if tfParallelism < 1 {
+		return fmt.Errorf("❌ Invalid --parallelism: must be at least 1, got %d", tfParallelism)
 	}
-	envID, err := utils.ExtractEnvIDFromDeploymentContext(tempDir)
+
+	// Verify the zip before anything is extracted
+	exportZip, removeDecrypted, err := openExportZip(zipPath, zipChecksum, decryptPassphrase)
 	if err != nil {
-		return fmt.Errorf("❌ Failed to extract environment ID from deploymentcontext.json: %v", err)
+		return fmt.Errorf("❌ %v", err)
 	}
-	fmt.Printf("🌍 Environment ID: %s\n", envID)
-	fmt.Printf("🆔 Deployment ID: %s\n", deploymentID)
+	defer removeDecrypted()
 
-	// Create base directory structure
-	homeDir, err := os.UserHomeDir()
+	// Resolve the environment and the local directories for this deployment
+	paths, err := resolveDeploymentPaths(exportZip)
 	if err != nil {
-		return fmt.Errorf("❌ Failed to get home directory: %v", err)
+		return fmt.Errorf("❌ %v", err)
 	}
-	baseDir := filepath.Join(homeDir, ".facets")
-	envDir := filepath.Join(baseDir, envID)
+	envID, deploymentID := paths.EnvID, paths.DeploymentID
+	output.Infof("🌍 Environment ID: %s\n", envID)
+	output.Infof("🆔 Deployment ID: %s\n", deploymentID)
+	output.Result().EnvironmentID = envID
+	output.Result().DeploymentID = deploymentID
 
 	// Cleanup old releases (directories and zips)
-	cleanupOldReleases(envDir, baseDir, envID)
+	cleanupOldReleases(paths.EnvDir, paths.BaseDir, envID, KeepReleasesFlag)
+
+	envDir, deployDir, tfWorkDir := paths.EnvDir, paths.DeployDir, paths.TFWorkDir
 
-	deployDir := filepath.Join(envDir, deploymentID)
-	tfWorkDir := filepath.Join(deployDir, "tfexport")
+	runLog, err := openRunLog(paths, runLogPath, "plan")
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+	defer func() { runLog.Close(err) }()
+	output.Result().OutputPath = deployDir
 
 	// Create directories
-	fmt.Printf("📁 Creating deployment directory for environment %s and deployment %s...\n", envID, deploymentID)
+	output.Infof("📁 Creating deployment directory for environment %s and deployment %s...\n", envID, deploymentID)
 	if err := os.MkdirAll(deployDir, 0755); err != nil {
 		return fmt.Errorf("❌ Failed to create directories: %v", err)
 	}
@@ -101,13 +141,13 @@ func runPlan(cmd *cobra.Command, args []string) error {
 				return fmt.Errorf("❌ Failed to list existing deployments: %v", err)
 			}
 			if len(existingDeployments) > 0 {
-				proceed, selectedDeployment, err := utils.PromptUser(existingDeployments, tfStatePath)
+				proceed, selectedDeployment, err := selectExistingState(existingDeployments, tfStatePath)
 				if err != nil {
 					return fmt.Errorf("❌ User input error: %v", err)
 				}
 				if proceed {
 					if selectedDeployment == "__USE_TF_TFSTATE__" {
-						fmt.Println("📝 Using tf.tfstate for this deployment...")
+						output.Infoln("📝 Using tf.tfstate for this deployment...")
 						stateDir := filepath.Join(tfWorkDir, "terraform.tfstate.d", envID)
 						if err := os.MkdirAll(stateDir, 0755); err != nil {
 							return fmt.Errorf("❌ Failed to create state directory: %v", err)
@@ -117,7 +157,7 @@ func runPlan(cmd *cobra.Command, args []string) error {
 							return fmt.Errorf("❌ Failed to copy tf.tfstate: %v", err)
 						}
 					} else {
-						fmt.Println("🔄 User chose to proceed with state file from existing deployment")
+						output.Infoln("🔄 User chose to proceed with state file from existing deployment")
 						if err := utils.CopyStateFromPreviousDeployment(envDir, deploymentID, envID, selectedDeployment); err != nil {
 							return fmt.Errorf("❌ Failed to copy state file: %v", err)
 						}
@@ -125,47 +165,42 @@ func runPlan(cmd *cobra.Command, args []string) error {
 				}
 			}
 		} else {
-			fmt.Printf("ℹ️  Using %s backend for state management\n", backendConfig.Type)
+			output.Infof("ℹ️  Using %s backend for state management\n", backendConfig.Type)
 		}
 		// Now extract zip contents to deployDir
-		fmt.Println("📦 Extracting terraform configuration...")
-		if err := utils.ExtractZip(zipPath, deployDir); err != nil {
-			return fmt.Errorf("❌ Failed to extract zip: %v", err)
-		}
-		// Fix permissions after extraction
-		if err := utils.FixPermissions(tfWorkDir); err != nil {
-			return fmt.Errorf("❌ Failed to fix permissions: %v", err)
+		output.Infoln("📦 Extracting terraform configuration...")
+		if err := extractDeployment(exportZip, paths); err != nil {
+			return fmt.Errorf("❌ %v", err)
 		}
 	} else {
-		fmt.Println("♻️ Using existing deployment directory")
-		// Check if zip contents differ from deployDir
-		different, err := utils.IsZipDifferentFromDir(zipPath, deployDir)
+		output.Infoln("♻️ Using existing deployment directory")
+		// Check if the zip changed since it was extracted to deployDir
+		different, err := zipChangedSinceExtract(exportZip, paths)
 		if err != nil {
-			return fmt.Errorf("❌ Failed to compare zip and directory: %v", err)
+			return fmt.Errorf("❌ Failed to compare zip with the deployment directory: %v", err)
 		}
 		if different {
-			fmt.Println("📦 Changes detected in zip, extracting to deployment directory...")
-			if err := utils.ExtractZip(zipPath, deployDir); err != nil {
-				return fmt.Errorf("❌ Failed to extract zip: %v", err)
-			}
-			// Fix permissions after extraction
-			if err := utils.FixPermissions(tfWorkDir); err != nil {
-				return fmt.Errorf("❌ Failed to fix permissions: %v", err)
+			output.Infoln("📦 Changes detected in zip, extracting to deployment directory...")
+			if err := extractDeployment(exportZip, paths); err != nil {
+				return fmt.Errorf("❌ %v", err)
 			}
 		} else {
-			fmt.Println("✅ No changes detected in zip, skipping extraction.")
+			output.Infoln("✅ No changes detected in zip, skipping extraction.")
 		}
 	}
 
+	// Resources are protected with prevent_destroy = true unless --allow-destroy is given
 	if allowDestroy {
-		fmt.Println("🔒 Enforcing prevent_destroy = true in all Terraform resources...")
-		if err := utils.UpdatePreventDestroyInTFs(tfWorkDir); err != nil {
-			return fmt.Errorf("❌ Failed to update prevent_destroy in .tf files: %v", err)
-		}
+		output.Infoln("🔓 Setting prevent_destroy = false in all Terraform resources (--allow-destroy)...")
+	} else {
+		output.Infoln("🔒 Enforcing prevent_destroy = true in all Terraform resources...")
+	}
+	if err := utils.UpdatePreventDestroyInTFs(tfWorkDir, !allowDestroy); err != nil {
+		return fmt.Errorf("❌ Failed to update prevent_destroy in .tf files: %v", err)
 	}
 
 	// Initialize terraform
-	fmt.Println("🔧 Initializing terraform...")
+	output.Infoln("🔧 Initializing terraform...")
 	tf, err := tfexec.NewTerraform(tfWorkDir, "terraform")
 	if err != nil {
 		return fmt.Errorf("❌ Failed to create terraform executor: %v", err)
@@ -173,12 +208,16 @@ func runPlan(cmd *cobra.Command, args []string) error {
 
 	// set logging for terraform
 	tf.SetLog("INFO")
-	tf.SetStderr(os.Stdout)
-	tf.SetStdout(os.Stdout)
+	tfOutput := output.Writer()
+	if planSummaryOnly {
+		tfOutput = output.LogWriter()
+	}
+	tf.SetStderr(tfOutput)
+	tf.SetStdout(tfOutput)
 
 	// Handle state file
 	if statePath != "" && backendConfig == nil {
-		fmt.Println("📝 Copying provided state file...")
+		output.Infoln("📝 Copying provided state file...")
 		stateDir := filepath.Join(tfWorkDir, "terraform.tfstate.d", envID)
 		if err := os.MkdirAll(stateDir, 0755); err != nil {
 			return fmt.Errorf("❌ Failed to create state directory: %v", err)
@@ -192,7 +231,7 @@ func runPlan(cmd *cobra.Command, args []string) error {
 		// No state file provided, check for latest.tfstate
 		latestStatePath := filepath.Join(envDir, "tf.tfstate")
 		if _, err := os.Stat(latestStatePath); err == nil {
-			fmt.Println("📝 Using latest tf.tfstate for this environment...")
+			output.Infoln("📝 Using latest tf.tfstate for this environment...")
 			stateDir := filepath.Join(tfWorkDir, "terraform.tfstate.d", envID)
 			if err := os.MkdirAll(stateDir, 0755); err != nil {
 				return fmt.Errorf("❌ Failed to create state directory: %v", err)
@@ -202,52 +241,133 @@ func runPlan(cmd *cobra.Command, args []string) error {
 				return fmt.Errorf("❌ Failed to copy latest state file: %v", err)
 			}
 		} else {
-			fmt.Println("ℹ️ No previous state found. Proceeding as a fresh deployment.")
+			output.Infoln("ℹ️ No previous state found. Proceeding as a fresh deployment.")
 		}
 	}
 
 	// Initialize terraform with backend configuration if provided
 	if backendConfig != nil {
-		fmt.Printf("🔄 Writing backend.tf.json for %s backend...\n", backendConfig.Type)
+		output.Infof("🔄 Writing backend.tf.json for %s backend...\n", backendConfig.Type)
 		if err := backendConfig.WriteBackendTFJSON(tfWorkDir); err != nil {
 			return fmt.Errorf("❌ Failed to write backend.tf.json: %v", err)
 		}
 	}
-	if err := tf.Init(context.Background()); err != nil {
-		return fmt.Errorf("❌ Terraform init failed: %v", err)
+	ctx, cancel := terraformContext()
+	defer cancel()
+	if err := tf.Init(ctx); err != nil {
+		return terraformError(ctx, "init", err)
 	}
 
 	// Select workspace/environment
-	if err := tf.WorkspaceSelect(context.Background(), envID); err != nil {
+	if err := tf.WorkspaceSelect(ctx, envID); err != nil {
 		// If workspace doesn't exist, create it
-		if err := tf.WorkspaceNew(context.Background(), envID); err != nil {
+		if err := tf.WorkspaceNew(ctx, envID); err != nil {
 			return fmt.Errorf("❌ Failed to create workspace: %v", err)
 		}
 	}
 
 	// Run terraform plan
-	planOptions := []tfexec.PlanOption{}
-	if targetAddr != "" {
-		fmt.Printf("🎯 Targeting module: %s\n", targetAddr)
-		planOptions = append(planOptions, tfexec.Target(targetAddr))
+	planOptions := terraformRunOptions[tfexec.PlanOption](targetAddrs, resolvedVarFiles, tfVars, tfParallelism)
+	planOptions = append(planOptions, refreshPlanOptions()...)
+	// The plan is always written to a file so its changes can be summarized
+	var planFile string
+	if planOutPath != "" {
+		planFile, err = resolvePlanFile(deployDir, planOutPath)
+		if err != nil {
+			return fmt.Errorf("❌ Invalid --out: %v", err)
+		}
+	} else {
+		planFile = filepath.Join(deployDir, "fctl-summary.tfplan")
+		defer os.Remove(planFile)
 	}
+	planOptions = append(planOptions, tfexec.Out(planFile))
 
-	fmt.Println("📋 Running terraform plan...")
-	planResult, err := tf.Plan(context.Background(), planOptions...)
+	output.Infoln("📋 Running terraform plan...")
+	planResult, err := tf.Plan(ctx, planOptions...)
+	if planDetailedExitCode {
+		exitCode = planExitCode(planResult, err)
+	}
 	if err != nil {
-		return fmt.Errorf("❌ Terraform plan failed: %v", err)
+		return terraformError(ctx, "plan", err)
+	}
+
+	if driftDetect {
+		return reportDrift(ctx, tf, planFile, paths)
 	}
 
 	if planResult {
-		fmt.Println("🔄 Changes detected in plan")
+		output.Successf("🔄 Changes detected in plan\n")
 	} else {
-		fmt.Println("✅ No changes. Infrastructure is up-to-date.")
+		output.Successf("✅ No changes. Infrastructure is up-to-date.\n")
+	}
+
+	if err := writePlanSummary(ctx, tf, planFile, deployDir); err != nil {
+		output.Warnf("⚠️ Warning: Failed to summarize plan: %v\n", err)
 	}
 
-	fmt.Printf("📍 Deployment directory: %s\n", deployDir)
+	output.Infof("📍 Deployment directory: %s\n", deployDir)
+	if planOutPath != "" {
+		if err := writeZipDigest(exportZip, planFile+planZipSuffix); err != nil {
+			return fmt.Errorf("❌ %v", err)
+		}
+		output.Infof("💾 Plan saved to: %s\n", planFile)
+		output.Infof("👉 Apply it with: fctl apply --zip %s --plan-file %s\n", zipPath, planOutPath)
+	}
 	if backendConfig == nil {
-		fmt.Printf("💾 State file location: %s/terraform.tfstate.d/%s/terraform.tfstate\n", tfWorkDir, envID)
+		output.Infof("💾 State file location: %s/terraform.tfstate.d/%s/terraform.tfstate\n", tfWorkDir, envID)
 	}
 
 	return nil
 }
+
+// writePlanSummary writes per-module change counts for planFile to <deployDir>/plan-summary.json
+// and prints them as a table, followed by the resources that drifted from the state.
+func writePlanSummary(ctx context.Context, tf *tfexec.Terraform, planFile, deployDir string) error {
+	plan, err := tf.ShowPlanFile(ctx, planFile)
+	if err != nil {
+		return err
+	}
+	summaries := utils.SummarizePlan(plan)
+
+	data, err := json.MarshalIndent(summaries, "", "  ")
+	if err != nil {
+		return err
+	}
+	summaryFile := filepath.Join(deployDir, "plan-summary.json")
+	if err := os.WriteFile(summaryFile, data, 0644); err != nil {
+		return err
+	}
+
+	output.Infoln("📊 Plan summary:")
+	for _, summary := range summaries {
+		output.Infof("   %s: +%d ~%d -%d\n", summary.Module, summary.Add, summary.Change, summary.Destroy)
+	}
+	output.Infof("📝 Plan summary saved to: %s\n", summaryFile)
+
+	// Drift is what --refresh-only plans for, so it is printed like the result in that mode
+	printDrift := output.Infof
+	if refreshOnly {
+		printDrift = output.Successf
+	}
+	if drifted := utils.DriftedResources(plan); len(drifted) > 0 {
+		printDrift("🌊 %d resource(s) changed outside of Terraform:\n", len(drifted))
+		for _, address := range drifted {
+			printDrift("   %s\n", address)
+		}
+	} else if refreshOnly {
+		printDrift("✅ No drift: the state matches real infrastructure\n")
+	}
+	return nil
+}
+
+// planExitCode returns the --detailed-exitcode status of a plan: 1 when it failed, 2 when it has changes,
+// and 0 when it has none
+func planExitCode(hasChanges bool, err error) int {
+	if err != nil {
+		return 1
+	}
+	if hasChanges {
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPlanExitCode(t *testing.T) {
+	tests := []struct {
+		name       string
+		hasChanges bool
+		err        error
+		want       int
+	}{
+		{name: "no changes", want: 0},
+		{name: "changes", hasChanges: true, want: 2},
+		{name: "error", err: errors.New("plan failed"), want: 1},
+		{name: "error with changes reported", hasChanges: true, err: errors.New("plan failed"), want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := planExitCode(tt.hasChanges, tt.err); got != tt.want {
+				t.Errorf("planExitCode(%v, %v) = %d, want %d", tt.hasChanges, tt.err, got, tt.want)
+			}
+		})
+	}
+}
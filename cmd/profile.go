@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Facets-cloud/fctl/pkg/config"
+	"github.com/Facets-cloud/fctl/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	profileListJSON     bool
+	profileOutputFormat string
+	profileDeleteForce  bool
+)
+
+var profileCmd = &cobra.Command{
+	Use:         "profile",
+	Aliases:     []string{"profiles"},
+	Short:       "Manage the profiles stored in your credentials file.",
+	Long:        `Manage the profiles stored in ~/.facets/credentials. Profiles hold the control plane URL, username, and token used to talk to a Facets control plane.`,
+	Annotations: map[string]string{skipAuthAnnotation: "true"},
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all profiles and mark the active one.",
+	Long:  `List every profile in ~/.facets/credentials with its control plane URL, username, and token expiry. Tokens are masked. The active profile from ~/.facets/config is marked with an arrow.`,
+	RunE:  runProfileList,
+}
+
+var profileShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show the details of a single profile.",
+	Long:  `Show the control plane URL, username, masked token, token expiry, and default status of a profile in ~/.facets/credentials.`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runProfileShow,
+}
+
+var profileDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a profile from your credentials file.",
+	Long:  `Delete a profile from ~/.facets/credentials. If it is the active profile, the default in ~/.facets/config is cleared. Deleting the last remaining profile requires --force.`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runProfileDelete,
+}
+
+var profileSetDefaultCmd = &cobra.Command{
+	Use:   "set-default <name>",
+	Short: "Make a profile the default without logging in again.",
+	Long:  `Make an existing profile from ~/.facets/credentials the default profile in ~/.facets/config. Credentials are not modified.`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runProfileSetDefault,
+}
+
+// profileSummary is the per-profile record printed by 'profile list' and 'profile show'.
+type profileSummary struct {
+	Name            string `json:"name"`
+	ControlPlaneURL string `json:"control_plane_url"`
+	Username        string `json:"username"`
+	Token           string `json:"token"`
+	TokenExpiry     string `json:"token_expiry,omitempty"`
+	Active          bool   `json:"active"`
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileListCmd)
+	profileCmd.AddCommand(profileShowCmd)
+	profileCmd.AddCommand(profileDeleteCmd)
+	profileCmd.AddCommand(profileSetDefaultCmd)
+
+	profileListCmd.Flags().StringVarP(&profileOutputFormat, "output", "o", "table", "Output format: table or json")
+	profileListCmd.Flags().BoolVar(&profileListJSON, "json", false, "Print the profiles as a JSON array (same as --output json)")
+	profileShowCmd.Flags().StringVarP(&profileOutputFormat, "output", "o", "table", "Output format: table or json")
+	profileDeleteCmd.Flags().BoolVar(&profileDeleteForce, "force", false, "Allow deleting the last remaining profile")
+}
+
+func runProfileList(cmd *cobra.Command, args []string) error {
+	if profileListJSON {
+		profileOutputFormat = "json"
+	}
+	if err := validateOutputFormat(profileOutputFormat); err != nil {
+		return err
+	}
+	profiles, err := config.ListProfiles()
+	if err != nil {
+		return fmt.Errorf("❌ %v\nPlease run 'fctl login' to create a profile", err)
+	}
+	activeProfile := config.GetDefaultProfile()
+
+	summaries := []profileSummary{}
+	for _, p := range profiles {
+		summaries = append(summaries, newProfileSummary(p, activeProfile))
+	}
+
+	if profileOutputFormat == "json" {
+		return printJSON(summaries)
+	}
+
+	if len(summaries) == 0 {
+		output.Infoln("ℹ️ No profiles found. Run 'fctl login' to create one.")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tNAME\tCONTROL PLANE URL\tUSERNAME\tTOKEN\tEXPIRES")
+	for _, p := range summaries {
+		marker := ""
+		if p.Active {
+			marker = "→"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", marker, p.Name, p.ControlPlaneURL, p.Username, p.Token, orDash(p.TokenExpiry))
+	}
+	return w.Flush()
+}
+
+func runProfileShow(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(profileOutputFormat); err != nil {
+		return err
+	}
+	profile, err := config.GetProfile(args[0])
+	if err != nil {
+		return fmt.Errorf("❌ %v\nRun 'fctl profile list' to see available profiles", err)
+	}
+	summary := newProfileSummary(*profile, config.GetDefaultProfile())
+
+	if profileOutputFormat == "json" {
+		return printJSON(summary)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s\n", summary.Name)
+	fmt.Fprintf(w, "Control plane URL:\t%s\n", orDash(summary.ControlPlaneURL))
+	fmt.Fprintf(w, "Username:\t%s\n", orDash(summary.Username))
+	fmt.Fprintf(w, "Token:\t%s\n", orDash(summary.Token))
+	fmt.Fprintf(w, "Token expiry:\t%s\n", orDash(summary.TokenExpiry))
+	fmt.Fprintf(w, "Default:\t%t\n", summary.Active)
+	return w.Flush()
+}
+
+func runProfileDelete(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	profiles, err := config.ListProfiles()
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+	if !hasProfile(profiles, name) {
+		return fmt.Errorf("❌ Profile '%s' not found", name)
+	}
+	if len(profiles) == 1 && !profileDeleteForce {
+		return fmt.Errorf("❌ Refusing to delete '%s', the last remaining profile (use --force to delete it anyway)", name)
+	}
+
+	if err := config.DeleteProfile(name); err != nil {
+		return fmt.Errorf("❌ Failed to delete profile: %v", err)
+	}
+	output.Infof("🗑️ Deleted profile '%s'\n", name)
+
+	if config.GetDefaultProfile() == name {
+		if err := config.SetDefaultProfile(""); err != nil {
+			return fmt.Errorf("❌ Failed to clear default profile: %v", err)
+		}
+		output.Warnf("⚠️ '%s' was the active profile, so no default profile is set now. Run 'fctl profile set-default <name>' to choose one.\n", name)
+	}
+	return nil
+}
+
+func runProfileSetDefault(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	profiles, err := config.ListProfiles()
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+	if !hasProfile(profiles, name) {
+		return fmt.Errorf("❌ Profile '%s' not found. Run 'fctl profile list' to see available profiles", name)
+	}
+
+	previous := config.GetDefaultProfile()
+	if previous == name {
+		output.Infof("ℹ️ '%s' is already the default profile\n", name)
+		return nil
+	}
+	if err := config.SetDefaultProfile(name); err != nil {
+		return fmt.Errorf("❌ Failed to set default profile: %v", err)
+	}
+	if previous == "" {
+		output.Infof("✅ Default profile set to '%s' (no previous default)\n", name)
+	} else {
+		output.Infof("✅ Default profile changed from '%s' to '%s'\n", previous, name)
+	}
+	return nil
+}
+
+// newProfileSummary builds the printable form of a profile, masking its token.
+func newProfileSummary(p config.Profile, activeProfile string) profileSummary {
+	return profileSummary{
+		Name:            p.Name,
+		ControlPlaneURL: p.ControlPlaneURL,
+		Username:        p.Username,
+		Token:           maskToken(p.Token),
+		TokenExpiry:     p.TokenExpiry,
+		Active:          p.Name == activeProfile,
+	}
+}
+
+// orDash returns "-" for empty table cells.
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// hasProfile reports whether a profile with the given name exists.
+func hasProfile(profiles []config.Profile, name string) bool {
+	for _, p := range profiles {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// maskToken hides a stored token, keeping only whether one is set.
+func maskToken(token string) string {
+	if token == "" {
+		return ""
+	}
+	return "***"
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// captureStdout returns what f writes to os.Stdout.
+func captureStdout(t *testing.T, f func() error) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = old }()
+
+	ferr := f()
+	w.Close()
+	out, _ := io.ReadAll(r)
+	if ferr != nil {
+		t.Fatalf("unexpected error = %v", ferr)
+	}
+	return string(out)
+}
+
+// writeProfiles sets up a HOME with two profiles in the credentials file and staging as the default.
+func writeProfiles(t *testing.T) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	files := map[string]string{
+		"credentials": "[default]\ncontrol_plane_url = https://a.example.com\nusername = alice\ntoken = secret-a\ntoken_expiry = 2026-01-02T15:04:05Z\n\n" +
+			"[staging]\ncontrol_plane_url = https://b.example.com\nusername = bob\ntoken = secret-b\n",
+		"config": "[default]\nprofile = staging\n",
+	}
+	if err := os.MkdirAll(filepath.Join(home, ".facets"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(home, ".facets", name), []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRunProfileListTable(t *testing.T) {
+	writeProfiles(t)
+	setFlag(t, &profileListJSON, false)
+	setFlag(t, &profileOutputFormat, "table")
+
+	out := captureStdout(t, func() error { return runProfileList(profileListCmd, nil) })
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("runProfileList() printed %d lines, want a header and 2 profiles:\n%s", len(lines), out)
+	}
+	tests := []struct {
+		line int
+		want []string
+	}{
+		{0, []string{"NAME", "CONTROL PLANE URL", "USERNAME", "TOKEN", "EXPIRES"}},
+		{1, []string{"default", "https://a.example.com", "alice", "***", "2026-01-02T15:04:05Z"}},
+		{2, []string{"→", "staging", "https://b.example.com", "bob", "***", "-"}},
+	}
+	for _, tt := range tests {
+		for _, want := range tt.want {
+			if !strings.Contains(lines[tt.line], want) {
+				t.Errorf("line %d = %q, want it to contain %q", tt.line, lines[tt.line], want)
+			}
+		}
+	}
+	if strings.Contains(lines[1], "→") {
+		t.Errorf("the inactive profile is marked active: %q", lines[1])
+	}
+	if strings.Contains(out, "secret") {
+		t.Errorf("runProfileList() printed a token:\n%s", out)
+	}
+}
+
+func TestRunProfileListJSON(t *testing.T) {
+	writeProfiles(t)
+	setFlag(t, &profileListJSON, true)
+	setFlag(t, &profileOutputFormat, "table")
+
+	out := captureStdout(t, func() error { return runProfileList(profileListCmd, nil) })
+	var got []profileSummary
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("runProfileList() printed invalid JSON: %v\n%s", err, out)
+	}
+	want := []profileSummary{
+		{Name: "default", ControlPlaneURL: "https://a.example.com", Username: "alice", Token: "***", TokenExpiry: "2026-01-02T15:04:05Z"},
+		{Name: "staging", ControlPlaneURL: "https://b.example.com", Username: "bob", Token: "***", Active: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("runProfileList() = %+v, want %+v", got, want)
+	}
+}
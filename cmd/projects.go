@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Facets-cloud/facets-sdk-go/facets/client/ui_stack_controller"
+	"github.com/Facets-cloud/fctl/pkg/config"
+	"github.com/Facets-cloud/fctl/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	projectsOutputFormat string
+	projectsJSON         bool
+	projectsFilter       string
+)
+
+var projectsCmd = &cobra.Command{
+	Use:   "projects",
+	Short: "Browse the projects (stacks) in your Facets control plane.",
+	Long:  `Browse the projects (stacks) available in your Facets control plane. Use the sub-commands to discover project names for flags such as --project.`,
+}
+
+var projectsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all projects (stacks) visible to your profile.",
+	Long:  `List all projects (stacks) visible to the current profile, showing the project name, ID, cloud type, and creation date. Projects are identified by name, so the ID is the name that --project accepts. Use --filter to narrow the list by name and --output json (or --json) to consume it from scripts.`,
+	RunE:  runProjectsList,
+}
+
+// projectSummary is the per-project record printed by 'projects list'. Stacks have no ID other than
+// their name, which is what --project takes.
+type projectSummary struct {
+	Name      string `json:"name"`
+	ID        string `json:"id"`
+	Cloud     string `json:"cloud"`
+	CreatedOn string `json:"created_on"`
+}
+
+func init() {
+	rootCmd.AddCommand(projectsCmd)
+	projectsCmd.AddCommand(projectsListCmd)
+
+	projectsListCmd.Flags().StringVarP(&projectsOutputFormat, "output", "o", "table", "Output format: table or json")
+	projectsListCmd.Flags().BoolVar(&projectsJSON, "json", false, "Print the projects as a JSON array (same as --output json)")
+	projectsListCmd.Flags().StringVar(&projectsFilter, "filter", "", "Only list projects whose name contains this text (case-insensitive)")
+}
+
+func runProjectsList(cmd *cobra.Command, args []string) error {
+	if projectsJSON {
+		projectsOutputFormat = "json"
+	}
+	if err := validateOutputFormat(projectsOutputFormat); err != nil {
+		return err
+	}
+
+	profile, _ := cmd.Flags().GetString("profile")
+	client, auth, err := config.GetClient(profile, false)
+	if err != nil {
+		return fmt.Errorf("❌ Could not get client: %v", err)
+	}
+
+	stacksResp, err := client.UIStackController.GetStacks(ui_stack_controller.NewGetStacksParams(), auth)
+	if err != nil {
+		if isControlPlaneDown(err) {
+			output.Errorf("🔴 The Facets control plane is currently unavailable (HTTP 503). Please try again later.\n")
+		}
+		return fmt.Errorf("❌ Could not get projects (stacks): %v", err)
+	}
+
+	var projects []projectSummary
+	for _, stack := range stacksResp.Payload {
+		projects = append(projects, projectSummary{
+			Name:      stack.Name,
+			ID:        stack.Name,
+			Cloud:     stack.Cloud,
+			CreatedOn: formatTimestamp(time.Time(stack.CreationDate)),
+		})
+	}
+	projects = filterProjects(projects, projectsFilter)
+
+	if projectsOutputFormat == "json" {
+		return printJSON(projects)
+	}
+
+	if len(projects) == 0 {
+		output.Infoln("ℹ️ No projects found.")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tID\tCLOUD\tCREATED")
+	for _, p := range projects {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", p.Name, p.ID, p.Cloud, p.CreatedOn)
+	}
+	return w.Flush()
+}
+
+// filterProjects returns the projects whose name contains filter, ignoring case.
+func filterProjects(projects []projectSummary, filter string) []projectSummary {
+	filter = strings.ToLower(filter)
+	var filtered []projectSummary
+	for _, p := range projects {
+		if strings.Contains(strings.ToLower(p.Name), filter) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterProjects(t *testing.T) {
+	projects := []projectSummary{
+		{Name: "payments", ID: "payments", Cloud: "AWS"},
+		{Name: "Payments-EU", ID: "Payments-EU", Cloud: "GCP"},
+		{Name: "search", ID: "search", Cloud: "AZURE"},
+	}
+	tests := []struct {
+		name   string
+		filter string
+		want   []string
+	}{
+		{name: "no filter", filter: "", want: []string{"payments", "Payments-EU", "search"}},
+		{name: "case-insensitive substring", filter: "PAY", want: []string{"payments", "Payments-EU"}},
+		{name: "no match", filter: "billing", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, p := range filterProjects(projects, tt.filter) {
+				got = append(got, p.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterProjects(%q) = %v, want %v", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,13 +1,16 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 
+	"github.com/Facets-cloud/fctl/pkg/output"
 	"github.com/Facets-cloud/fctl/pkg/utils"
 	"github.com/spf13/cobra"
-	"github.com/yarlson/pin"
 )
 
 var (
@@ -15,13 +18,37 @@ var (
 	repackageOutputPath string
 	repackageInplace    bool
 	copyPairs           []string // --copy source:destination
+	deletePaths         []string // --delete path-in-zip, the deprecated spelling of --remove
+	removePatterns      []string // --remove pattern
+	renamePairs         []string // --rename old:new
+	ignoreMissing       bool
+	encryptPassphrase   string
+	repackageDecrypt    string
+	repackageDiffJSON   string
+	repackageDryRun     bool
 )
 
 var repackageCmd = &cobra.Command{
 	Use:   "repackage",
-	Short: "Tweak the exported zip file by copying files from local into specific paths inside the zip.",
-	Long:  `Copy files or directories from your local system into specific directory structures inside an existing zip file. Supports multiple source:destination pairs via --copy flag.`,
-	RunE:  runRepackage,
+	Short: "Tweak the exported zip file by copying, removing, or renaming files inside it.",
+	Long: `Copy files or directories from your local system into specific directory structures inside an existing zip file, remove files and directories from it, or move them to another path, for example to strip .tfvars files with secrets before sharing the zip. Supports multiple source:destination pairs via --copy, patterns via --remove, and old:new pairs via --rename.
+
+--remove takes a path in the zip or a glob pattern, such as 'tfexport/*.tfvars'; '*' does not match '/'. Removing a directory removes everything under it, and renaming a directory moves everything under it. Removals happen first, then renames, then copies, so a removed file can be replaced. A --remove pattern or --rename path that matches nothing is an error, unless --ignore-missing is set.
+
+Use --encrypt to encrypt the output zip with AES-256-GCM under a key derived from a passphrase, for example to store or send a zip with backend credentials. apply, plan, and destroy detect an encrypted zip and decrypt it with --decrypt; repackage takes --decrypt to change an encrypted zip.
+
+When done, the files that were added, modified, and removed are listed, found by comparing the SHA-256 of every file in the input and output zips. --diff-json writes the full report, with both manifests, as JSON. With --dry-run the report is printed without writing the output zip.`,
+	RunE: runRepackage,
+}
+
+// repackageReport is the report written by 'repackage --diff-json'.
+type repackageReport struct {
+	Source        string                   `json:"source"`
+	Output        string                   `json:"output"`
+	DryRun        bool                     `json:"dry_run"`
+	SourceEntries []utils.ZipManifestEntry `json:"source_entries"`
+	OutputEntries []utils.ZipManifestEntry `json:"output_entries"`
+	utils.ZipChanges
 }
 
 func init() {
@@ -32,34 +59,38 @@ func init() {
 	repackageCmd.Flags().BoolVar(&repackageInplace, "inplace", false, "Overwrite the original zip file (default: false)")
 	repackageCmd.Flags().StringArrayVar(&copyPairs, "copy", nil, "Copy a file or directory from local into a specific path inside the zip. Format: source:destination. Can be specified multiple times.")
 
+	repackageCmd.Flags().StringArrayVar(&removePatterns, "remove", nil, "Remove the files and directories inside the zip that match a path or glob pattern. Can be specified multiple times.")
+	repackageCmd.Flags().StringArrayVar(&renamePairs, "rename", nil, "Move a file or directory inside the zip to another path. Format: old:new. Can be specified multiple times.")
+	repackageCmd.Flags().BoolVar(&ignoreMissing, "ignore-missing", false, "Warn instead of failing when a --remove pattern or --rename path matches nothing")
+	repackageCmd.Flags().StringArrayVar(&deletePaths, "delete", nil, "Delete a file or directory inside the zip, given as its path in the zip. Can be specified multiple times.")
+	repackageCmd.Flags().MarkDeprecated("delete", "use --remove instead")
+
+	repackageCmd.Flags().StringVar(&encryptPassphrase, "encrypt", "", "Encrypt the output zip with this passphrase")
+	repackageCmd.Flags().StringVar(&repackageDecrypt, "decrypt", "", "Passphrase of the input zip, when it was encrypted with --encrypt")
+
+	repackageCmd.Flags().StringVar(&repackageDiffJSON, "diff-json", "", "Write the manifests of the input and output zips and their differences to this file as JSON")
+	repackageCmd.Flags().BoolVar(&repackageDryRun, "dry-run", false, "Report the changes without writing the output zip")
+
 	repackageCmd.MarkFlagRequired("zip")
-	repackageCmd.MarkFlagsRequiredTogether("copy")
 }
 
 func runRepackage(cmd *cobra.Command, args []string) error {
-	s := pin.New("📦 Starting repackaging...",
-		pin.WithSpinnerColor(pin.ColorCyan),
-		pin.WithTextColor(pin.ColorYellow),
-		pin.WithDoneSymbol('✔'),
-		pin.WithDoneSymbolColor(pin.ColorGreen),
-		pin.WithPrefix("pin"),
-		pin.WithPrefixColor(pin.ColorMagenta),
-		pin.WithSeparatorColor(pin.ColorGray),
-	)
+	s := newSpinner("📦 Starting repackaging...")
 	cancel := s.Start(cmd.Context())
 	defer cancel()
 
-	if !repackageInplace && repackageOutputPath == "" {
+	if !repackageInplace && repackageOutputPath == "" && !repackageDryRun {
 		s.Fail("❌ --output is required unless --inplace is set")
 		return fmt.Errorf("--output is required unless --inplace is set")
 	}
 
-	if len(copyPairs) == 0 {
-		s.Fail("❌ At least one --copy <source>:<destination> pair is required")
-		return fmt.Errorf("at least one --copy <source>:<destination> pair is required")
+	removePatterns = append(removePatterns, deletePaths...)
+	if len(copyPairs) == 0 && len(removePatterns) == 0 && len(renamePairs) == 0 && encryptPassphrase == "" && repackageDecrypt == "" {
+		s.Fail("❌ At least one --copy <source>:<destination> pair, --remove <pattern>, --rename <old>:<new>, --encrypt, or --decrypt is required")
+		return fmt.Errorf("at least one --copy <source>:<destination> pair, --remove <pattern>, --rename <old>:<new>, --encrypt, or --decrypt is required")
 	}
 
-	// 1. Unzip to temp dir
+	// 1. Decrypt the zip if needed
 	s.UpdateMessage("🗂️  Creating temporary directory...")
 	tempDir, err := os.MkdirTemp("", "fctl-repackage-*")
 	if err != nil {
@@ -68,62 +99,175 @@ func runRepackage(cmd *cobra.Command, args []string) error {
 	}
 	defer os.RemoveAll(tempDir)
 
-	s.UpdateMessage("📂 Extracting zip file...")
-	if err := utils.ExtractZip(repackageZipPath, tempDir); err != nil {
-		s.Fail("❌ Failed to extract zip")
-		return fmt.Errorf("failed to extract zip: %w", err)
+	inputZip := repackageZipPath
+	encrypted, err := utils.IsEncryptedZip(repackageZipPath)
+	if err != nil {
+		s.Fail("❌ Failed to read zip")
+		return fmt.Errorf("failed to read zip: %w", err)
+	}
+	if encrypted {
+		if repackageDecrypt == "" {
+			s.Fail("❌ The zip is encrypted")
+			return fmt.Errorf("%s is encrypted; pass the passphrase with --decrypt", repackageZipPath)
+		}
+		s.UpdateMessage("🔓 Decrypting zip file...")
+		inputZip = filepath.Join(tempDir, "input.zip")
+		if err := utils.DecryptFile(repackageZipPath, inputZip, repackageDecrypt); err != nil {
+			s.Fail("❌ Failed to decrypt zip")
+			return fmt.Errorf("failed to decrypt zip: %w", err)
+		}
 	}
 
-	// 2. For each copy pair, copy file/dir to destination inside temp dir
-	for _, pair := range copyPairs {
-		sepIdx := -1
-		for i, c := range pair {
-			if c == ':' {
-				sepIdx = i
-				break
+	// 2. Check the patterns to remove, the paths to rename, and the files to copy
+	edit := utils.ZipEdit{}
+	for _, p := range removePatterns {
+		pattern, err := zipEntryPath("--remove", p)
+		if err == nil {
+			if _, matchErr := path.Match(pattern, ""); matchErr != nil {
+				err = fmt.Errorf("invalid --remove pattern: %s (%v)", p, matchErr)
 			}
 		}
-		if sepIdx == -1 {
-			s.Fail(fmt.Sprintf("❌ Invalid --copy value: %s (expected format source:destination)", pair))
-			return fmt.Errorf("invalid --copy value: %s (expected format source:destination)", pair)
+		if err != nil {
+			s.Fail("❌ " + err.Error())
+			return err
+		}
+		edit.Remove = append(edit.Remove, pattern)
+	}
+	for _, pair := range renamePairs {
+		oldPath, newPath, ok := strings.Cut(pair, ":")
+		if !ok {
+			s.Fail(fmt.Sprintf("❌ Invalid --rename value: %s (expected old:new)", pair))
+			return fmt.Errorf("invalid --rename value: %s (expected old:new)", pair)
 		}
-		source := pair[:sepIdx]
-		dest := pair[sepIdx+1:]
-		if source == "" || dest == "" {
-			s.Fail(fmt.Sprintf("❌ Invalid --copy value: %s (source and destination required)", pair))
-			return fmt.Errorf("invalid --copy value: %s (source and destination required)", pair)
+		rename := utils.ZipRename{}
+		if rename.Old, err = zipEntryPath("--rename", oldPath); err == nil {
+			rename.New, err = zipEntryPath("--rename", newPath)
 		}
-		destPath := filepath.Join(tempDir, dest)
-		s.UpdateMessage("📄 Copying files to zip structure...")
-		srcInfo, err := os.Stat(source)
 		if err != nil {
-			s.Fail(fmt.Sprintf("❌ Failed to stat source: %s", source))
-			return fmt.Errorf("failed to stat source %s: %w", source, err)
-		}
-		if srcInfo.IsDir() {
-			if err := utils.CopyDir(source, destPath); err != nil {
-				s.Fail(fmt.Sprintf("❌ Failed to copy directory: %s", source))
-				return fmt.Errorf("failed to copy directory %s: %w", source, err)
-			}
-		} else {
-			if err := utils.CopyFile(source, destPath); err != nil {
-				s.Fail(fmt.Sprintf("❌ Failed to copy file: %s", source))
-				return fmt.Errorf("failed to copy file %s: %w", source, err)
-			}
+			s.Fail("❌ " + err.Error())
+			return err
 		}
+		edit.Rename = append(edit.Rename, rename)
+	}
+	edit.Add, err = parseCopyPairs(copyPairs)
+	if err != nil {
+		s.Fail("❌ " + err.Error())
+		return err
+	}
+
+	s.UpdateMessage("🔍 Matching paths in zip...")
+	unmatchedRemoves, unmatchedRenames, err := utils.UnmatchedZipEdits(inputZip, edit)
+	if err != nil {
+		s.Fail("❌ Failed to read zip")
+		return fmt.Errorf("failed to read zip: %w", err)
+	}
+	var unmatched []string
+	for _, p := range unmatchedRemoves {
+		unmatched = append(unmatched, fmt.Sprintf("--remove %s matches nothing in the zip", p))
+	}
+	for _, p := range unmatchedRenames {
+		unmatched = append(unmatched, fmt.Sprintf("--rename %s matches nothing in the zip", p))
+	}
+	if len(unmatched) > 0 && !ignoreMissing {
+		s.Fail("❌ " + strings.Join(unmatched, "\n❌ "))
+		return fmt.Errorf("%d path(s) matched nothing in the zip; pass --ignore-missing to skip them", len(unmatched))
 	}
 
-	// 3. Zip temp dir to output
+	// 3. Copy the entries of the zip to the output, leaving out the deleted paths and adding the copied files.
+	// The entries are copied as they are, so the zip is never extracted.
 	outputZip := repackageZipPath
 	if !repackageInplace {
 		outputZip = repackageOutputPath
 	}
-	s.UpdateMessage("🗜️ Creating new zip file...")
-	if err := utils.ZipDir(tempDir, outputZip); err != nil {
+	plainZip := outputZip
+	if encryptPassphrase != "" || repackageDryRun {
+		plainZip = filepath.Join(tempDir, "output.zip")
+	}
+	// The input is hashed first, as with --inplace it is overwritten
+	s.UpdateMessage("🔢 Hashing input zip...")
+	sourceEntries, err := utils.ZipManifest(inputZip)
+	if err != nil {
+		s.Fail("❌ Failed to read zip")
+		return fmt.Errorf("failed to read zip: %w", err)
+	}
+	s.UpdateMessage("🗜️ Writing new zip file...")
+	if err := utils.RewriteZip(inputZip, plainZip, edit); err != nil {
 		s.Fail("❌ Failed to create zip")
 		return fmt.Errorf("failed to create zip: %w", err)
 	}
+	s.UpdateMessage("🔢 Hashing output zip...")
+	outputEntries, err := utils.ZipManifest(plainZip)
+	if err != nil {
+		s.Fail("❌ Failed to read new zip")
+		return fmt.Errorf("failed to read new zip: %w", err)
+	}
+	report := repackageReport{
+		Source:        repackageZipPath,
+		Output:        outputZip,
+		DryRun:        repackageDryRun,
+		SourceEntries: sourceEntries,
+		OutputEntries: outputEntries,
+		ZipChanges:    utils.CompareZipManifests(sourceEntries, outputEntries),
+	}
+	if report.SourceEntries == nil {
+		report.SourceEntries = []utils.ZipManifestEntry{}
+	}
+	if report.OutputEntries == nil {
+		report.OutputEntries = []utils.ZipManifestEntry{}
+	}
+	if repackageDiffJSON != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			s.Fail("❌ Failed to encode report")
+			return fmt.Errorf("failed to encode report: %w", err)
+		}
+		if err := os.WriteFile(repackageDiffJSON, data, 0644); err != nil {
+			s.Fail("❌ Failed to write report")
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+	}
 
-	s.Stop(fmt.Sprintf("✅ Repackaged zip created at: %s", outputZip))
+	if repackageDryRun {
+		s.Stop("🔍 Dry run: no zip was written")
+	} else {
+		if encryptPassphrase != "" {
+			s.UpdateMessage("🔒 Encrypting zip file...")
+			if err := utils.EncryptFile(plainZip, outputZip, encryptPassphrase); err != nil {
+				s.Fail("❌ Failed to encrypt zip")
+				return fmt.Errorf("failed to encrypt zip: %w", err)
+			}
+		}
+		s.Stop(fmt.Sprintf("✅ Repackaged zip created at: %s", outputZip))
+	}
+	for _, msg := range unmatched {
+		output.Warnf("⚠️ %s\n", msg)
+	}
+	printZipChanges(report.ZipChanges)
+	if repackageDiffJSON != "" {
+		output.Infof("📝 Report saved to: %s\n", repackageDiffJSON)
+	}
 	return nil
 }
+
+// printZipChanges lists the files that repackage added, modified, and removed
+func printZipChanges(changes utils.ZipChanges) {
+	fmt.Printf("%d added, %d modified, %d removed\n", len(changes.Added), len(changes.Modified), len(changes.Removed))
+	for _, e := range changes.Added {
+		fmt.Println(output.Colorize(output.Green, "  + "+e.Path))
+	}
+	for _, e := range changes.Modified {
+		fmt.Println(output.Colorize(output.Yellow, "  ~ "+e.Path))
+	}
+	for _, e := range changes.Removed {
+		fmt.Println(output.Colorize(output.Red, "  - "+e.Path))
+	}
+}
+
+// zipEntryPath cleans a path inside the zip given with flag and converts it to slashes
+func zipEntryPath(flag, p string) (string, error) {
+	rel := filepath.Clean(filepath.FromSlash(p))
+	if filepath.IsAbs(rel) || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid %s value: %s (expected a path inside the zip)", flag, p)
+	}
+	return filepath.ToSlash(rel), nil
+}
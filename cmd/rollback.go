@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Facets-cloud/fctl/pkg/output"
+	"github.com/Facets-cloud/fctl/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rollbackEnvID        string
+	rollbackDeploymentID string
+)
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Re-apply a previous local deployment of an environment.",
+	Long: `Roll an environment back by re-applying the configuration of a previous deployment. The deployments in ~/.facets/<environment-id>/ are ordered by modification time and the second newest is applied, or the one given with --deployment-id. Its zip is rebuilt from the extracted directory, so the original zip does not have to be kept.
+
+Without a backend, the environment's newest state (tf.tfstate, or the state of the newest deployment) is applied on, so that Terraform changes the infrastructure back instead of working from the old deployment's outdated state. The apply itself runs as with 'fctl apply', including the check for destroyed resources; pass --force to allow them.`,
+	RunE: runRollback,
+}
+
+func init() {
+	rootCmd.AddCommand(rollbackCmd)
+
+	rollbackCmd.Flags().StringVarP(&rollbackEnvID, "environment-id", "e", "", "Environment to roll back (required)")
+	rollbackCmd.Flags().StringVar(&rollbackDeploymentID, "deployment-id", "", "Deployment ID to roll back to (default: the deployment before the newest)")
+	rollbackCmd.Flags().StringVar(&backendType, "backend", "", "Terraform backend type for state (s3, gcs, azurerm, consul). Falls back to TF_BACKEND_TYPE")
+	rollbackCmd.Flags().StringArrayVar(&backendConfigPairs, "backend-config", nil, "Backend variable in the form key=value, overriding TF_BACKEND_<TYPE>_<KEY>. Can be specified multiple times.")
+	rollbackCmd.Flags().StringVar(&backendConfigFile, "backend-config-file", "", "Path to a JSON or HCL file of backend variables (key = value). --backend-config values take precedence")
+	rollbackCmd.Flags().StringVar(&vaultAddr, "vault-addr", "", "Address of the Vault server to read backend variables from when TF_BACKEND_<TYPE>_VAULT_PATH is set. Falls back to VAULT_ADDR")
+	rollbackCmd.Flags().StringVar(&vaultToken, "vault-token", "", "Token for --vault-addr. Falls back to VAULT_TOKEN")
+	rollbackCmd.Flags().StringArrayVar(&varFiles, "var-file", nil, "Path to a Terraform variables file (.tfvars). Can be specified multiple times.")
+	rollbackCmd.Flags().StringArrayVar(&tfVars, "var", nil, "Set a Terraform variable, in the form name=value. Can be specified multiple times.")
+	rollbackCmd.Flags().BoolVar(&applyForce, "force", false, "Apply even if the plan destroys or replaces resources. Implies --allow-destroy, as Terraform refuses such plans while prevent_destroy = true")
+	rollbackCmd.Flags().IntVar(&tfParallelism, "parallelism", 10, "Number of concurrent Terraform operations. Lower it for cloud accounts with strict API rate limits")
+	rollbackCmd.Flags().DurationVar(&tfTimeout, "timeout", 0, "Stop Terraform if it runs longer than this (e.g. 90m, 2h); 0 means no limit")
+	rollbackCmd.Flags().BoolVar(&jsonOutput, "json", false, "Suppress progress output and print a single JSON result when done")
+
+	rollbackCmd.MarkFlagRequired("environment-id")
+	rollbackCmd.RegisterFlagCompletionFunc("environment-id", completeLocalEnvironmentIDs)
+	rollbackCmd.RegisterFlagCompletionFunc("deployment-id", completeLocalDeploymentIDs)
+}
+
+func runRollback(cmd *cobra.Command, args []string) error {
+	envPaths, err := newDeploymentPaths(rollbackEnvID, "")
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+	envDir := envPaths.EnvDir
+	deployments := listByModTime(envDir, func(entry os.DirEntry) bool {
+		if !entry.IsDir() {
+			return false
+		}
+		_, err := os.Stat(filepath.Join(envDir, entry.Name(), "tfexport"))
+		return err == nil
+	})
+	if len(deployments) == 0 {
+		return fmt.Errorf("❌ No local deployments found for environment %s in %s", rollbackEnvID, envDir)
+	}
+	current := deployments[len(deployments)-1]
+
+	var target string
+	if rollbackDeploymentID != "" {
+		target = filepath.Join(envDir, rollbackDeploymentID)
+		if _, err := os.Stat(filepath.Join(target, "tfexport")); err != nil {
+			return fmt.Errorf("❌ No local deployment %s found for environment %s in %s", rollbackDeploymentID, rollbackEnvID, envDir)
+		}
+		if target == current {
+			return fmt.Errorf("❌ Deployment %s is already the newest deployment of environment %s; pass the ID of a previous one", rollbackDeploymentID, rollbackEnvID)
+		}
+	} else {
+		if len(deployments) < 2 {
+			return fmt.Errorf("❌ No previous deployment to roll back to: environment %s has only one local deployment (%s). Older deployments may have been removed by the --keep-releases cleanup", rollbackEnvID, filepath.Base(current))
+		}
+		target = deployments[len(deployments)-2]
+	}
+	targetID := filepath.Base(target)
+	output.Infof("⏪ Rolling back environment %s from deployment %s to %s\n", rollbackEnvID, filepath.Base(current), targetID)
+
+	tempDir, err := os.MkdirTemp("", "fctl-rollback-*")
+	if err != nil {
+		return fmt.Errorf("❌ Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// The zip is named after the deployment, so apply reuses its directory
+	output.Infof("📦 Rebuilding the zip of deployment %s...\n", targetID)
+	rollbackZip := filepath.Join(tempDir, targetID+".zip")
+	if err := utils.ZipDirWithOptions(target, rollbackZip, utils.ZipOptions{Exclude: isLocalDeploymentFile}); err != nil {
+		return fmt.Errorf("❌ Failed to zip deployment %s: %v", targetID, err)
+	}
+
+	// The state in the old deployment directory is from before the newer deployments were applied.
+	// With a backend, the state is remote and already up to date.
+	if backendType == "" && os.Getenv("TF_BACKEND_TYPE") == "" {
+		for _, state := range []string{
+			filepath.Join(envDir, "tf.tfstate"),
+			filepath.Join(current, "tfexport", "terraform.tfstate.d", rollbackEnvID, "terraform.tfstate"),
+		} {
+			if _, err := os.Stat(state); err != nil {
+				continue
+			}
+			output.Infof("📝 Using the newest state of the environment: %s\n", state)
+			statePath = filepath.Join(tempDir, "terraform.tfstate")
+			if err := utils.CopyFile(state, statePath); err != nil {
+				return fmt.Errorf("❌ Failed to copy state file: %v", err)
+			}
+			break
+		}
+	}
+
+	// Mark the deployment as the newest, so the release cleanup in apply keeps it
+	now := time.Now()
+	if err := os.Chtimes(target, now, now); err != nil {
+		return fmt.Errorf("❌ Failed to update %s: %v", target, err)
+	}
+
+	zipPath = rollbackZip
+	return runApply(cmd, args)
+}
+
+// isLocalDeploymentFile reports whether the path, relative to a deployment directory, was written by
+// fctl or Terraform after the zip was extracted, rather than being part of the exported configuration.
+func isLocalDeploymentFile(relPath string, info os.FileInfo) bool {
+	name := path.Base(relPath)
+	if info.IsDir() {
+		return name == ".terraform" || name == "terraform.tfstate.d" || relPath == "state-backups"
+	}
+	switch {
+	case name == "release-metadata.json", name == "plan-summary.json", name == "backend.tf.json", relPath == extractedZipFile:
+		return true
+	case strings.HasPrefix(name, runLogPrefix) && strings.HasSuffix(name, ".log"):
+		return true
+	case strings.HasSuffix(name, ".tfplan"), strings.HasSuffix(name, planZipSuffix), strings.HasSuffix(name, ".tfstate"), strings.HasSuffix(name, ".tfstate.backup"):
+		return true
+	}
+	return false
+}
@@ -3,9 +3,12 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/Facets-cloud/fctl/pkg/config"
+	"github.com/Facets-cloud/fctl/pkg/output"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var asciiArt = `
@@ -20,11 +23,26 @@ var asciiArt = `
 var description = "Facets iac-export Controller. A command-line tool to manage infrastructure, environments, deployments, and resources in an air-gapped clouds. It is designed to help users interact with Facets projects and automate workflows around infrastructure as code, primarily using Terraform."
 
 var AllowDestroyFlag bool
+var KeepReleasesFlag int
+var VerboseFlag bool
+var QuietFlag bool
+var NoColorFlag bool
+var NonInteractiveFlag bool
+var BaseDirFlag string
+
+// exitCode is the process exit status for a run that returned no error (e.g. plan --detailed-exitcode)
+var exitCode int
+
+// skipAuthAnnotation marks commands that work on local files only and must not require a valid login.
+const skipAuthAnnotation = "fctl.skip-auth"
+
+// noBannerAnnotation marks commands whose stdout is data (e.g. a state file) that the banner would corrupt.
+const noBannerAnnotation = "fctl.no-banner"
 
 var rootCmd = &cobra.Command{
 	Use:   "fctl",
 	Short: "Facets iac-export Controller: Export Facets Environments as Terraform Configurations.",
-	Long:  fmt.Sprintf("\033[35m%s\033[0m\n", description),
+	Long:  description,
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println(asciiArt)
 		fmt.Println()
@@ -34,9 +52,15 @@ var rootCmd = &cobra.Command{
 
 func Execute() {
 	rootCmd.SuggestionsMinimumDistance = 1
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	if output.JSON() {
+		output.Result().Fail(err)
+		output.Result().Write(os.Stdout)
+	}
+	if err != nil {
 		os.Exit(1)
 	}
+	os.Exit(exitCode)
 }
 
 // GetRootCommand returns the root command for embedding in other CLIs
@@ -46,17 +70,54 @@ func GetRootCommand() *cobra.Command {
 
 func init() {
 	rootCmd.PersistentFlags().StringP("profile", "p", "", "The profile to use from your credentials file")
-	rootCmd.PersistentFlags().BoolVar(&AllowDestroyFlag, "allow-destroy", false, "Allow resource destroy by setting prevent_destroy = false in all Terraform resources")
+	rootCmd.PersistentFlags().BoolVar(&AllowDestroyFlag, "allow-destroy", false, "Allow resource destroy by setting prevent_destroy = false in all Terraform resources (by default prevent_destroy = true is enforced). Implied by destroy and apply --force")
+	rootCmd.PersistentFlags().IntVar(&KeepReleasesFlag, "keep-releases", 10, "Number of local deployment directories and zips to keep per environment (0 disables cleanup). Defaults to keep_releases in ~/.facets/fctl.ini, else 10")
+	rootCmd.PersistentFlags().BoolVarP(&VerboseFlag, "verbose", "v", false, "Print debug output")
+	rootCmd.PersistentFlags().BoolVarP(&QuietFlag, "quiet", "q", false, "Print errors and warnings only; progress messages and Terraform's own output are suppressed")
+	rootCmd.MarkFlagsMutuallyExclusive("verbose", "quiet")
+	rootCmd.PersistentFlags().BoolVar(&NoColorFlag, "no-color", false, "Disable ANSI colors in output. Also disabled when NO_COLOR is set")
+	rootCmd.RegisterFlagCompletionFunc("profile", completeProfiles)
+	rootCmd.PersistentFlags().StringVar(&BaseDirFlag, "base-dir", "", "Directory for extracted deployments and their state (default base_dir in ~/.facets/fctl.ini, else ~/.facets)")
+	rootCmd.PersistentFlags().BoolVar(&NonInteractiveFlag, "non-interactive", false, "Never prompt; when earlier deployments exist, use tf.tfstate if present or start with a fresh state")
+
+	// The description is colored when help is shown, once --no-color has been parsed
+	defaultHelp := rootCmd.HelpFunc()
+	rootCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		output.SetColor(!NoColorFlag)
+		if cmd == rootCmd {
+			cmd.Long = output.Colorize(output.Magenta, description) + "\n"
+		}
+		defaultHelp(cmd, args)
+	})
 
 	// Move PersistentPreRunE assignment here to avoid initialization cycle
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		// Flags that were not passed take their values from FCTL_* environment variables, else from ~/.facets/fctl.ini
+		if err := BindEnvToFlags(cmd); err != nil {
+			return fmt.Errorf("❌ %v", err)
+		}
+		if err := applySettings(cmd); err != nil {
+			return fmt.Errorf("❌ %v", err)
+		}
+		output.SetJSON(jsonOutput)
+		output.SetVerbose(VerboseFlag)
+		output.SetQuiet(QuietFlag)
+		output.SetColor(!NoColorFlag)
+		// Completion requests write candidates to stdout and must work before login
+		if isCompletionRequest(cmd) {
+			output.SetQuiet(true)
+			return nil
+		}
 		// Only print banner if not the root command
 		if cmd == rootCmd {
 			return nil
 		}
-		fmt.Println(asciiArt)
-		fmt.Println()
-		if cmd.Use == "login" {
+		// Keep stdout machine-readable when JSON output is requested
+		if !wantsJSON(cmd) && !QuietFlag && cmd.Annotations[noBannerAnnotation] != "true" {
+			fmt.Println(asciiArt)
+			fmt.Println()
+		}
+		if cmd.Use == "login" || skipsAuth(cmd) {
 			return nil
 		}
 		profile, _ := cmd.Flags().GetString("profile")
@@ -67,3 +128,63 @@ func init() {
 		return nil
 	}
 }
+
+// BindEnvToFlags sets each persistent flag of the root command that was not passed on the command line
+// from its environment variable, FCTL_<FLAG_NAME> (e.g. --base-dir from FCTL_BASE_DIR). The flags stay
+// marked as not passed, so explicit flags keep taking precedence wherever that is checked.
+func BindEnvToFlags(cmd *cobra.Command) error {
+	flags := cmd.Root().PersistentFlags()
+	var err error
+	flags.VisitAll(func(flag *pflag.Flag) {
+		value := os.Getenv(flagEnvVar(flag.Name))
+		if err != nil || value == "" || flag.Changed {
+			return
+		}
+		if setErr := flag.Value.Set(value); setErr != nil {
+			err = fmt.Errorf("invalid %s value %q: %v", flagEnvVar(flag.Name), value, setErr)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	// Cobra only rejects --verbose with --quiet when both are passed. One that is set from the environment
+	// gives way to the other when that is passed, and both set from the environment are an error.
+	verbose, quiet := flags.Lookup("verbose"), flags.Lookup("quiet")
+	if verbose == nil || quiet == nil || verbose.Value.String() != "true" || quiet.Value.String() != "true" {
+		return nil
+	}
+	switch {
+	case verbose.Changed:
+		return quiet.Value.Set("false")
+	case quiet.Changed:
+		return verbose.Value.Set("false")
+	}
+	return fmt.Errorf("%s and %s cannot both be set", flagEnvVar("verbose"), flagEnvVar("quiet"))
+}
+
+// flagEnvVar returns the environment variable BindEnvToFlags reads for a flag
+func flagEnvVar(name string) string {
+	return "FCTL_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// skipsAuth reports whether cmd or one of its parents is annotated with skipAuthAnnotation.
+func skipsAuth(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Annotations[skipAuthAnnotation] == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// wantsJSON reports whether cmd was asked for JSON output via --json or --output json.
+func wantsJSON(cmd *cobra.Command) bool {
+	if jsonFlag, err := cmd.Flags().GetBool("json"); err == nil && jsonFlag {
+		return true
+	}
+	if outputFlag, err := cmd.Flags().GetString("output"); err == nil && outputFlag == "json" {
+		return true
+	}
+	return false
+}
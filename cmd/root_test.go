@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// newFlagTestCommand returns a subcommand of a fresh root command with fctl's profile, verbose, and quiet
+// flags, after parsing args
+func newFlagTestCommand(t *testing.T, args ...string) *cobra.Command {
+	t.Helper()
+	root := &cobra.Command{Use: "fctl"}
+	root.PersistentFlags().StringP("profile", "p", "", "")
+	root.PersistentFlags().BoolP("verbose", "v", false, "")
+	root.PersistentFlags().BoolP("quiet", "q", false, "")
+	child := &cobra.Command{Use: "apply", Run: func(*cobra.Command, []string) {}}
+	root.AddCommand(child)
+	if err := child.ParseFlags(args); err != nil {
+		t.Fatal(err)
+	}
+	return child
+}
+
+func TestBindEnvToFlags(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		args        []string
+		wantProfile string
+		wantVerbose bool
+		wantQuiet   bool
+		wantErr     bool
+	}{
+		{name: "no environment", wantProfile: ""},
+		{name: "environment fills an unset flag", env: map[string]string{"FCTL_PROFILE": "staging"}, wantProfile: "staging"},
+		{name: "explicit flag wins", env: map[string]string{"FCTL_PROFILE": "staging"}, args: []string{"-p", "prod"}, wantProfile: "prod"},
+		{name: "explicit empty flag wins", env: map[string]string{"FCTL_PROFILE": "staging"}, args: []string{"--profile="}, wantProfile: ""},
+		{name: "invalid boolean", env: map[string]string{"FCTL_VERBOSE": "loud"}, wantErr: true},
+		{name: "verbose from environment", env: map[string]string{"FCTL_VERBOSE": "1"}, wantVerbose: true},
+		{name: "explicit quiet wins over environment verbose", env: map[string]string{"FCTL_VERBOSE": "1"}, args: []string{"-q"}, wantQuiet: true},
+		{name: "explicit verbose wins over environment quiet", env: map[string]string{"FCTL_QUIET": "true"}, args: []string{"-v"}, wantVerbose: true},
+		{name: "verbose and quiet from environment", env: map[string]string{"FCTL_VERBOSE": "1", "FCTL_QUIET": "1"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"FCTL_PROFILE", "FCTL_VERBOSE", "FCTL_QUIET"} {
+				t.Setenv(name, tt.env[name])
+			}
+			cmd := newFlagTestCommand(t, tt.args...)
+
+			err := BindEnvToFlags(cmd)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BindEnvToFlags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			profile, _ := cmd.Flags().GetString("profile")
+			verbose, _ := cmd.Flags().GetBool("verbose")
+			quiet, _ := cmd.Flags().GetBool("quiet")
+			if profile != tt.wantProfile || verbose != tt.wantVerbose || quiet != tt.wantQuiet {
+				t.Errorf("profile, verbose, quiet = %q, %v, %v; want %q, %v, %v",
+					profile, verbose, quiet, tt.wantProfile, tt.wantVerbose, tt.wantQuiet)
+			}
+		})
+	}
+}
+
+func TestFlagEnvVar(t *testing.T) {
+	tests := map[string]string{
+		"profile":         "FCTL_PROFILE",
+		"base-dir":        "FCTL_BASE_DIR",
+		"non-interactive": "FCTL_NON_INTERACTIVE",
+	}
+	for name, want := range tests {
+		if got := flagEnvVar(name); got != want {
+			t.Errorf("flagEnvVar(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestWantsJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags func(*cobra.Command)
+		args  []string
+		want  bool
+	}{
+		{name: "no json flag", flags: func(*cobra.Command) {}, want: false},
+		{name: "--json not passed", flags: func(c *cobra.Command) { c.Flags().Bool("json", false, "") }, want: false},
+		{name: "--json", flags: func(c *cobra.Command) { c.Flags().Bool("json", false, "") }, args: []string{"--json"}, want: true},
+		{name: "--output table", flags: func(c *cobra.Command) { c.Flags().StringP("output", "o", "table", "") }, want: false},
+		{name: "--output json", flags: func(c *cobra.Command) { c.Flags().StringP("output", "o", "table", "") }, args: []string{"-o", "json"}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{Use: "list"}
+			tt.flags(cmd)
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			if got := wantsJSON(cmd); got != tt.want {
+				t.Errorf("wantsJSON() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,569 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Facets-cloud/fctl/pkg/output"
+	"github.com/Facets-cloud/fctl/pkg/utils"
+	"github.com/hashicorp/terraform-exec/tfexec"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/spf13/cobra"
+)
+
+var (
+	stateZipPath      string
+	stateEnvID        string
+	stateDeploymentID string
+	stateFilter       string
+	stateDryRun       bool
+	stateOutPath      string
+	statePushForce    bool
+	statePushFile     string
+	stateShowJSON     bool
+)
+
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Inspect and modify the Terraform state of an applied export.",
+	Long:  `Inspect and modify the Terraform state of a deployment that was applied with 'fctl apply'. The workspace is located from the exported zip in the same way as apply (~/.facets/<environment-id>/<deployment-id>/tfexport), or with --environment-id and an optional --deployment, which defaults to the newest local deployment of the environment.`,
+	// State commands work on the local workspace only
+	Annotations: map[string]string{skipAuthAnnotation: "true"},
+}
+
+var stateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the resource addresses in the state.",
+	Long:  `List every resource address tracked in the deployment's Terraform state, like 'terraform state list'. Use --filter to keep only addresses matching a regular expression.`,
+	RunE:  runStateList,
+}
+
+var stateRmCmd = &cobra.Command{
+	Use:   "rm <address>",
+	Short: "Remove a resource from the state without destroying it.",
+	Long:  `Remove a resource from the deployment's Terraform state, like 'terraform state rm'. The real infrastructure object is left untouched and is no longer managed. A backup of the state is written to <deployment-dir>/state-backups/ first.`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runStateRm,
+}
+
+var stateMvCmd = &cobra.Command{
+	Use:   "mv <source> <destination>",
+	Short: "Move a resource to a new address in the state.",
+	Long:  `Move a resource or module to a new address in the deployment's Terraform state, like 'terraform state mv'. Use it after renaming resources so Terraform doesn't destroy and recreate them. A backup of the state is written to <deployment-dir>/state-backups/ first. Refuses to run while the state is locked.`,
+	Args:  cobra.ExactArgs(2),
+	RunE:  runStateMv,
+}
+
+var statePullCmd = &cobra.Command{
+	Use:         "pull",
+	Short:       "Print the current state as JSON.",
+	Long:        `Read the deployment's Terraform state, like 'terraform state pull', and write it to stdout or to the file given by --output. With --backend, the state is read from that backend instead, which is configured for the deployment first.`,
+	Annotations: map[string]string{noBannerAnnotation: "true"},
+	RunE:        runStatePull,
+}
+
+var statePushCmd = &cobra.Command{
+	Use:   "push [file]",
+	Short: "Replace the state with a local state file.",
+	Long: `Replace the deployment's Terraform state with a local state file, like 'terraform state push'. The file is given as an argument or with --file and must be valid JSON with a "version" field. A backup of the current state is written to <deployment-dir>/state-backups/ first, and you are asked to confirm unless --force is given. With --non-interactive, the push is refused unless --force is given.
+
+The push is refused if the file belongs to a different state (its lineage differs) or is older than the current state (its serial is lower), unless --force is given.
+
+With --backend, the deployment is configured for that backend first, so a state that started out local can be migrated to S3, GCS, and the others: pull it with 'fctl state pull --output', then push it with --backend.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runStatePush,
+}
+
+var stateShowCmd = &cobra.Command{
+	Use:         "show <address>",
+	Short:       "Show the attributes of a resource in the state.",
+	Long:        `Show the attributes of a single resource in the deployment's Terraform state, like 'terraform state show'. Use --json to print the resource as it appears in 'terraform show -json'.`,
+	Args:        cobra.ExactArgs(1),
+	Annotations: map[string]string{noBannerAnnotation: "true"},
+	RunE:        runStateShow,
+}
+
+func init() {
+	rootCmd.AddCommand(stateCmd)
+	stateCmd.AddCommand(stateListCmd)
+	stateCmd.AddCommand(stateShowCmd)
+	stateCmd.AddCommand(stateRmCmd)
+	stateCmd.AddCommand(stateMvCmd)
+	stateCmd.AddCommand(statePullCmd)
+	stateCmd.AddCommand(statePushCmd)
+
+	stateCmd.PersistentFlags().StringVarP(&stateZipPath, "zip", "z", "", "Path to the exported zip file")
+	stateCmd.PersistentFlags().StringVarP(&stateEnvID, "environment-id", "e", "", "Environment whose local deployment to use instead of --zip")
+	stateCmd.PersistentFlags().StringVar(&stateDeploymentID, "deployment", "", "Deployment ID to use with --environment-id (default: the newest local deployment)")
+	stateCmd.MarkFlagsOneRequired("zip", "environment-id")
+	stateCmd.MarkFlagsMutuallyExclusive("zip", "environment-id")
+	stateCmd.MarkFlagsMutuallyExclusive("zip", "deployment")
+	stateCmd.RegisterFlagCompletionFunc("environment-id", completeLocalEnvironmentIDs)
+	stateCmd.RegisterFlagCompletionFunc("deployment", completeLocalDeploymentIDs)
+
+	stateListCmd.Flags().StringVar(&stateFilter, "filter", "", "Only list addresses matching this regular expression")
+	stateShowCmd.Flags().BoolVar(&stateShowJSON, "json", false, "Print the resource as JSON from 'terraform show -json'")
+	stateRmCmd.Flags().BoolVar(&stateDryRun, "dry-run", false, "Print what would be removed without changing the state")
+	statePullCmd.Flags().StringVar(&stateOutPath, "output", "", "Write the state to this file instead of stdout")
+	statePushCmd.Flags().StringVar(&statePushFile, "file", "", "Path to the state file to push (instead of the argument)")
+	statePushCmd.Flags().BoolVar(&statePushForce, "force", false, "Push without asking for confirmation, even if the lineage differs or the serial is older")
+	for _, c := range []*cobra.Command{statePullCmd, statePushCmd} {
+		c.Flags().StringVar(&backendType, "backend", "", "Terraform backend type for state (s3, gcs, azurerm, consul). Falls back to TF_BACKEND_TYPE")
+		c.Flags().StringArrayVar(&backendConfigPairs, "backend-config", nil, "Backend variable in the form key=value, overriding TF_BACKEND_<TYPE>_<KEY>. Can be specified multiple times.")
+		c.Flags().StringVar(&backendConfigFile, "backend-config-file", "", "Path to a JSON or HCL file of backend variables (key = value). --backend-config values take precedence")
+		c.Flags().StringVar(&vaultAddr, "vault-addr", "", "Address of the Vault server to read backend variables from when TF_BACKEND_<TYPE>_VAULT_PATH is set. Falls back to VAULT_ADDR")
+		c.Flags().StringVar(&vaultToken, "vault-token", "", "Token for --vault-addr. Falls back to VAULT_TOKEN")
+	}
+}
+
+func runStateList(cmd *cobra.Command, args []string) error {
+	var filter *regexp.Regexp
+	if stateFilter != "" {
+		var err error
+		filter, err = regexp.Compile(stateFilter)
+		if err != nil {
+			return fmt.Errorf("❌ Invalid --filter pattern: %v", err)
+		}
+	}
+
+	tf, paths, err := openStateWorkspace()
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+
+	addresses, err := listStateAddresses(tf, paths.TFWorkDir)
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+	for _, address := range addresses {
+		if filter != nil && !filter.MatchString(address) {
+			continue
+		}
+		fmt.Println(address)
+	}
+	return nil
+}
+
+func runStateShow(cmd *cobra.Command, args []string) error {
+	address := args[0]
+	tf, paths, err := openStateWorkspace()
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+
+	if !stateShowJSON {
+		out, err := runTerraformState(tf, paths.TFWorkDir, "show", address)
+		if err != nil {
+			return fmt.Errorf("❌ %v", err)
+		}
+		fmt.Print(out)
+		return nil
+	}
+
+	state, err := tf.Show(context.Background())
+	if err != nil {
+		return fmt.Errorf("❌ Terraform show failed: %v", err)
+	}
+	if state.Values == nil {
+		return fmt.Errorf("❌ State is empty")
+	}
+	resource := findStateResource(state.Values.RootModule, address)
+	if resource == nil {
+		return fmt.Errorf("❌ Address '%s' not found in state. Run 'fctl state list %s' to see managed resources", address, stateSelectorFlags(paths))
+	}
+	return printJSON(resource)
+}
+
+func runStateRm(cmd *cobra.Command, args []string) error {
+	address := args[0]
+	tf, paths, err := openStateWorkspace()
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+
+	addresses, err := listStateAddresses(tf, paths.TFWorkDir)
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+	if !slices.Contains(addresses, address) {
+		return fmt.Errorf("❌ Address '%s' not found in state. Run 'fctl state list %s' to see managed resources", address, stateSelectorFlags(paths))
+	}
+
+	if stateDryRun {
+		output.Infof("🔍 Would remove %s from state (dry run, nothing changed)\n", address)
+		return nil
+	}
+
+	backupPath, err := backupState(tf, paths.DeployDir, "rm")
+	if err != nil {
+		return fmt.Errorf("❌ Failed to back up state: %v", err)
+	}
+	output.Infof("💾 State backed up to: %s\n", backupPath)
+
+	if err := tf.StateRm(context.Background(), address); err != nil {
+		return fmt.Errorf("❌ Terraform state rm failed: %v", err)
+	}
+	output.Infof("✅ Removed %s from state\n", address)
+	return nil
+}
+
+func runStateMv(cmd *cobra.Command, args []string) error {
+	source, destination := args[0], args[1]
+	tf, paths, err := openStateWorkspace()
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+
+	if lockPath, locked := findStateLock(paths); locked {
+		return fmt.Errorf("❌ State is locked (%s). Wait for the running operation to finish before moving resources", lockPath)
+	}
+
+	// Validate the addresses first so that a typo does not leave a pointless backup behind
+	addresses, err := listStateAddresses(tf, paths.TFWorkDir)
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+	if err := checkStateMv(addresses, source, destination, stateSelectorFlags(paths)); err != nil {
+		return err
+	}
+
+	backupPath, err := backupState(tf, paths.DeployDir, "mv")
+	if err != nil {
+		return fmt.Errorf("❌ Failed to back up state: %v", err)
+	}
+	output.Infof("💾 State backed up to: %s\n", backupPath)
+
+	if err := tf.StateMv(context.Background(), source, destination); err != nil {
+		return fmt.Errorf("❌ Terraform state mv failed: %v", err)
+	}
+	output.Infof("✅ Moved %s to %s\n", source, destination)
+	return nil
+}
+
+func runStatePull(cmd *cobra.Command, args []string) error {
+	tf, paths, err := openStateWorkspace()
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+	if err := configureStateBackend(tf, paths); err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+
+	state, err := tf.StatePull(context.Background())
+	if err != nil {
+		return fmt.Errorf("❌ Terraform state pull failed: %v", err)
+	}
+
+	if stateOutPath == "" {
+		fmt.Print(state)
+		return nil
+	}
+	if err := os.WriteFile(stateOutPath, []byte(state), 0600); err != nil {
+		return fmt.Errorf("❌ Failed to write state to %s: %v", stateOutPath, err)
+	}
+	fmt.Fprintf(output.StatusWriter(), "✅ State written to: %s\n", stateOutPath)
+	return nil
+}
+
+func runStatePush(cmd *cobra.Command, args []string) error {
+	file := statePushFile
+	if len(args) == 1 {
+		if file != "" {
+			return fmt.Errorf("❌ Give the state file either as an argument or with --file, not both")
+		}
+		file = args[0]
+	}
+	if file == "" {
+		return fmt.Errorf("❌ No state file given. Usage: fctl state push <file> or --file <file>")
+	}
+	if !statePushForce && NonInteractiveFlag {
+		return fmt.Errorf("❌ Refusing to replace the state without confirmation in non-interactive mode. Pass --force to push it")
+	}
+	statePath, err := filepath.Abs(file)
+	if err != nil {
+		return fmt.Errorf("❌ Could not resolve %s: %v", file, err)
+	}
+	incoming, err := validateStateFile(statePath)
+	if err != nil {
+		return fmt.Errorf("❌ Invalid state file: %v", err)
+	}
+
+	tf, paths, err := openStateWorkspace()
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+	if err := configureStateBackend(tf, paths); err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+
+	current, err := tf.StatePull(context.Background())
+	if err != nil {
+		return fmt.Errorf("❌ Could not read the current state: %v", err)
+	}
+	if err := checkStateOrder(current, incoming); err != nil {
+		if !statePushForce {
+			return fmt.Errorf("❌ Refusing to push %s: %v. Re-run with --force to push it anyway", file, err)
+		}
+		output.Warnf("⚠️ Pushing anyway: %v\n", err)
+	}
+
+	if !statePushForce {
+		ok, err := utils.Confirm(fmt.Sprintf("⚠️ Replace the state of environment %s with %s?", paths.EnvID, file))
+		if err != nil {
+			return fmt.Errorf("❌ User input error: %v", err)
+		}
+		if !ok {
+			output.Infoln("ℹ️ State push cancelled")
+			return nil
+		}
+	}
+
+	backupPath, err := writeStateBackup(paths.DeployDir, "push", current)
+	if err != nil {
+		return fmt.Errorf("❌ Failed to back up state: %v", err)
+	}
+	output.Infof("💾 State backed up to: %s\n", backupPath)
+
+	if err := tf.StatePush(context.Background(), statePath, tfexec.Force(statePushForce)); err != nil {
+		return fmt.Errorf("❌ Terraform state push failed: %v", err)
+	}
+	output.Infof("✅ Pushed %s to state\n", file)
+	return nil
+}
+
+// stateMeta holds the fields of a state file that identify it and order its snapshots.
+type stateMeta struct {
+	Version *int   `json:"version"`
+	Lineage string `json:"lineage"`
+	Serial  int64  `json:"serial"`
+}
+
+// validateStateFile checks that path holds a JSON object with a version field and returns its metadata.
+func validateStateFile(path string) (*stateMeta, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var meta stateMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("%s is not valid JSON: %v", path, err)
+	}
+	if meta.Version == nil {
+		return nil, fmt.Errorf("%s has no \"version\" field", path)
+	}
+	return &meta, nil
+}
+
+// checkStateOrder reports an error if pushing incoming over the current state would replace it
+// with a different state (lineage mismatch) or an older snapshot of it (lower serial).
+func checkStateOrder(current string, incoming *stateMeta) error {
+	if strings.TrimSpace(current) == "" {
+		return nil
+	}
+	var meta stateMeta
+	if err := json.Unmarshal([]byte(current), &meta); err != nil {
+		return fmt.Errorf("could not parse the current state: %v", err)
+	}
+	if meta.Lineage != "" && incoming.Lineage != meta.Lineage {
+		return fmt.Errorf("its lineage %q does not match the current state's lineage %q", incoming.Lineage, meta.Lineage)
+	}
+	if incoming.Serial < meta.Serial {
+		return fmt.Errorf("its serial %d is older than the current state's serial %d", incoming.Serial, meta.Serial)
+	}
+	return nil
+}
+
+// configureStateBackend points the deployment at the backend given by --backend, when set,
+// and selects the environment's workspace in it, creating the workspace if needed.
+func configureStateBackend(tf *tfexec.Terraform, paths *deploymentPaths) error {
+	backendConfig, err := newBackendConfigFromFlags()
+	if err != nil {
+		return fmt.Errorf("failed to initialize backend configuration: %v", err)
+	}
+	if backendConfig == nil {
+		return nil
+	}
+	if err := backendConfig.Validate(); err != nil {
+		return fmt.Errorf("invalid backend configuration: %v", err)
+	}
+	fmt.Fprintf(output.StatusWriter(), "🔄 Writing backend.tf.json for %s backend...\n", backendConfig.Type)
+	if err := backendConfig.WriteBackendTFJSON(paths.TFWorkDir); err != nil {
+		return fmt.Errorf("failed to write backend.tf.json: %v", err)
+	}
+	if err := tf.Init(context.Background(), tfexec.Reconfigure(true)); err != nil {
+		return fmt.Errorf("terraform init failed: %v", err)
+	}
+	if err := tf.WorkspaceSelect(context.Background(), paths.EnvID); err != nil {
+		if err := tf.WorkspaceNew(context.Background(), paths.EnvID); err != nil {
+			return fmt.Errorf("failed to create workspace %s: %v", paths.EnvID, err)
+		}
+	}
+	return nil
+}
+
+// checkStateMv returns an error unless source is a resource or module in addresses and destination
+// is neither. selectorFlags are the flags that select the deployment, for the 'fctl state list' hint.
+func checkStateMv(addresses []string, source, destination, selectorFlags string) error {
+	if !slices.Contains(addresses, source) && !hasModulePrefix(addresses, source) {
+		return fmt.Errorf("❌ Source address '%s' not found in state. Run 'fctl state list %s' to see managed resources", source, selectorFlags)
+	}
+	if source == destination {
+		return fmt.Errorf("❌ Source and destination are both '%s'", source)
+	}
+	if slices.Contains(addresses, destination) || hasModulePrefix(addresses, destination) {
+		return fmt.Errorf("❌ Destination address '%s' already exists in state", destination)
+	}
+	return nil
+}
+
+// hasModulePrefix reports whether address is a module containing at least one of the resources in addresses.
+func hasModulePrefix(addresses []string, address string) bool {
+	for _, a := range addresses {
+		if strings.HasPrefix(a, address+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// findStateLock returns the path of the local backend's lock file for the deployment's workspace, if one exists.
+func findStateLock(paths *deploymentPaths) (string, bool) {
+	candidates := []string{
+		filepath.Join(paths.TFWorkDir, "terraform.tfstate.d", paths.EnvID, ".terraform.tfstate.lock.info"),
+		filepath.Join(paths.TFWorkDir, ".terraform.tfstate.lock.info"),
+	}
+	for _, lockPath := range candidates {
+		if _, err := os.Stat(lockPath); err == nil {
+			return lockPath, true
+		}
+	}
+	return "", false
+}
+
+// openStateWorkspace opens the deployment selected by --zip, or by --environment-id and --deployment.
+func openStateWorkspace() (*tfexec.Terraform, *deploymentPaths, error) {
+	if stateZipPath != "" {
+		return openDeploymentWorkspace(stateZipPath)
+	}
+	paths, err := resolveLocalDeploymentPaths(stateEnvID, stateDeploymentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := os.Stat(paths.TFWorkDir); os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("no local deployment found at %s", paths.TFWorkDir)
+	}
+	tf, err := openWorkspace(paths)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tf, paths, nil
+}
+
+// stateSelectorFlags returns the flags that select the same deployment, for hints in error messages.
+func stateSelectorFlags(paths *deploymentPaths) string {
+	if stateZipPath != "" {
+		return "--zip " + stateZipPath
+	}
+	return fmt.Sprintf("--environment-id %s --deployment %s", paths.EnvID, paths.DeploymentID)
+}
+
+// listStateAddresses returns the resource addresses in the selected workspace's state.
+func listStateAddresses(tf *tfexec.Terraform, workDir string) ([]string, error) {
+	out, err := runTerraformState(tf, workDir, "list")
+	if err != nil {
+		return nil, err
+	}
+
+	var addresses []string
+	for _, address := range strings.Split(out, "\n") {
+		if address != "" {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses, nil
+}
+
+// runTerraformState runs 'terraform state <args>' in workDir and returns its stdout.
+// terraform-exec has no wrappers for 'state list' and 'state show', so they are run directly.
+func runTerraformState(tf *tfexec.Terraform, workDir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	terraformCmd := exec.Command(tf.ExecPath(), append([]string{"state"}, args...)...)
+	terraformCmd.Dir = workDir
+	terraformCmd.Env = append(os.Environ(), "TF_IN_AUTOMATION=1")
+	terraformCmd.Stdout = &stdout
+	terraformCmd.Stderr = &stderr
+	if err := terraformCmd.Run(); err != nil {
+		return "", fmt.Errorf("terraform state %s failed: %v\n%s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// findStateResource looks up a resource by address in module and its child modules.
+func findStateResource(module *tfjson.StateModule, address string) *tfjson.StateResource {
+	if module == nil {
+		return nil
+	}
+	for _, resource := range module.Resources {
+		if resource.Address == address {
+			return resource
+		}
+	}
+	for _, child := range module.ChildModules {
+		if resource := findStateResource(child, address); resource != nil {
+			return resource
+		}
+	}
+	return nil
+}
+
+// backupState writes the current state to <deployDir>/state-backups/<timestamp>-<reason>.tfstate
+// and returns the backup path.
+func backupState(tf *tfexec.Terraform, deployDir, reason string) (string, error) {
+	state, err := tf.StatePull(context.Background())
+	if err != nil {
+		return "", fmt.Errorf("could not read state: %v", err)
+	}
+	return writeStateBackup(deployDir, reason, state)
+}
+
+// writeStateBackup writes an already pulled state to <deployDir>/state-backups/<timestamp>-<reason>.tfstate,
+// adding a counter before the reason if a backup with that name already exists.
+func writeStateBackup(deployDir, reason, state string) (string, error) {
+	backupDir := filepath.Join(deployDir, "state-backups")
+	if err := os.MkdirAll(backupDir, 0755); err != nil {
+		return "", fmt.Errorf("could not create %s: %v", backupDir, err)
+	}
+	// Millisecond timestamps keep backups in order; the counter keeps two in the same millisecond apart
+	stamp := time.Now().Format("20060102-150405.000")
+	for i := 1; ; i++ {
+		name := fmt.Sprintf("%s-%s.tfstate", stamp, reason)
+		if i > 1 {
+			name = fmt.Sprintf("%s-%d-%s.tfstate", stamp, i, reason)
+		}
+		backupPath := filepath.Join(backupDir, name)
+		f, err := os.OpenFile(backupPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("could not write %s: %v", backupPath, err)
+		}
+		if _, err := f.WriteString(state); err != nil {
+			f.Close()
+			return "", fmt.Errorf("could not write %s: %v", backupPath, err)
+		}
+		if err := f.Close(); err != nil {
+			return "", fmt.Errorf("could not write %s: %v", backupPath, err)
+		}
+		return backupPath, nil
+	}
+}
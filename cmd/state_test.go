@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckStateMv(t *testing.T) {
+	addresses := []string{
+		"aws_s3_bucket.logs",
+		"module.cache.aws_elasticache_cluster.this",
+		"module.cache.aws_security_group.this",
+	}
+	tests := []struct {
+		name        string
+		source      string
+		destination string
+		wantErr     string
+	}{
+		{name: "resource", source: "aws_s3_bucket.logs", destination: "aws_s3_bucket.audit_logs"},
+		{name: "module", source: "module.cache", destination: "module.redis"},
+		{name: "missing source", source: "aws_s3_bucket.missing", destination: "aws_s3_bucket.other", wantErr: "Source address 'aws_s3_bucket.missing' not found in state. Run 'fctl state list --zip export.zip'"},
+		{name: "partial module name is not a module", source: "module.cac", destination: "module.redis", wantErr: "not found in state"},
+		{name: "same address", source: "aws_s3_bucket.logs", destination: "aws_s3_bucket.logs", wantErr: "Source and destination are both"},
+		{name: "existing destination", source: "aws_s3_bucket.logs", destination: "module.cache.aws_security_group.this", wantErr: "Destination address 'module.cache.aws_security_group.this' already exists"},
+		{name: "existing destination module", source: "aws_s3_bucket.logs", destination: "module.cache", wantErr: "Destination address 'module.cache' already exists"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkStateMv(addresses, tt.source, tt.destination, "--zip export.zip")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkStateMv() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkStateMv() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckStateOrder(t *testing.T) {
+	current := `{"version": 4, "lineage": "abc", "serial": 5}`
+	tests := []struct {
+		name     string
+		current  string
+		incoming stateMeta
+		wantErr  string
+	}{
+		{name: "no current state", current: "", incoming: stateMeta{Lineage: "other", Serial: 1}},
+		{name: "newer serial", current: current, incoming: stateMeta{Lineage: "abc", Serial: 6}},
+		{name: "same serial", current: current, incoming: stateMeta{Lineage: "abc", Serial: 5}},
+		{name: "current state without lineage", current: `{"version": 4, "serial": 1}`, incoming: stateMeta{Lineage: "abc", Serial: 1}},
+		{name: "older serial", current: current, incoming: stateMeta{Lineage: "abc", Serial: 4}, wantErr: "its serial 4 is older than the current state's serial 5"},
+		{name: "different lineage", current: current, incoming: stateMeta{Lineage: "xyz", Serial: 9}, wantErr: `its lineage "xyz" does not match the current state's lineage "abc"`},
+		{name: "unparseable current state", current: "{", incoming: stateMeta{Serial: 1}, wantErr: "could not parse the current state"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkStateOrder(tt.current, &tt.incoming)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkStateOrder() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkStateOrder() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateStateFile(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantSerial int64
+		wantErr    bool
+	}{
+		{name: "valid", content: `{"version": 4, "lineage": "abc", "serial": 7}`, wantSerial: 7},
+		{name: "no version", content: `{"lineage": "abc", "serial": 7}`, wantErr: true},
+		{name: "not json", content: "terraform state", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "terraform.tfstate")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			meta, err := validateStateFile(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateStateFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && meta.Serial != tt.wantSerial {
+				t.Errorf("Serial = %d, want %d", meta.Serial, tt.wantSerial)
+			}
+		})
+	}
+}
+
+func TestRunStatePushNonInteractive(t *testing.T) {
+	setFlag(t, &NonInteractiveFlag, true)
+	setFlag(t, &statePushForce, false)
+	setFlag(t, &statePushFile, "")
+	err := runStatePush(statePushCmd, []string{"my-env.tfstate"})
+	if err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("runStatePush() error = %v, want a refusal pointing to --force", err)
+	}
+}
+
+func TestWriteStateBackup(t *testing.T) {
+	deployDir := t.TempDir()
+	states := []string{`{"version": 4, "serial": 1}`, `{"version": 4, "serial": 2}`}
+	var paths []string
+	for _, state := range states {
+		path, err := writeStateBackup(deployDir, "push", state)
+		if err != nil {
+			t.Fatalf("writeStateBackup() error = %v", err)
+		}
+		paths = append(paths, path)
+	}
+	if paths[0] == paths[1] {
+		t.Fatalf("two backups were written to the same file %s", paths[0])
+	}
+	for i, path := range paths {
+		if dir := filepath.Dir(path); dir != filepath.Join(deployDir, "state-backups") {
+			t.Errorf("backup written to %s, want it in %s/state-backups", dir, deployDir)
+		}
+		if !strings.HasSuffix(path, "-push.tfstate") {
+			t.Errorf("backup name %s does not end in -push.tfstate", filepath.Base(path))
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != states[i] {
+			t.Errorf("backup %s = %s, want %s", path, data, states[i])
+		}
+	}
+}
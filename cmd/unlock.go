@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Facets-cloud/fctl/pkg/output"
+	"github.com/Facets-cloud/fctl/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	unlockZipPath      string
+	unlockEnvID        string
+	unlockDeploymentID string
+	unlockLockID       string
+	unlockYes          bool
+)
+
+var unlockCmd = &cobra.Command{
+	Use:   "unlock",
+	Short: "Remove a stuck lock from the state of an applied export.",
+	Long: `Remove a state lock that was left behind, for example by an apply that was killed, like 'terraform force-unlock'. The deployment is located from the exported zip in the same way as apply, or with --environment-id and an optional --deployment. With --backend, the deployment is configured for that backend first, so a lock held in S3/DynamoDB, GCS, and the others can be removed.
+
+The lock ID is printed in the error of the command that failed to acquire the lock. For the local backend the lock being removed is printed before you are asked to confirm; pass --yes to skip the confirmation.
+
+Only unlock when you are sure no other Terraform run is using the state.`,
+	// Unlocking works on the local workspace and the backend only
+	Annotations: map[string]string{skipAuthAnnotation: "true"},
+	RunE:        runUnlock,
+}
+
+func init() {
+	rootCmd.AddCommand(unlockCmd)
+
+	unlockCmd.Flags().StringVarP(&unlockZipPath, "zip", "z", "", "Path to the exported zip file")
+	unlockCmd.Flags().StringVarP(&unlockEnvID, "environment-id", "e", "", "Environment whose local deployment to use instead of --zip")
+	unlockCmd.Flags().StringVar(&unlockDeploymentID, "deployment", "", "Deployment ID to use with --environment-id (default: the newest local deployment)")
+	unlockCmd.Flags().StringVar(&unlockLockID, "lock-id", "", "ID of the lock to remove (required)")
+	unlockCmd.Flags().BoolVarP(&unlockYes, "yes", "y", false, "Remove the lock without asking for confirmation")
+	unlockCmd.Flags().StringVar(&backendType, "backend", "", "Terraform backend type for state (s3, gcs, azurerm, consul). Falls back to TF_BACKEND_TYPE")
+	unlockCmd.Flags().StringArrayVar(&backendConfigPairs, "backend-config", nil, "Backend variable in the form key=value, overriding TF_BACKEND_<TYPE>_<KEY>. Can be specified multiple times.")
+	unlockCmd.Flags().StringVar(&backendConfigFile, "backend-config-file", "", "Path to a JSON or HCL file of backend variables (key = value). --backend-config values take precedence")
+	unlockCmd.Flags().StringVar(&vaultAddr, "vault-addr", "", "Address of the Vault server to read backend variables from when TF_BACKEND_<TYPE>_VAULT_PATH is set. Falls back to VAULT_ADDR")
+	unlockCmd.Flags().StringVar(&vaultToken, "vault-token", "", "Token for --vault-addr. Falls back to VAULT_TOKEN")
+
+	unlockCmd.MarkFlagRequired("lock-id")
+	unlockCmd.MarkFlagsOneRequired("zip", "environment-id")
+	unlockCmd.MarkFlagsMutuallyExclusive("zip", "environment-id")
+	unlockCmd.MarkFlagsMutuallyExclusive("zip", "deployment")
+	unlockCmd.RegisterFlagCompletionFunc("environment-id", completeLocalEnvironmentIDs)
+	unlockCmd.RegisterFlagCompletionFunc("deployment", completeLocalDeploymentIDs)
+}
+
+// stateLockInfo is the lock information Terraform records while it holds a state lock.
+type stateLockInfo struct {
+	ID        string `json:"ID"`
+	Operation string `json:"Operation"`
+	Who       string `json:"Who"`
+	Version   string `json:"Version"`
+	Created   string `json:"Created"`
+	Path      string `json:"Path"`
+}
+
+func runUnlock(cmd *cobra.Command, args []string) error {
+	if !unlockYes && NonInteractiveFlag {
+		return fmt.Errorf("❌ Refusing to remove a lock without confirmation in non-interactive mode. Pass --yes to remove it")
+	}
+
+	var paths *deploymentPaths
+	var err error
+	if unlockZipPath != "" {
+		paths, err = resolveDeploymentPaths(unlockZipPath)
+	} else {
+		paths, err = resolveLocalDeploymentPaths(unlockEnvID, unlockDeploymentID)
+	}
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+	if _, err := os.Stat(paths.TFWorkDir); os.IsNotExist(err) {
+		return fmt.Errorf("❌ No local deployment found at %s. Run 'fctl apply' first", paths.TFWorkDir)
+	}
+	tf, err := openWorkspace(paths)
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+	if err := configureStateBackend(tf, paths); err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+
+	output.Infof("🔒 Lock ID:     %s\n", unlockLockID)
+	output.Infof("   Environment: %s\n", paths.EnvID)
+	output.Infof("   Deployment:  %s\n", paths.DeploymentID)
+	backendFile := filepath.Join(paths.TFWorkDir, "backend.tf.json")
+	if _, err := os.Stat(backendFile); os.IsNotExist(err) {
+		// The local backend keeps the lock info next to the state, so it can be shown and checked
+		lockPath, locked := findStateLock(paths)
+		if !locked {
+			return fmt.Errorf("❌ State is not locked: no lock found in %s", paths.TFWorkDir)
+		}
+		info, err := readStateLockInfo(lockPath)
+		if err != nil {
+			return fmt.Errorf("❌ Failed to read lock info from %s: %v", lockPath, err)
+		}
+		if info.ID != unlockLockID {
+			return fmt.Errorf("❌ The state is locked with ID %s, not %s", info.ID, unlockLockID)
+		}
+		output.Infof("   Operation:   %s\n", info.Operation)
+		output.Infof("   Who:         %s\n", info.Who)
+		output.Infof("   Created:     %s\n", info.Created)
+		output.Infof("   Terraform:   %s\n", info.Version)
+	} else {
+		output.Infof("   Backend:     %s\n", backendFile)
+	}
+
+	if !unlockYes {
+		ok, err := utils.Confirm("⚠️ Remove this lock? Only do so if no other Terraform run is using the state")
+		if err != nil {
+			return fmt.Errorf("❌ User input error: %v", err)
+		}
+		if !ok {
+			output.Infoln("ℹ️ Unlock cancelled")
+			return nil
+		}
+	}
+
+	if err := tf.ForceUnlock(context.Background(), unlockLockID); err != nil {
+		return fmt.Errorf("❌ Failed to remove lock %s: %v. Check that the state is locked and that the lock ID is correct", unlockLockID, err)
+	}
+	output.Infof("✅ Removed lock %s\n", unlockLockID)
+	return nil
+}
+
+// readStateLockInfo reads the lock info file written by the local backend.
+func readStateLockInfo(path string) (*stateLockInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var info stateLockInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/Facets-cloud/fctl/pkg/output"
+	"github.com/Facets-cloud/fctl/pkg/utils"
+	"github.com/hashicorp/terraform-exec/tfexec"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/spf13/cobra"
+)
+
+var (
+	validateZipPath string
+	validateJSON    bool
+)
+
+var validateCmd = &cobra.Command{
+	Use:         "validate",
+	Short:       "Check an exported zip for Terraform configuration errors.",
+	Long:        `Extract an exported zip to a temporary directory and run 'terraform validate' on it, so configuration errors are caught before plan or apply. Each diagnostic is listed with its file, line, and message, and the command exits with 1 if the configuration is invalid. Does not require a login.`,
+	Annotations: map[string]string{skipAuthAnnotation: "true"},
+	RunE:        runValidate,
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	validateCmd.Flags().StringVarP(&validateZipPath, "zip", "z", "", "Path to the exported zip file (required)")
+	validateCmd.Flags().BoolVar(&validateJSON, "json", false, "Print the result of 'terraform validate -json'")
+
+	validateCmd.MarkFlagRequired("zip")
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	if err := utils.VerifyZip(validateZipPath); err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+
+	tempDir, err := os.MkdirTemp("", "fctl-validate-*")
+	if err != nil {
+		return fmt.Errorf("❌ Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	if err := utils.ExtractZip(validateZipPath, tempDir); err != nil {
+		return fmt.Errorf("❌ Failed to extract zip: %v", err)
+	}
+	tfWorkDir := filepath.Join(tempDir, "tfexport")
+	if err := utils.FixPermissions(tfWorkDir); err != nil {
+		return fmt.Errorf("❌ Failed to fix permissions: %v", err)
+	}
+
+	tf, err := tfexec.NewTerraform(tfWorkDir, "terraform")
+	if err != nil {
+		return fmt.Errorf("❌ Failed to create terraform executor: %v", err)
+	}
+	tf.SetStdout(io.Discard)
+	tf.SetStderr(io.Discard)
+
+	// Providers and modules are needed to validate, but the backend is not
+	if !validateJSON {
+		output.Infoln("🔧 Initializing terraform (without backend)...")
+	}
+	if err := tf.Init(context.Background(), tfexec.Backend(false)); err != nil {
+		return fmt.Errorf("❌ Terraform init failed: %v", err)
+	}
+
+	result, err := tf.Validate(context.Background())
+	if err != nil {
+		return fmt.Errorf("❌ Terraform validate failed: %v", err)
+	}
+
+	if validateJSON {
+		if err := printJSON(result); err != nil {
+			return err
+		}
+	} else {
+		printDiagnostics(os.Stdout, result.Diagnostics)
+	}
+
+	if !result.Valid {
+		return fmt.Errorf("❌ Configuration is invalid: %d error(s), %d warning(s)", result.ErrorCount, result.WarningCount)
+	}
+	if !validateJSON {
+		output.Infof("✅ Configuration is valid (%d warning(s))\n", result.WarningCount)
+	}
+	return nil
+}
+
+// printDiagnostics writes each diagnostic as its severity icon, file and line, and summary, followed by
+// its detail on an indented line
+func printDiagnostics(w io.Writer, diags []tfjson.Diagnostic) {
+	for _, diag := range diags {
+		icon := "⚠️"
+		if diag.Severity == tfjson.DiagnosticSeverityError {
+			icon = "❌"
+		}
+		location := "-"
+		if diag.Range != nil {
+			location = fmt.Sprintf("%s:%d", diag.Range.Filename, diag.Range.Start.Line)
+		}
+		fmt.Fprintf(w, "%s %s: %s\n", icon, location, diag.Summary)
+		if diag.Detail != "" {
+			fmt.Fprintf(w, "   %s\n", diag.Detail)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// writeTestZip writes a zip of the given files, by path, and returns its path
+func writeTestZip(t *testing.T, files map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "export.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w := zip.NewWriter(f)
+	for name, content := range files {
+		fw, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPrintDiagnostics(t *testing.T) {
+	diags := []tfjson.Diagnostic{
+		{
+			Severity: tfjson.DiagnosticSeverityError,
+			Summary:  "Reference to undeclared input variable",
+			Detail:   `An input variable with the name "missing" has not been declared.`,
+			Range:    &tfjson.Range{Filename: "main.tf", Start: tfjson.Pos{Line: 2}},
+		},
+		{Severity: tfjson.DiagnosticSeverityWarning, Summary: "Deprecated attribute"},
+	}
+	var buf bytes.Buffer
+	printDiagnostics(&buf, diags)
+	want := "❌ main.tf:2: Reference to undeclared input variable\n" +
+		"   An input variable with the name \"missing\" has not been declared.\n" +
+		"⚠️ -: Deprecated attribute\n"
+	if buf.String() != want {
+		t.Errorf("printDiagnostics() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestRunValidateRejectsCorruptZip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.zip")
+	if err := os.WriteFile(path, []byte("not a zip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	setFlag(t, &validateZipPath, path)
+	if err := runValidate(validateCmd, nil); err == nil {
+		t.Error("runValidate() of a corrupt zip succeeded")
+	}
+}
+
+// TestRunValidate validates configurations that need no providers, so Terraform runs offline
+func TestRunValidate(t *testing.T) {
+	if _, err := exec.LookPath("terraform"); err != nil {
+		t.Skip("terraform is not installed")
+	}
+	tests := []struct {
+		name    string
+		mainTF  string
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			mainTF: "variable \"name\" {\n  type = string\n}\n\noutput \"name\" {\n  value = var.name\n}\n",
+		},
+		{
+			name:    "invalid",
+			mainTF:  "output \"name\" {\n  value = var.missing\n}\n",
+			wantErr: "Configuration is invalid: 1 error(s), 0 warning(s)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setFlag(t, &validateZipPath, writeTestZip(t, map[string]string{"tfexport/main.tf": tt.mainTF}))
+			setFlag(t, &validateJSON, false)
+			err := runValidate(validateCmd, nil)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("runValidate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("runValidate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
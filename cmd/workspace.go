@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Facets-cloud/fctl/pkg/output"
+	"github.com/hashicorp/terraform-exec/tfexec"
+	"github.com/spf13/cobra"
+)
+
+var (
+	workspaceZipPath         string
+	workspaceJSON            bool
+	workspaceName            string
+	workspaceCreateIfMissing bool
+)
+
+var workspaceCmd = &cobra.Command{
+	Use:   "workspace",
+	Short: "Manage the Terraform workspaces of an applied export.",
+	Long:  `Manage the Terraform workspaces of a deployment that was applied with 'fctl apply'. The deployment directory is located from the exported zip in the same way as apply (~/.facets/<environment-id>/<deployment-id>/tfexport). apply, plan, and destroy use a workspace named after the environment ID.`,
+	// Workspaces are local to the deployment directory
+	Annotations: map[string]string{skipAuthAnnotation: "true"},
+}
+
+var workspaceListCmd = &cobra.Command{
+	Use:         "list",
+	Short:       "List the Terraform workspaces of an applied export.",
+	Long:        `List the Terraform workspaces of an applied export, like 'terraform workspace list'. The selected workspace is marked with an asterisk.`,
+	Annotations: map[string]string{noBannerAnnotation: "true"},
+	RunE:        runWorkspaceList,
+}
+
+var workspaceSelectCmd = &cobra.Command{
+	Use:   "select",
+	Short: "Select the Terraform workspace of an applied export.",
+	Long: `Select a Terraform workspace of an applied export, like 'terraform workspace select', for example before running terraform directly in the deployment directory to inspect another workspace. Pass --create-if-missing to create the workspace when it does not exist.
+
+The fctl commands that run Terraform, such as apply, plan, output, and exec, select the environment's workspace again when they run.`,
+	RunE: runWorkspaceSelect,
+}
+
+// workspaceList is the --json output of 'workspace list'.
+type workspaceList struct {
+	Workspaces []string `json:"workspaces"`
+	Current    string   `json:"current"`
+}
+
+func init() {
+	rootCmd.AddCommand(workspaceCmd)
+	workspaceCmd.AddCommand(workspaceListCmd)
+	workspaceCmd.AddCommand(workspaceSelectCmd)
+
+	workspaceCmd.PersistentFlags().StringVarP(&workspaceZipPath, "zip", "z", "", "Path to the exported zip file (required)")
+	workspaceListCmd.Flags().BoolVar(&workspaceJSON, "json", false, "Print the workspaces as JSON")
+
+	workspaceSelectCmd.Flags().StringVarP(&workspaceName, "workspace", "w", "", "Name of the workspace to select (required)")
+	workspaceSelectCmd.Flags().BoolVar(&workspaceCreateIfMissing, "create-if-missing", false, "Create the workspace if it does not exist")
+
+	workspaceCmd.MarkPersistentFlagRequired("zip")
+	workspaceSelectCmd.MarkFlagRequired("workspace")
+}
+
+// openDeploymentTerraform returns a Terraform executor for the deployment extracted from zipPath,
+// leaving the selected workspace as it is.
+func openDeploymentTerraform(zipPath string) (*tfexec.Terraform, *deploymentPaths, error) {
+	paths, err := resolveDeploymentPaths(zipPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := os.Stat(paths.TFWorkDir); os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("no local deployment found at %s; run 'fctl apply --zip %s' first", paths.TFWorkDir, zipPath)
+	}
+	tf, err := tfexec.NewTerraform(paths.TFWorkDir, "terraform")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create terraform executor: %v", err)
+	}
+	return tf, paths, nil
+}
+
+func runWorkspaceList(cmd *cobra.Command, args []string) error {
+	tf, _, err := openDeploymentTerraform(workspaceZipPath)
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+	workspaces, current, err := tf.WorkspaceList(context.Background())
+	if err != nil {
+		return fmt.Errorf("❌ Terraform workspace list failed: %v", err)
+	}
+
+	if workspaceJSON {
+		return printJSON(workspaceList{Workspaces: workspaces, Current: current})
+	}
+	if len(workspaces) == 0 {
+		output.Infoln("ℹ️ No workspaces found.")
+		return nil
+	}
+	for _, name := range workspaces {
+		marker := " "
+		if name == current {
+			marker = "*"
+		}
+		fmt.Printf("%s %s\n", marker, name)
+	}
+	return nil
+}
+
+func runWorkspaceSelect(cmd *cobra.Command, args []string) error {
+	tf, _, err := openDeploymentTerraform(workspaceZipPath)
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+	ctx := context.Background()
+	workspaces, current, err := tf.WorkspaceList(ctx)
+	if err != nil {
+		return fmt.Errorf("❌ Terraform workspace list failed: %v", err)
+	}
+	if workspaceName == current {
+		output.Successf("✅ Workspace %s is already selected\n", workspaceName)
+		return nil
+	}
+
+	if !slices.Contains(workspaces, workspaceName) {
+		if !workspaceCreateIfMissing {
+			return fmt.Errorf("❌ Workspace %s does not exist (available: %s). Pass --create-if-missing to create it", workspaceName, strings.Join(workspaces, ", "))
+		}
+		// Creating a workspace also selects it
+		if err := tf.WorkspaceNew(ctx, workspaceName); err != nil {
+			return fmt.Errorf("❌ Failed to create workspace %s: %v", workspaceName, err)
+		}
+		output.Successf("✅ Created and selected workspace %s\n", workspaceName)
+		return nil
+	}
+	if err := tf.WorkspaceSelect(ctx, workspaceName); err != nil {
+		return fmt.Errorf("❌ Failed to select workspace %s: %v", workspaceName, err)
+	}
+	output.Successf("✅ Selected workspace %s\n", workspaceName)
+	return nil
+}
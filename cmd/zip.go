@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Facets-cloud/fctl/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	zipListPath         string
+	zipListFilter       string
+	zipListTree         bool
+	zipListOutputFormat string
+	zipListJSON         bool
+)
+
+var zipCmd = &cobra.Command{
+	Use:   "zip",
+	Short: "Inspect exported zip files.",
+	Long:  `Inspect the exported zip files that apply, plan, and destroy take, without extracting them.`,
+	// Zips are local files
+	Annotations: map[string]string{skipAuthAnnotation: "true"},
+}
+
+var zipListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the files in an exported zip.",
+	Long: `List the files in an exported zip with their uncompressed size, compressed size, and modification time, without extracting it. Use --filter to only list files matching a glob pattern, such as '*.tf' or 'tfexport/modules/*', and --tree to print the files as a tree.
+
+Since the zip has to be readable to be listed, this is also a quick check of a zip before apply or plan.`,
+	RunE: runZipList,
+}
+
+// zipEntry is the per-file record printed by 'zip list'.
+type zipEntry struct {
+	Name             string `json:"name"`
+	Size             uint64 `json:"size"`
+	CompressedSize   uint64 `json:"compressed_size"`
+	ModificationTime string `json:"modification_time"`
+}
+
+func init() {
+	rootCmd.AddCommand(zipCmd)
+	zipCmd.AddCommand(zipListCmd)
+
+	zipListCmd.Flags().StringVarP(&zipListPath, "zip", "z", "", "Path to the exported zip file (required)")
+	zipListCmd.Flags().StringVar(&zipListFilter, "filter", "", "Only list files whose path or name matches this glob pattern (e.g. '*.tf')")
+	zipListCmd.Flags().BoolVar(&zipListTree, "tree", false, "Print the files as a tree")
+	zipListCmd.Flags().StringVarP(&zipListOutputFormat, "output", "o", "table", "Output format: table or json")
+	zipListCmd.Flags().BoolVar(&zipListJSON, "json", false, "Print the files as a JSON array (same as --output json)")
+
+	zipListCmd.MarkFlagRequired("zip")
+	zipListCmd.MarkFlagsMutuallyExclusive("tree", "json")
+	zipListCmd.MarkFlagsMutuallyExclusive("tree", "output")
+}
+
+func runZipList(cmd *cobra.Command, args []string) error {
+	if zipListJSON {
+		zipListOutputFormat = "json"
+	}
+	if err := validateOutputFormat(zipListOutputFormat); err != nil {
+		return err
+	}
+	if zipListFilter != "" {
+		if _, err := path.Match(zipListFilter, ""); err != nil {
+			return fmt.Errorf("❌ Invalid --filter pattern %q: %v", zipListFilter, err)
+		}
+	}
+
+	reader, err := zip.OpenReader(zipListPath)
+	if err != nil {
+		return fmt.Errorf("❌ Could not read %s: %v. The zip may be corrupted; please re-run the export", zipListPath, err)
+	}
+	defer reader.Close()
+
+	entries := []zipEntry{}
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() || !matchesZipFilter(file.Name, zipListFilter) {
+			continue
+		}
+		entries = append(entries, zipEntry{
+			Name:             file.Name,
+			Size:             file.UncompressedSize64,
+			CompressedSize:   file.CompressedSize64,
+			ModificationTime: file.Modified.Format(time.RFC3339),
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+
+	if zipListOutputFormat == "json" {
+		return printJSON(entries)
+	}
+	if len(entries) == 0 {
+		if zipListFilter != "" {
+			output.Infof("ℹ️ No files in %s match %s\n", zipListPath, zipListFilter)
+		} else {
+			output.Infof("ℹ️ %s is empty\n", zipListPath)
+		}
+		return nil
+	}
+	if zipListTree {
+		names := make([]string, len(entries))
+		for i, e := range entries {
+			names[i] = e.Name
+		}
+		printZipTree(names)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSIZE\tCOMPRESSED\tMODIFIED")
+	var total, totalCompressed uint64
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Name, formatBytes(e.Size), formatBytes(e.CompressedSize), e.ModificationTime)
+		total += e.Size
+		totalCompressed += e.CompressedSize
+	}
+	fmt.Fprintf(w, "%d file(s)\t%s\t%s\t\n", len(entries), formatBytes(total), formatBytes(totalCompressed))
+	return w.Flush()
+}
+
+// matchesZipFilter reports whether the zip entry name, its base name, or one of its parent directories
+// matches the glob pattern, so that 'tfexport/modules/*' lists everything under the modules. An empty
+// pattern matches everything.
+func matchesZipFilter(name, pattern string) bool {
+	if pattern == "" {
+		return true
+	}
+	if ok, _ := path.Match(pattern, path.Base(name)); ok {
+		return true
+	}
+	for dir := name; dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if ok, _ := path.Match(pattern, dir); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// zipTreeNode is a directory or file in the tree printed by 'zip list --tree'.
+type zipTreeNode struct {
+	children map[string]*zipTreeNode
+}
+
+// printZipTree prints the slash-separated names as a tree, like the tree command.
+func printZipTree(names []string) {
+	root := &zipTreeNode{children: map[string]*zipTreeNode{}}
+	for _, name := range names {
+		node := root
+		for _, part := range strings.Split(name, "/") {
+			child, ok := node.children[part]
+			if !ok {
+				child = &zipTreeNode{children: map[string]*zipTreeNode{}}
+				node.children[part] = child
+			}
+			node = child
+		}
+	}
+	fmt.Println(".")
+	printZipTreeNode(root, "")
+}
+
+func printZipTreeNode(node *zipTreeNode, prefix string) {
+	names := make([]string, 0, len(node.children))
+	for name := range node.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		connector, childPrefix := "├── ", "│   "
+		if i == len(names)-1 {
+			connector, childPrefix = "└── ", "    "
+		}
+		fmt.Printf("%s%s%s\n", prefix, connector, name)
+		printZipTreeNode(node.children[name], prefix+childPrefix)
+	}
+}
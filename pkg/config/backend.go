@@ -1,11 +1,17 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
 )
 
 // BackendConfig represents the configuration for a Terraform backend
@@ -33,11 +39,40 @@ var GCSBackendVars = []string{
 	"credentials",
 }
 
-// NewBackendConfig creates a new backend configuration
-func NewBackendConfig() (*BackendConfig, error) {
-	backendType := os.Getenv("TF_BACKEND_TYPE")
+// AzureRMBackendVars contains required variables for AzureRM backend
+var AzureRMBackendVars = []string{
+	"resource_group_name",
+	"storage_account_name",
+	"container_name",
+	"key",
+	"sas_token",       // optional
+	"client_id",       // optional
+	"client_secret",   // optional
+	"tenant_id",       // optional
+	"subscription_id", // optional
+}
+
+// ConsulBackendVars contains required variables for Consul backend
+var ConsulBackendVars = []string{
+	"address",
+	"path",
+	"token",      // optional
+	"scheme",     // optional
+	"datacenter", // optional
+}
+
+// NewBackendConfig creates a new backend configuration.
+// backendType and configVars come from the --backend and --backend-config flags; the
+// TF_BACKEND_TYPE and TF_BACKEND_<TYPE>_<VAR> environment variables are used as fallbacks.
+func NewBackendConfig(backendType string, configVars map[string]string) (*BackendConfig, error) {
+	if backendType == "" {
+		backendType = os.Getenv("TF_BACKEND_TYPE")
+	}
 	backendType = strings.ToLower(backendType)
 	if backendType == "" {
+		if len(configVars) > 0 {
+			return nil, fmt.Errorf("backend variables were given but no backend type is set (use --backend or TF_BACKEND_TYPE)")
+		}
 		return nil, nil // Local backend
 	}
 
@@ -46,25 +81,124 @@ func NewBackendConfig() (*BackendConfig, error) {
 		ConfigVars: make(map[string]string),
 	}
 
-	var requiredVars []string
+	requiredVars, err := backendVars(backendType)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load configuration from environment variables
+	for _, v := range requiredVars {
+		if val := os.Getenv(backendEnvVar(backendType, v)); val != "" {
+			config.ConfigVars[v] = val
+		}
+	}
+
+	// Explicit values override the environment
+	for k, v := range configVars {
+		if !contains(requiredVars, k) {
+			return nil, fmt.Errorf("unsupported variable %q for %s backend (supported: %s)", k, backendType, strings.Join(requiredVars, ", "))
+		}
+		config.ConfigVars[k] = v
+	}
+
+	return config, nil
+}
+
+// ParseBackendConfigPairs parses key=value pairs given via --backend-config
+func ParseBackendConfigPairs(pairs []string) (map[string]string, error) {
+	configVars := make(map[string]string)
+	for _, pair := range pairs {
+		k, v, found := strings.Cut(pair, "=")
+		k = strings.TrimSpace(k)
+		if !found || k == "" {
+			return nil, fmt.Errorf("invalid backend config %q (expected key=value)", pair)
+		}
+		configVars[k] = v
+	}
+	return configVars, nil
+}
+
+// LoadBackendConfigFile reads backend variables from a JSON file (.json) or an HCL file of
+// key = value attributes (any other extension, e.g. .hcl or .tfbackend)
+func LoadBackendConfigFile(path string) (map[string]string, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backend config file: %w", err)
+	}
+
+	configVars := make(map[string]string)
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		// Numbers are kept as written, so that e.g. 1000000 does not become 1e+06
+		var raw map[string]interface{}
+		decoder := json.NewDecoder(bytes.NewReader(src))
+		decoder.UseNumber()
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("failed to parse backend config file %s: %w", path, err)
+		}
+		if _, err := decoder.Token(); err != io.EOF {
+			return nil, fmt.Errorf("failed to parse backend config file %s: unexpected data after the JSON object", path)
+		}
+		for k, v := range raw {
+			switch v.(type) {
+			case string, json.Number, bool:
+				configVars[k] = fmt.Sprint(v)
+			default:
+				return nil, fmt.Errorf("backend config file %s: value of %q must be a string, number, or bool", path, k)
+			}
+		}
+		return configVars, nil
+	}
+
+	file, diags := hclparse.NewParser().ParseHCL(src, path)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse backend config file %s: %s", path, diags.Error())
+	}
+	attrs, diags := file.Body.JustAttributes()
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse backend config file %s: %s", path, diags.Error())
+	}
+	for name, attr := range attrs {
+		val, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("backend config file %s: %s", path, diags.Error())
+		}
+		strVal, err := convert.Convert(val, cty.String)
+		if err != nil || strVal.IsNull() {
+			return nil, fmt.Errorf("backend config file %s: value of %q must be a string, number, or bool", path, name)
+		}
+		configVars[name] = strVal.AsString()
+	}
+	return configVars, nil
+}
+
+// backendVars returns the variables understood by the given backend type
+func backendVars(backendType string) ([]string, error) {
 	switch backendType {
 	case "s3":
-		requiredVars = S3BackendVars
+		return S3BackendVars, nil
 	case "gcs":
-		requiredVars = GCSBackendVars
+		return GCSBackendVars, nil
+	case "azurerm":
+		return AzureRMBackendVars, nil
+	case "consul":
+		return ConsulBackendVars, nil
 	default:
 		return nil, fmt.Errorf("unsupported backend type: %s", backendType)
 	}
+}
 
-	// Load configuration from environment variables
-	for _, v := range requiredVars {
-		envVar := fmt.Sprintf("TF_BACKEND_%s_%s", strings.ToUpper(backendType), strings.ToUpper(v))
-		if val := os.Getenv(envVar); val != "" {
-			config.ConfigVars[v] = val
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
 		}
 	}
+	return false
+}
 
-	return config, nil
+// backendEnvVar returns the environment variable that holds a backend variable, e.g. TF_BACKEND_S3_BUCKET
+func backendEnvVar(backendType, name string) string {
+	return fmt.Sprintf("TF_BACKEND_%s_%s", strings.ToUpper(backendType), strings.ToUpper(name))
 }
 
 // GetTerraformConfig returns the backend configuration in Terraform format
@@ -107,17 +241,23 @@ func (c *BackendConfig) Validate() error {
 		requiredVars = []string{"bucket", "key", "region"}
 	case "gcs":
 		requiredVars = []string{"bucket", "prefix"}
+	case "azurerm":
+		requiredVars = []string{"resource_group_name", "storage_account_name", "container_name", "key"}
+	case "consul":
+		requiredVars = []string{"address", "path"}
 	}
 
 	var missingVars []string
+	var missingEnvVars []string
 	for _, v := range requiredVars {
 		if _, ok := c.ConfigVars[v]; !ok {
 			missingVars = append(missingVars, v)
+			missingEnvVars = append(missingEnvVars, backendEnvVar(c.Type, v))
 		}
 	}
 
 	if len(missingVars) > 0 {
-		return fmt.Errorf("missing required backend variables: %s", strings.Join(missingVars, ", "))
+		return fmt.Errorf("missing required backend variables: %s (set %s)", strings.Join(missingVars, ", "), strings.Join(missingEnvVars, ", "))
 	}
 
 	return nil
@@ -0,0 +1,242 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadBackendConfigFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "json",
+			file:    "backend.json",
+			content: `{"bucket": "state", "max_retries": 1000000, "ratio": 0.25, "encrypt": true}`,
+			want:    map[string]string{"bucket": "state", "max_retries": "1000000", "ratio": "0.25", "encrypt": "true"},
+		},
+		{
+			name:    "json with upper case extension",
+			file:    "backend.JSON",
+			content: `{"bucket": "state"}`,
+			want:    map[string]string{"bucket": "state"},
+		},
+		{
+			name:    "hcl",
+			file:    "backend.hcl",
+			content: "bucket = \"state\"\nmax_retries = 1000000\nencrypt = true\n",
+			want:    map[string]string{"bucket": "state", "max_retries": "1000000", "encrypt": "true"},
+		},
+		{
+			name:    "tfbackend is hcl",
+			file:    "s3.tfbackend",
+			content: `region = "eu-west-1"`,
+			want:    map[string]string{"region": "eu-west-1"},
+		},
+		{name: "malformed json", file: "backend.json", content: `{"bucket": "state"`, wantErr: true},
+		{name: "json with trailing data", file: "backend.json", content: `{"bucket": "state"} {}`, wantErr: true},
+		{name: "json object value", file: "backend.json", content: `{"bucket": {"name": "state"}}`, wantErr: true},
+		{name: "json null value", file: "backend.json", content: `{"bucket": null}`, wantErr: true},
+		{name: "malformed hcl", file: "backend.hcl", content: `bucket = "state`, wantErr: true},
+		{name: "hcl block", file: "backend.hcl", content: "s3 {\n  bucket = \"state\"\n}\n", wantErr: true},
+		{name: "hcl list value", file: "backend.hcl", content: `bucket = ["a", "b"]`, wantErr: true},
+		{name: "hcl reference", file: "backend.hcl", content: `bucket = var.bucket`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := LoadBackendConfigFile(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadBackendConfigFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadBackendConfigFile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadBackendConfigFileMissing(t *testing.T) {
+	if _, err := LoadBackendConfigFile(filepath.Join(t.TempDir(), "missing.hcl")); err == nil {
+		t.Error("LoadBackendConfigFile() of a missing file succeeded")
+	}
+}
+
+// clearBackendEnv unsets the backend environment variables of backendType that NewBackendConfig reads
+func clearBackendEnv(t *testing.T, backendType string) {
+	t.Helper()
+	t.Setenv("TF_BACKEND_TYPE", "")
+	vars, err := backendVars(backendType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range vars {
+		t.Setenv(backendEnvVar(backendType, v), "")
+	}
+}
+
+func TestAzureRMBackendConfig(t *testing.T) {
+	complete := map[string]string{
+		"resource_group_name":  "tfstate",
+		"storage_account_name": "tfstate123",
+		"container_name":       "state",
+		"key":                  "prod.tfstate",
+	}
+	with := func(extra map[string]string) map[string]string {
+		vars := make(map[string]string)
+		for k, v := range complete {
+			vars[k] = v
+		}
+		for k, v := range extra {
+			vars[k] = v
+		}
+		return vars
+	}
+	tests := []struct {
+		name        string
+		backendType string
+		vars        map[string]string
+		env         map[string]string
+		wantErr     string // error from NewBackendConfig
+		wantInvalid string // error from Validate
+	}{
+		{name: "complete", backendType: "azurerm", vars: complete},
+		{name: "type is case-insensitive", backendType: "AzureRM", vars: complete},
+		{name: "optional credentials", backendType: "azurerm", vars: with(map[string]string{"client_id": "id", "tenant_id": "t", "sas_token": "sas"})},
+		{
+			name:        "from environment",
+			backendType: "azurerm",
+			env: map[string]string{
+				"TF_BACKEND_AZURERM_RESOURCE_GROUP_NAME":  "tfstate",
+				"TF_BACKEND_AZURERM_STORAGE_ACCOUNT_NAME": "tfstate123",
+				"TF_BACKEND_AZURERM_CONTAINER_NAME":       "state",
+				"TF_BACKEND_AZURERM_KEY":                  "prod.tfstate",
+			},
+		},
+		{
+			name:        "unsupported variable",
+			backendType: "azurerm",
+			vars:        with(map[string]string{"bucket": "state"}),
+			wantErr:     `unsupported variable "bucket" for azurerm backend`,
+		},
+		{
+			name:        "missing required variables",
+			backendType: "azurerm",
+			vars:        map[string]string{"resource_group_name": "tfstate", "key": "prod.tfstate"},
+			wantInvalid: "missing required backend variables: storage_account_name, container_name (set TF_BACKEND_AZURERM_STORAGE_ACCOUNT_NAME, TF_BACKEND_AZURERM_CONTAINER_NAME)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runBackendConfigTest(t, tt.backendType, tt.vars, tt.env, tt.wantErr, tt.wantInvalid)
+		})
+	}
+}
+
+// runBackendConfigTest creates a backend config from vars and env and checks the errors of
+// NewBackendConfig and Validate, which must contain wantErr and wantInvalid when they are set
+func runBackendConfigTest(t *testing.T, backendType string, vars, env map[string]string, wantErr, wantInvalid string) {
+	t.Helper()
+	clearBackendEnv(t, strings.ToLower(backendType))
+	for k, v := range env {
+		t.Setenv(k, v)
+	}
+	backendConfig, err := NewBackendConfig(backendType, vars)
+	if wantErr != "" {
+		if err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Fatalf("NewBackendConfig() error = %v, want %q", err, wantErr)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("NewBackendConfig() error = %v", err)
+	}
+	if backendConfig.Type != strings.ToLower(backendType) {
+		t.Errorf("Type = %q, want %q", backendConfig.Type, strings.ToLower(backendType))
+	}
+	err = backendConfig.Validate()
+	if wantInvalid == "" {
+		if err != nil {
+			t.Errorf("Validate() error = %v", err)
+		}
+		return
+	}
+	if err == nil || !strings.Contains(err.Error(), wantInvalid) {
+		t.Errorf("Validate() error = %v, want %q", err, wantInvalid)
+	}
+}
+
+func TestNewBackendConfigWithoutType(t *testing.T) {
+	t.Setenv("TF_BACKEND_TYPE", "")
+	if backendConfig, err := NewBackendConfig("", nil); err != nil || backendConfig != nil {
+		t.Errorf("NewBackendConfig() = %v, %v; want the local backend", backendConfig, err)
+	}
+	if _, err := NewBackendConfig("", map[string]string{"bucket": "state"}); err == nil {
+		t.Error("NewBackendConfig() with variables but no type succeeded")
+	}
+	if _, err := NewBackendConfig("swift", nil); err == nil || !strings.Contains(err.Error(), "unsupported backend type") {
+		t.Errorf("NewBackendConfig(swift) error = %v, want unsupported backend type", err)
+	}
+}
+
+func TestConsulBackendConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		vars        map[string]string
+		env         map[string]string
+		wantErr     string
+		wantInvalid string
+	}{
+		{name: "required only", vars: map[string]string{"address": "consul:8500", "path": "fctl/prod"}},
+		{
+			name: "optional variables",
+			vars: map[string]string{"address": "consul:8500", "path": "fctl/prod", "scheme": "https", "datacenter": "dc1", "token": "t0ken"},
+		},
+		{
+			name: "flags override the environment",
+			vars: map[string]string{"path": "fctl/prod"},
+			env:  map[string]string{"TF_BACKEND_CONSUL_ADDRESS": "consul:8500", "TF_BACKEND_CONSUL_PATH": "fctl/dev"},
+		},
+		{
+			name:    "unsupported variable",
+			vars:    map[string]string{"address": "consul:8500", "path": "fctl/prod", "lock": "true"},
+			wantErr: `unsupported variable "lock" for consul backend (supported: address, path, token, scheme, datacenter)`,
+		},
+		{
+			name:        "missing path",
+			vars:        map[string]string{"address": "consul:8500"},
+			wantInvalid: "missing required backend variables: path (set TF_BACKEND_CONSUL_PATH)",
+		},
+		{
+			name:        "missing everything",
+			wantInvalid: "missing required backend variables: address, path (set TF_BACKEND_CONSUL_ADDRESS, TF_BACKEND_CONSUL_PATH)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runBackendConfigTest(t, "consul", tt.vars, tt.env, tt.wantErr, tt.wantInvalid)
+		})
+	}
+}
+
+func TestConsulBackendConfigPrefersFlags(t *testing.T) {
+	clearBackendEnv(t, "consul")
+	t.Setenv("TF_BACKEND_CONSUL_PATH", "fctl/dev")
+	backendConfig, err := NewBackendConfig("consul", map[string]string{"address": "consul:8500", "path": "fctl/prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := backendConfig.ConfigVars["path"]; got != "fctl/prod" {
+		t.Errorf("path = %q, want the flag's fctl/prod", got)
+	}
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -21,112 +22,241 @@ type ClientConfig struct {
 	TokenExpiry     time.Time
 }
 
-// GetClientConfig returns the configuration for the specified profile
-func GetClientConfig(profileName string) *ClientConfig {
-	// Determine profile to use
-	if profileName == "" {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return nil
+// Profile is a named section of the credentials file
+type Profile struct {
+	Name            string
+	ControlPlaneURL string
+	Username        string
+	Token           string
+	TokenExpiry     string
+}
+
+// CredentialsPath returns the path to the credentials file (~/.facets/credentials)
+func CredentialsPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not get user home directory: %v", err)
+	}
+	return home + "/.facets/credentials", nil
+}
+
+// ConfigPath returns the path to the config file (~/.facets/config)
+func ConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not get user home directory: %v", err)
+	}
+	return home + "/.facets/config", nil
+}
+
+// GetDefaultProfile returns the default profile name from the config file, or "" if none is set
+func GetDefaultProfile() string {
+	configPath, err := ConfigPath()
+	if err != nil {
+		return ""
+	}
+	cfg, err := ini.Load(configPath)
+	if err != nil {
+		return ""
+	}
+	return cfg.Section("default").Key("profile").String()
+}
+
+// ListProfiles returns every profile in the credentials file, in file order
+func ListProfiles() ([]Profile, error) {
+	credsPath, err := CredentialsPath()
+	if err != nil {
+		return nil, err
+	}
+	creds, err := ini.Load(credsPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read credentials file at %s: %v", credsPath, err)
+	}
+
+	var profiles []Profile
+	for _, section := range creds.Sections() {
+		if section.Name() == ini.DefaultSection {
+			continue
 		}
-		configPath := home + "/.facets/config"
-		cfg, err := ini.Load(configPath)
-		if err != nil {
-			return nil
+		profiles = append(profiles, profileFromSection(section))
+	}
+	return profiles, nil
+}
+
+// DeleteProfile removes a profile section from the credentials file
+func DeleteProfile(name string) error {
+	credsPath, err := CredentialsPath()
+	if err != nil {
+		return err
+	}
+	creds, err := ini.Load(credsPath)
+	if err != nil {
+		return fmt.Errorf("could not read credentials file at %s: %v", credsPath, err)
+	}
+	if _, err := creds.GetSection(name); err != nil {
+		return fmt.Errorf("profile '%s' not found in %s", name, credsPath)
+	}
+	creds.DeleteSection(name)
+	if err := creds.SaveTo(credsPath); err != nil {
+		return fmt.Errorf("could not save credentials file at %s: %v", credsPath, err)
+	}
+	return nil
+}
+
+// ClearProfileToken removes the token and token expiry from a profile in the credentials file.
+// It returns false without error when the credentials file or the profile does not exist.
+func ClearProfileToken(name string) (bool, error) {
+	credsPath, err := CredentialsPath()
+	if err != nil {
+		return false, err
+	}
+	creds, err := ini.Load(credsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
 		}
-		profileName = cfg.Section("default").Key("profile").String()
-		if profileName == "" {
-			return nil
+		return false, fmt.Errorf("could not read credentials file at %s: %v", credsPath, err)
+	}
+	section, err := creds.GetSection(name)
+	if err != nil {
+		return false, nil
+	}
+	section.DeleteKey("token")
+	section.DeleteKey("token_expiry")
+	if err := creds.SaveTo(credsPath); err != nil {
+		return false, fmt.Errorf("could not save credentials file at %s: %v", credsPath, err)
+	}
+	return true, nil
+}
+
+// SetDefaultProfile sets the default profile in the config file. An empty name clears it.
+func SetDefaultProfile(name string) error {
+	configPath, err := ConfigPath()
+	if err != nil {
+		return err
+	}
+	cfg, err := ini.Load(configPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("could not read config file at %s: %v", configPath, err)
 		}
+		cfg = ini.Empty()
+	}
+	if name == "" {
+		cfg.Section("default").DeleteKey("profile")
+	} else {
+		cfg.Section("default").Key("profile").SetValue(name)
 	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
+		return fmt.Errorf("could not create config directory: %v", err)
+	}
+	if err := cfg.SaveTo(configPath); err != nil {
+		return fmt.Errorf("could not save config file at %s: %v", configPath, err)
+	}
+	return nil
+}
 
-	// Load credentials
-	home, err := os.UserHomeDir()
+// GetProfile returns a single profile from the credentials file
+func GetProfile(name string) (*Profile, error) {
+	credsPath, err := CredentialsPath()
 	if err != nil {
-		return nil
+		return nil, err
 	}
-	credsPath := home + "/.facets/credentials"
 	creds, err := ini.Load(credsPath)
 	if err != nil {
-		return nil
+		return nil, fmt.Errorf("could not read credentials file at %s: %v", credsPath, err)
 	}
+	section, err := creds.GetSection(name)
+	if err != nil {
+		return nil, fmt.Errorf("profile '%s' not found in %s", name, credsPath)
+	}
+	profile := profileFromSection(section)
+	return &profile, nil
+}
 
-	profile, err := creds.GetSection(profileName)
+// profileFromSection reads a Profile from a credentials file section
+func profileFromSection(section *ini.Section) Profile {
+	return Profile{
+		Name:            section.Name(),
+		ControlPlaneURL: section.Key("control_plane_url").String(),
+		Username:        section.Key("username").String(),
+		Token:           section.Key("token").String(),
+		TokenExpiry:     section.Key("token_expiry").String(),
+	}
+}
+
+// ResolveProfileName returns profileName, or the default profile from the config file when it is empty
+func ResolveProfileName(profileName string) (string, error) {
+	if profileName != "" {
+		return profileName, nil
+	}
+	configPath, err := ConfigPath()
 	if err != nil {
-		return nil
+		return "", err
+	}
+	cfg, err := ini.Load(configPath)
+	if err != nil {
+		return "", fmt.Errorf("no profile specified and could not read config file at %s", configPath)
 	}
+	profileName = cfg.Section("default").Key("profile").String()
+	if profileName == "" {
+		return "", fmt.Errorf("no profile specified and no default profile set in %s", configPath)
+	}
+	return profileName, nil
+}
 
-	host := profile.Key("control_plane_url").String()
-	username := profile.Key("username").String()
-	token := profile.Key("token").String()
-	tokenExpiryStr := profile.Key("token_expiry").String()
+// GetClientConfig returns the configuration for the specified profile
+func GetClientConfig(profileName string) *ClientConfig {
+	profileName, err := ResolveProfileName(profileName)
+	if err != nil {
+		return nil
+	}
+	profile, err := GetProfile(profileName)
+	if err != nil {
+		return nil
+	}
 
-	if host == "" || username == "" || token == "" {
+	if profile.ControlPlaneURL == "" || profile.Username == "" || profile.Token == "" {
 		return nil
 	}
 
 	var tokenExpiry time.Time
-	if tokenExpiryStr != "" {
-		tokenExpiry, err = time.Parse(time.RFC3339, tokenExpiryStr)
+	if profile.TokenExpiry != "" {
+		tokenExpiry, err = time.Parse(time.RFC3339, profile.TokenExpiry)
 		if err != nil {
 			return nil
 		}
 	}
 
 	return &ClientConfig{
-		ControlPlaneURL: host,
-		Username:        username,
-		Token:           token,
+		ControlPlaneURL: profile.ControlPlaneURL,
+		Username:        profile.Username,
+		Token:           profile.Token,
 		TokenExpiry:     tokenExpiry,
 	}
 }
 
 func GetClient(profileName string, skipExpiryCheck bool) (*client.Facets, runtime.ClientAuthInfoWriter, error) {
-	// Determine profile to use
-	if profileName == "" {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return nil, nil, fmt.Errorf("could not get user home directory: %v", err)
-		}
-		configPath := home + "/.facets/config"
-		cfg, err := ini.Load(configPath)
-		if err != nil {
-			return nil, nil, fmt.Errorf("no profile specified and could not read config file at %s", configPath)
-		}
-		profileName = cfg.Section("default").Key("profile").String()
-		if profileName == "" {
-			return nil, nil, fmt.Errorf("no profile specified and no default profile set in %s", configPath)
-		}
-	}
-
-	// Load credentials
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return nil, nil, fmt.Errorf("could not get user home directory: %v", err)
-	}
-	credsPath := home + "/.facets/credentials"
-	creds, err := ini.Load(credsPath)
+	profileName, err := ResolveProfileName(profileName)
 	if err != nil {
-		return nil, nil, fmt.Errorf("could not read credentials file at %s: %v", credsPath, err)
+		return nil, nil, err
 	}
-
-	profile, err := creds.GetSection(profileName)
+	profile, err := GetProfile(profileName)
 	if err != nil {
-		return nil, nil, fmt.Errorf("profile '%s' not found in %s", profileName, credsPath)
+		return nil, nil, err
 	}
 
-	host := profile.Key("control_plane_url").String()
-	username := profile.Key("username").String()
-	token := profile.Key("token").String()
-	tokenExpiryStr := profile.Key("token_expiry").String()
+	host := profile.ControlPlaneURL
+	username := profile.Username
+	token := profile.Token
 
 	if host == "" || username == "" || token == "" {
 		return nil, nil, fmt.Errorf("profile '%s' is missing one of control_plane_url, username, or token", profileName)
 	}
 
 	// Check token expiry, unless skipped by the caller (e.g., the login command)
-	if !skipExpiryCheck && tokenExpiryStr != "" {
-		tokenExpiry, err := time.Parse(time.RFC3339, tokenExpiryStr)
+	if !skipExpiryCheck && profile.TokenExpiry != "" {
+		tokenExpiry, err := time.Parse(time.RFC3339, profile.TokenExpiry)
 		if err != nil {
 			return nil, nil, fmt.Errorf("could not parse token_expiry for profile '%s': %v", profileName, err)
 		}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testCredentials = `[default]
+control_plane_url = https://a.example.com
+username = alice
+token = secret-a
+token_expiry = 2026-01-02T15:04:05Z
+
+[staging]
+control_plane_url = https://b.example.com
+username = bob
+token = secret-b
+`
+
+// writeCredentials points HOME at a temporary directory holding a credentials file with the given contents.
+func writeCredentials(t *testing.T, contents string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.MkdirAll(filepath.Join(home, ".facets"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	credsPath := filepath.Join(home, ".facets", "credentials")
+	if err := os.WriteFile(credsPath, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return credsPath
+}
+
+func TestListProfiles(t *testing.T) {
+	writeCredentials(t, testCredentials)
+	got, err := ListProfiles()
+	if err != nil {
+		t.Fatalf("ListProfiles() error = %v", err)
+	}
+	want := []Profile{
+		{Name: "default", ControlPlaneURL: "https://a.example.com", Username: "alice", Token: "secret-a", TokenExpiry: "2026-01-02T15:04:05Z"},
+		{Name: "staging", ControlPlaneURL: "https://b.example.com", Username: "bob", Token: "secret-b"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListProfiles() = %+v, want %+v", got, want)
+	}
+}
+
+func TestListProfilesWithoutFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if _, err := ListProfiles(); err == nil {
+		t.Error("ListProfiles() without a credentials file succeeded")
+	}
+}
+
+func TestDeleteProfile(t *testing.T) {
+	credsPath := writeCredentials(t, testCredentials)
+	if err := DeleteProfile("staging"); err != nil {
+		t.Fatalf("DeleteProfile() error = %v", err)
+	}
+
+	data, err := os.ReadFile(credsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "[staging]") || strings.Contains(string(data), "secret-b") {
+		t.Errorf("the staging section is still in the credentials file:\n%s", data)
+	}
+	profiles, err := ListProfiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 1 || profiles[0].Name != "default" || profiles[0].Token != "secret-a" {
+		t.Errorf("ListProfiles() after the delete = %+v, want only the untouched default profile", profiles)
+	}
+
+	if err := DeleteProfile("staging"); err == nil {
+		t.Error("DeleteProfile() of a missing profile succeeded")
+	}
+}
@@ -0,0 +1,187 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// Setting is a key of the settings file (~/.facets/fctl.ini), which holds tool defaults as opposed to
+// the credentials and the default profile. A setting is the default of the global or command flag Flag.
+type Setting struct {
+	Key         string
+	Type        string // "int", "bool", or "string"
+	Flag        string
+	Description string
+}
+
+// Settings are the keys that 'fctl config set' accepts
+var Settings = []Setting{
+	{Key: "base_dir", Type: "string", Flag: "base-dir", Description: "Directory for extracted deployments and their state"},
+	{Key: "keep_releases", Type: "int", Flag: "keep-releases", Description: "Number of local deployment directories and zips to keep per environment (0 disables cleanup)"},
+	{Key: "parallelism", Type: "int", Flag: "parallelism", Description: "Number of concurrent Terraform operations (10 by default)"},
+	{Key: "no_color", Type: "bool", Flag: "no-color", Description: "Disable ANSI colors in output"},
+	{Key: "non_interactive", Type: "bool", Flag: "non-interactive", Description: "Never prompt for input"},
+}
+
+// SettingsPath returns the path to the settings file (~/.facets/fctl.ini)
+func SettingsPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not get user home directory: %v", err)
+	}
+	return home + "/.facets/fctl.ini", nil
+}
+
+// LookupSetting returns the setting with the given key
+func LookupSetting(key string) (Setting, error) {
+	var keys []string
+	for _, s := range Settings {
+		if s.Key == key {
+			return s, nil
+		}
+		keys = append(keys, s.Key)
+	}
+	return Setting{}, fmt.Errorf("unknown setting %q (valid settings: %s)", key, strings.Join(keys, ", "))
+}
+
+// Normalize checks that value is valid for the setting and returns it in the form it is stored in
+func (s Setting) Normalize(value string) (string, error) {
+	switch s.Type {
+	case "int":
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || n < 0 {
+			return "", fmt.Errorf("%s must be a non-negative number, got %q", s.Key, value)
+		}
+		if s.Key == "parallelism" && n < 1 {
+			return "", fmt.Errorf("%s must be at least 1, got %q", s.Key, value)
+		}
+		return strconv.Itoa(n), nil
+	case "bool":
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return "", fmt.Errorf("%s must be true or false, got %q", s.Key, value)
+		}
+		return strconv.FormatBool(b), nil
+	default:
+		if strings.TrimSpace(value) == "" {
+			return "", fmt.Errorf("%s must not be empty", s.Key)
+		}
+		if s.Key == "base_dir" {
+			// Stored absolute, so the setting does not depend on the directory fctl is run from
+			abs, err := filepath.Abs(value)
+			if err != nil {
+				return "", fmt.Errorf("could not resolve %s: %v", value, err)
+			}
+			return abs, nil
+		}
+		return value, nil
+	}
+}
+
+// LoadSettings returns the valid settings that are set in the settings file, by key, and an error for
+// each invalid one, which is left out so that it can still be fixed with 'fctl config set'. A missing
+// file has no settings; keys that are not settings are ignored.
+func LoadSettings() (map[string]string, []error, error) {
+	settingsPath, err := SettingsPath()
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg, err := ini.Load(settingsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil, nil
+		}
+		return nil, nil, fmt.Errorf("could not read settings file at %s: %v", settingsPath, err)
+	}
+	values := make(map[string]string)
+	var invalid []error
+	for _, s := range Settings {
+		key := cfg.Section(ini.DefaultSection).Key(s.Key)
+		if key.String() == "" {
+			continue
+		}
+		value, err := s.Normalize(key.String())
+		if err != nil {
+			invalid = append(invalid, fmt.Errorf("%v in %s", err, settingsPath))
+			continue
+		}
+		values[s.Key] = value
+	}
+	return values, invalid, nil
+}
+
+// SetSetting validates value and stores it in the settings file, returning the stored value. An empty
+// value removes the setting.
+func SetSetting(key, value string) (string, error) {
+	s, err := LookupSetting(key)
+	if err != nil {
+		return "", err
+	}
+	if value != "" {
+		if value, err = s.Normalize(value); err != nil {
+			return "", err
+		}
+	}
+	settingsPath, err := SettingsPath()
+	if err != nil {
+		return "", err
+	}
+	cfg, err := ini.Load(settingsPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("could not read settings file at %s: %v", settingsPath, err)
+		}
+		cfg = ini.Empty()
+	}
+	if value == "" {
+		cfg.Section(ini.DefaultSection).DeleteKey(key)
+	} else {
+		cfg.Section(ini.DefaultSection).Key(key).SetValue(value)
+	}
+	if err := os.MkdirAll(filepath.Dir(settingsPath), 0700); err != nil {
+		return "", fmt.Errorf("could not create config directory: %v", err)
+	}
+	if err := cfg.SaveTo(settingsPath); err != nil {
+		return "", fmt.Errorf("could not save settings file at %s: %v", settingsPath, err)
+	}
+	return value, nil
+}
+
+// MigrateKeepReleases moves keep_releases from the [default] section of the config file, where older
+// versions of fctl read it, to the settings file, so that the settings file is its only source. When the
+// settings file already sets keep_releases, the old key is only removed. It returns the value that was
+// moved, or "" when there was nothing to move.
+func MigrateKeepReleases() (string, error) {
+	configPath, err := ConfigPath()
+	if err != nil {
+		return "", err
+	}
+	cfg, err := ini.Load(configPath)
+	if err != nil {
+		return "", nil
+	}
+	key := cfg.Section("default").Key("keep_releases")
+	if key.String() == "" {
+		return "", nil
+	}
+	values, _, err := LoadSettings()
+	if err != nil {
+		return "", err
+	}
+	moved := ""
+	if _, ok := values["keep_releases"]; !ok {
+		if moved, err = SetSetting("keep_releases", key.String()); err != nil {
+			return "", fmt.Errorf("could not move keep_releases from %s: %v", configPath, err)
+		}
+	}
+	cfg.Section("default").DeleteKey("keep_releases")
+	if err := cfg.SaveTo(configPath); err != nil {
+		return "", fmt.Errorf("could not save config file at %s: %v", configPath, err)
+	}
+	return moved, nil
+}
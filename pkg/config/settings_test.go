@@ -0,0 +1,130 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSettingNormalize(t *testing.T) {
+	tests := []struct {
+		key     string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{key: "parallelism", value: " 4 ", want: "4"},
+		{key: "parallelism", value: "0", wantErr: true},
+		{key: "parallelism", value: "-1", wantErr: true},
+		{key: "keep_releases", value: "ten", wantErr: true},
+		{key: "no_color", value: "1", want: "true"},
+		{key: "non_interactive", value: "f", want: "false"},
+		{key: "no_color", value: "yes", wantErr: true},
+		{key: "base_dir", value: "/data/fctl", want: "/data/fctl"},
+		{key: "base_dir", value: " ", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			s, err := LookupSetting(tt.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := s.Normalize(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Normalize(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadSettingsSkipsInvalidValues(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.MkdirAll(filepath.Join(home, ".facets"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	ini := "parallelism = lots\nkeep_releases = 3\nunknown = 1\n"
+	if err := os.WriteFile(filepath.Join(home, ".facets", "fctl.ini"), []byte(ini), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	values, invalid, err := LoadSettings()
+	if err != nil {
+		t.Fatalf("LoadSettings() error = %v", err)
+	}
+	if want := map[string]string{"keep_releases": "3"}; !reflect.DeepEqual(values, want) {
+		t.Errorf("values = %v, want %v", values, want)
+	}
+	if len(invalid) != 1 {
+		t.Fatalf("invalid = %v, want one error for parallelism", invalid)
+	}
+
+	// The invalid value can still be replaced
+	if _, err := SetSetting("parallelism", "2"); err != nil {
+		t.Fatalf("SetSetting() error = %v", err)
+	}
+	values, invalid, err = LoadSettings()
+	if err != nil || len(invalid) != 0 || values["parallelism"] != "2" {
+		t.Errorf("after SetSetting: values = %v, invalid = %v, err = %v", values, invalid, err)
+	}
+}
+
+func TestLoadSettingsWithoutFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	values, invalid, err := LoadSettings()
+	if err != nil || len(invalid) != 0 || len(values) != 0 {
+		t.Errorf("LoadSettings() = %v, %v, %v; want no settings", values, invalid, err)
+	}
+}
+
+func TestMigrateKeepReleases(t *testing.T) {
+	tests := []struct {
+		name      string
+		settings  string
+		wantMoved string
+		wantValue string
+	}{
+		{name: "moved when not set", wantMoved: "30", wantValue: "30"},
+		{name: "settings file wins", settings: "keep_releases = 5\n", wantMoved: "", wantValue: "5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			dir := filepath.Join(home, ".facets")
+			if err := os.MkdirAll(dir, 0700); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "config"), []byte("[default]\nprofile = work\nkeep_releases = 30\n"), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if tt.settings != "" {
+				if err := os.WriteFile(filepath.Join(dir, "fctl.ini"), []byte(tt.settings), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			moved, err := MigrateKeepReleases()
+			if err != nil {
+				t.Fatalf("MigrateKeepReleases() error = %v", err)
+			}
+			if moved != tt.wantMoved {
+				t.Errorf("moved = %q, want %q", moved, tt.wantMoved)
+			}
+			values, _, err := LoadSettings()
+			if err != nil || values["keep_releases"] != tt.wantValue {
+				t.Errorf("keep_releases = %q (err %v), want %q", values["keep_releases"], err, tt.wantValue)
+			}
+			if GetDefaultProfile() != "work" {
+				t.Errorf("the default profile was not kept")
+			}
+			if moved, err := MigrateKeepReleases(); err != nil || moved != "" {
+				t.Errorf("second MigrateKeepReleases() = %q, %v; want nothing to move", moved, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// ReadVaultBackendVars reads the variables of a backend from the Vault secret at TF_BACKEND_<TYPE>_VAULT_PATH.
+// It returns nil when the variable is not set. addr and token fall back to VAULT_ADDR and VAULT_TOKEN.
+// Keys of the secret that the backend does not understand are ignored.
+func ReadVaultBackendVars(backendType, addr, token string) (map[string]string, error) {
+	pathVar := backendEnvVar(backendType, "vault_path")
+	secretPath := os.Getenv(pathVar)
+	if secretPath == "" {
+		return nil, nil
+	}
+	if addr == "" {
+		addr = os.Getenv("VAULT_ADDR")
+	}
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+	if addr == "" || token == "" {
+		return nil, fmt.Errorf("%s is set, but the Vault address or token is missing (use --vault-addr and --vault-token, or VAULT_ADDR and VAULT_TOKEN)", pathVar)
+	}
+
+	secret, err := readVaultSecret(addr, token, secretPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backend variables from Vault secret %s: %w", secretPath, err)
+	}
+	supported, err := backendVars(backendType)
+	if err != nil {
+		return nil, err
+	}
+	configVars := make(map[string]string)
+	for k, v := range secret {
+		if !contains(supported, k) {
+			continue
+		}
+		switch v.(type) {
+		case string, json.Number, bool:
+			configVars[k] = fmt.Sprint(v)
+		default:
+			return nil, fmt.Errorf("Vault secret %s: value of %q must be a string, number, or bool", secretPath, k)
+		}
+	}
+	if len(configVars) == 0 {
+		return nil, fmt.Errorf("Vault secret %s has none of the %s backend variables (%s)", secretPath, backendType, strings.Join(supported, ", "))
+	}
+	return configVars, nil
+}
+
+// readVaultSecret reads the secret at path (e.g. secret/data/terraform for a KV v2 engine) with
+// the Vault API client and returns its key/value data. The client takes VAULT_NAMESPACE, the TLS
+// settings (VAULT_CACERT, VAULT_CLIENT_CERT, VAULT_SKIP_VERIFY, ...), VAULT_MAX_RETRIES, and
+// VAULT_CLIENT_TIMEOUT from the environment, like the vault CLI.
+func readVaultSecret(addr, token, path string) (map[string]interface{}, error) {
+	cfg := api.DefaultConfig()
+	if cfg.Error != nil {
+		return nil, fmt.Errorf("invalid Vault configuration: %w", cfg.Error)
+	}
+	cfg.Address = addr
+	client, err := api.NewClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("could not create Vault client: %w", err)
+	}
+	client.SetToken(token)
+
+	// The client decodes numbers as json.Number, so e.g. 1000000 does not become 1e+06
+	secret, err := client.Logical().Read(strings.TrimPrefix(path, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("no secret found at %s", path)
+	}
+	// KV v2 wraps the key/value pairs in data.data, next to data.metadata
+	if inner, ok := secret.Data["data"].(map[string]interface{}); ok {
+		if _, ok := secret.Data["metadata"]; ok {
+			return inner, nil
+		}
+	}
+	return secret.Data, nil
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// newVaultServer returns a Vault stand-in that serves body for every read made with token
+func newVaultServer(t *testing.T, token, body string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != token {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors": ["permission denied"]}`))
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestReadVaultBackendVars(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		token   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:  "kv v2",
+			body:  `{"data": {"data": {"bucket": "state", "region": "eu-west-1", "owner": "ops"}, "metadata": {"version": 3}}}`,
+			token: "t0ken",
+			want:  map[string]string{"bucket": "state", "region": "eu-west-1"},
+		},
+		{
+			name:  "kv v1 with a large number",
+			body:  `{"data": {"bucket": "state", "key": 1000000}}`,
+			token: "t0ken",
+			want:  map[string]string{"bucket": "state", "key": "1000000"},
+		},
+		{name: "no backend variables", body: `{"data": {"owner": "ops"}}`, token: "t0ken", wantErr: true},
+		{name: "wrong token", body: `{"data": {"bucket": "state"}}`, token: "wrong", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newVaultServer(t, "t0ken", tt.body)
+			t.Setenv("TF_BACKEND_S3_VAULT_PATH", "secret/data/terraform")
+			got, err := ReadVaultBackendVars("s3", server.URL, tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadVaultBackendVars() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadVaultBackendVars() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadVaultBackendVarsWithoutPath(t *testing.T) {
+	t.Setenv("TF_BACKEND_S3_VAULT_PATH", "")
+	got, err := ReadVaultBackendVars("s3", "", "")
+	if err != nil || got != nil {
+		t.Errorf("ReadVaultBackendVars() = %v, %v; want nil, nil", got, err)
+	}
+}
+
+func TestReadVaultBackendVarsNamespace(t *testing.T) {
+	var namespace string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		namespace = r.Header.Get("X-Vault-Namespace")
+		w.Write([]byte(`{"data": {"bucket": "state"}}`))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("TF_BACKEND_S3_VAULT_PATH", "secret/terraform")
+	t.Setenv("VAULT_NAMESPACE", "team-a")
+
+	if _, err := ReadVaultBackendVars("s3", server.URL, "t0ken"); err != nil {
+		t.Fatalf("ReadVaultBackendVars() error = %v", err)
+	}
+	if namespace != "team-a" {
+		t.Errorf("Vault request namespace = %q, want %q", namespace, "team-a")
+	}
+}
+
+func TestReadVaultBackendVarsMissingSecret(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+	t.Setenv("TF_BACKEND_S3_VAULT_PATH", "secret/terraform")
+
+	if _, err := ReadVaultBackendVars("s3", server.URL, "t0ken"); err == nil {
+		t.Error("ReadVaultBackendVars() of a missing secret succeeded")
+	}
+}
@@ -0,0 +1,187 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	jsonMode    bool
+	verboseMode bool
+	quietMode   bool
+	logFile     io.Writer
+	noColor     bool
+)
+
+// ANSI color codes for Colorize
+const (
+	Red     = "\033[31m"
+	Green   = "\033[32m"
+	Yellow  = "\033[33m"
+	Magenta = "\033[35m"
+	reset   = "\033[0m"
+)
+
+// SetJSON enables or disables JSON mode. In JSON mode informational output is
+// suppressed and a single JSONResult is written to stdout at the end of the run.
+func SetJSON(enabled bool) {
+	jsonMode = enabled
+}
+
+// JSON reports whether JSON mode is enabled
+func JSON() bool {
+	return jsonMode
+}
+
+// SetVerbose enables or disables debug output
+func SetVerbose(enabled bool) {
+	verboseMode = enabled
+}
+
+// SetColor enables or disables ANSI colors. Colors are also disabled when NO_COLOR is set (https://no-color.org).
+func SetColor(enabled bool) {
+	noColor = !enabled
+}
+
+// ColorEnabled reports whether ANSI colors may be printed
+func ColorEnabled() bool {
+	return !noColor && os.Getenv("NO_COLOR") == ""
+}
+
+// Colorize wraps s in the given ANSI color, or returns it unchanged when colors are disabled
+func Colorize(color, s string) string {
+	if !ColorEnabled() {
+		return s
+	}
+	return color + s + reset
+}
+
+// SetQuiet enables or disables quiet mode, in which only errors are printed
+func SetQuiet(enabled bool) {
+	quietMode = enabled
+}
+
+// Quiet reports whether quiet mode is enabled
+func Quiet() bool {
+	return quietMode
+}
+
+// SetLogFile sets a writer that receives a copy of all output, in every mode, such as a per-run log file. nil disables it.
+func SetLogFile(w io.Writer) {
+	logFile = w
+}
+
+// LogWriter returns the writer of the log file set with SetLogFile, or io.Discard if none is set
+func LogWriter() io.Writer {
+	if logFile == nil {
+		return io.Discard
+	}
+	return logFile
+}
+
+// Writer returns the writer for informational output, such as Terraform's own logs
+func Writer() io.Writer {
+	var w io.Writer = os.Stdout
+	if jsonMode || quietMode {
+		w = io.Discard
+	}
+	if logFile != nil {
+		return io.MultiWriter(w, logFile)
+	}
+	return w
+}
+
+// Infof prints an informational message unless JSON or quiet mode is enabled
+func Infof(format string, a ...interface{}) {
+	fmt.Fprintf(Writer(), format, a...)
+}
+
+// Infoln prints an informational message followed by a newline unless JSON or quiet mode is enabled
+func Infoln(a ...interface{}) {
+	fmt.Fprintln(Writer(), a...)
+}
+
+// Successf prints the final result of a command. Unlike Infof it is printed in quiet mode; in JSON mode
+// the JSONResult takes its place.
+func Successf(format string, a ...interface{}) {
+	var w io.Writer = os.Stdout
+	if jsonMode {
+		w = io.Discard
+	}
+	fmt.Fprintf(io.MultiWriter(w, LogWriter()), format, a...)
+}
+
+// Warnf prints a warning to stderr. Like errors, warnings are printed in every mode, including quiet and JSON.
+func Warnf(format string, a ...interface{}) {
+	fmt.Fprintf(io.MultiWriter(os.Stderr, LogWriter()), format, a...)
+}
+
+// Errorf prints an error to stderr. Errors are printed in every mode, including quiet and JSON.
+func Errorf(format string, a ...interface{}) {
+	fmt.Fprintf(io.MultiWriter(os.Stderr, LogWriter()), format, a...)
+}
+
+// StatusWriter returns the writer for progress messages of commands whose stdout is data, such as 'state pull'
+func StatusWriter() io.Writer {
+	if quietMode {
+		return io.Discard
+	}
+	return os.Stderr
+}
+
+// Debugf prints a [DEBUG] message when verbose mode is enabled
+func Debugf(format string, a ...interface{}) {
+	if verboseMode {
+		Infof("[DEBUG] "+format, a...)
+	}
+}
+
+// Promptf prints an interactive prompt. In JSON mode prompts go to stderr so stdout stays machine-readable.
+func Promptf(format string, a ...interface{}) {
+	if jsonMode {
+		fmt.Fprintf(os.Stderr, format, a...)
+		return
+	}
+	fmt.Printf(format, a...)
+}
+
+// JSONResult is the machine-readable summary of an export, plan, apply, or destroy run
+type JSONResult struct {
+	Status          string  `json:"status"`
+	EnvironmentID   string  `json:"environment_id,omitempty"`
+	DeploymentID    string  `json:"deployment_id,omitempty"`
+	OutputPath      string  `json:"output_path,omitempty"`
+	Error           string  `json:"error,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds"`
+
+	start time.Time
+}
+
+var result = &JSONResult{start: time.Now()}
+
+// Result returns the JSONResult for the current invocation
+func Result() *JSONResult {
+	return result
+}
+
+// Fail records err as the run's error, keeping the first error recorded.
+// The leading ❌ used in human-readable messages is stripped.
+func (r *JSONResult) Fail(err error) {
+	if err != nil && r.Error == "" {
+		r.Error = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(err.Error()), "❌"))
+	}
+}
+
+// Write finalizes the status and duration and encodes the result to w
+func (r *JSONResult) Write(w io.Writer) error {
+	r.Status = "success"
+	if r.Error != "" {
+		r.Status = "error"
+	}
+	r.DurationSeconds = time.Since(r.start).Seconds()
+	return json.NewEncoder(w).Encode(r)
+}
@@ -0,0 +1,114 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+)
+
+// capture returns what f writes to stdout and stderr
+func capture(t *testing.T, f func()) (stdout, stderr string) {
+	t.Helper()
+	outR, outW, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	errR, errW, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldOut, oldErr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = outW, errW
+	defer func() { os.Stdout, os.Stderr = oldOut, oldErr }()
+
+	f()
+	outW.Close()
+	errW.Close()
+	outBytes, _ := io.ReadAll(outR)
+	errBytes, _ := io.ReadAll(errR)
+	return string(outBytes), string(errBytes)
+}
+
+func TestWarnfIsPrintedInEveryMode(t *testing.T) {
+	tests := []struct {
+		name  string
+		quiet bool
+		json  bool
+	}{
+		{name: "default"},
+		{name: "quiet", quiet: true},
+		{name: "json", json: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetQuiet(tt.quiet)
+			SetJSON(tt.json)
+			defer SetQuiet(false)
+			defer SetJSON(false)
+
+			stdout, stderr := capture(t, func() {
+				Warnf("⚠️ careful\n")
+				Infof("progress\n")
+			})
+			if stderr != "⚠️ careful\n" {
+				t.Errorf("stderr = %q, want the warning", stderr)
+			}
+			wantStdout := "progress\n"
+			if tt.quiet || tt.json {
+				wantStdout = ""
+			}
+			if stdout != wantStdout {
+				t.Errorf("stdout = %q, want %q", stdout, wantStdout)
+			}
+		})
+	}
+}
+
+func TestJSONResult(t *testing.T) {
+	tests := []struct {
+		name       string
+		errs       []error
+		wantStatus string
+		wantError  string
+	}{
+		{name: "success", wantStatus: "success"},
+		{name: "nil error", errs: []error{nil}, wantStatus: "success"},
+		{name: "error without icon", errs: []error{errors.New("❌ Terraform apply failed: exit status 1\n")}, wantStatus: "error", wantError: "Terraform apply failed: exit status 1"},
+		{name: "first error is kept", errs: []error{errors.New("first"), errors.New("second")}, wantStatus: "error", wantError: "first"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &JSONResult{EnvironmentID: "env1", start: time.Now()}
+			for _, err := range tt.errs {
+				r.Fail(err)
+			}
+			var buf bytes.Buffer
+			if err := r.Write(&buf); err != nil {
+				t.Fatal(err)
+			}
+			var got map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatalf("Write() wrote invalid JSON %q: %v", buf.String(), err)
+			}
+			if got["status"] != tt.wantStatus {
+				t.Errorf("status = %v, want %q", got["status"], tt.wantStatus)
+			}
+			if errValue, _ := got["error"].(string); errValue != tt.wantError {
+				t.Errorf("error = %q, want %q", errValue, tt.wantError)
+			}
+			if got["environment_id"] != "env1" {
+				t.Errorf("environment_id = %v, want env1", got["environment_id"])
+			}
+			if _, ok := got["deployment_id"]; ok {
+				t.Errorf("empty deployment_id was not omitted")
+			}
+			if _, ok := got["duration_seconds"].(float64); !ok {
+				t.Errorf("duration_seconds = %v, want a number", got["duration_seconds"])
+			}
+		})
+	}
+}
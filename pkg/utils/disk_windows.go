@@ -0,0 +1,18 @@
+//go:build windows
+
+package utils
+
+import "golang.org/x/sys/windows"
+
+// FreeDiskSpace returns the number of bytes available to the current user on the volume holding path
+func FreeDiskSpace(path string) (uint64, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	var freeBytes uint64
+	if err := windows.GetDiskFreeSpaceEx(pathPtr, &freeBytes, nil, nil); err != nil {
+		return 0, err
+	}
+	return freeBytes, nil
+}
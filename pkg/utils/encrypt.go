@@ -0,0 +1,224 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// An encrypted zip starts with encryptedZipMagic, followed by the Argon2id parameters (time and memory
+// in KiB as uint32, threads as uint8, big endian), the salt, and the nonce prefix. The zip follows in
+// chunks of up to encryptionChunkSize bytes, each sealed with AES-256-GCM under the nonce prefix and
+// the chunk's index. The last chunk is sealed with different additional data, so a truncated file
+// fails to decrypt.
+const (
+	encryptedZipMagic     = "FCTLENC1"
+	encryptionSaltSize    = 16
+	encryptionNoncePrefix = 8
+	encryptionHeaderSize  = len(encryptedZipMagic) + 4 + 4 + 1 + encryptionSaltSize + encryptionNoncePrefix
+	encryptionChunkSize   = 1 << 20
+)
+
+// argon2Params are the Argon2id parameters the key of an encrypted zip is derived with
+type argon2Params struct {
+	time    uint32
+	memory  uint32 // KiB
+	threads uint8
+}
+
+// defaultArgon2Params are the second recommended option of RFC 9106, which uses 64 MiB of memory
+var defaultArgon2Params = argon2Params{time: 3, memory: 64 * 1024, threads: 4}
+
+// supported reports whether p can be used to decrypt a file. The limits keep a crafted header from
+// making the key derivation take excessive time or memory.
+func (p argon2Params) supported() bool {
+	return p.time >= 1 && p.time <= 10*defaultArgon2Params.time &&
+		p.memory >= 8*uint32(p.threads) && p.memory <= 16*defaultArgon2Params.memory &&
+		p.threads >= 1
+}
+
+var (
+	lastChunkAAD = []byte{1}
+	chunkAAD     = []byte{0}
+)
+
+// ErrWrongPassphrase is returned by DecryptFile when the passphrase does not match, or the file was modified
+var ErrWrongPassphrase = errors.New("wrong passphrase, or the file is corrupted")
+
+// IsEncryptedZip reports whether the file at path was written by EncryptFile
+func IsEncryptedZip(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	magic := make([]byte, len(encryptedZipMagic))
+	if _, err := io.ReadFull(f, magic); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return false, nil
+		}
+		return false, err
+	}
+	return string(magic) == encryptedZipMagic, nil
+}
+
+// EncryptFile encrypts src to dst with AES-256-GCM, using a key derived from passphrase with Argon2id
+func EncryptFile(src, dst, passphrase string) error {
+	if passphrase == "" {
+		return errors.New("passphrase must not be empty")
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	salt := make([]byte, encryptionSaltSize)
+	noncePrefix := make([]byte, encryptionNoncePrefix)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	if _, err := rand.Read(noncePrefix); err != nil {
+		return err
+	}
+	params := defaultArgon2Params
+	aead, err := newZipAEAD(passphrase, salt, params)
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	w := bufio.NewWriter(out)
+	w.WriteString(encryptedZipMagic)
+	binary.Write(w, binary.BigEndian, params.time)
+	binary.Write(w, binary.BigEndian, params.memory)
+	w.WriteByte(params.threads)
+	w.Write(salt)
+	w.Write(noncePrefix)
+
+	r := bufio.NewReaderSize(in, encryptionChunkSize)
+	chunk := make([]byte, encryptionChunkSize)
+	for index := uint32(0); ; index++ {
+		n, err := io.ReadFull(r, chunk)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		// The chunk is the last one when the input ends in or right after it
+		_, peekErr := r.Peek(1)
+		last := peekErr == io.EOF
+		aad := chunkAAD
+		if last {
+			aad = lastChunkAAD
+		}
+		if _, err := w.Write(aead.Seal(nil, chunkNonce(noncePrefix, index), chunk[:n], aad)); err != nil {
+			return err
+		}
+		if last {
+			break
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// DecryptFile decrypts src, written by EncryptFile, to dst. It returns ErrWrongPassphrase when
+// the passphrase does not match or the file was modified or truncated.
+func DecryptFile(src, dst, passphrase string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	r := bufio.NewReaderSize(in, encryptionChunkSize)
+
+	header := make([]byte, encryptionHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil || !bytes.HasPrefix(header, []byte(encryptedZipMagic)) {
+		return fmt.Errorf("%s is not an encrypted zip", src)
+	}
+	header = header[len(encryptedZipMagic):]
+	params := argon2Params{
+		time:    binary.BigEndian.Uint32(header[0:4]),
+		memory:  binary.BigEndian.Uint32(header[4:8]),
+		threads: header[8],
+	}
+	if !params.supported() {
+		return fmt.Errorf("%s has an unsupported key derivation setting", src)
+	}
+	salt := header[9 : 9+encryptionSaltSize]
+	noncePrefix := header[9+encryptionSaltSize:]
+	aead, err := newZipAEAD(passphrase, salt, params)
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	w := bufio.NewWriter(out)
+
+	sealed := make([]byte, encryptionChunkSize+aead.Overhead())
+	for index := uint32(0); ; index++ {
+		n, err := io.ReadFull(r, sealed)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			if err == io.EOF {
+				// The last chunk was cut off
+				return ErrWrongPassphrase
+			}
+			return err
+		}
+		_, peekErr := r.Peek(1)
+		last := peekErr == io.EOF
+		aad := chunkAAD
+		if last {
+			aad = lastChunkAAD
+		}
+		plain, err := aead.Open(nil, chunkNonce(noncePrefix, index), sealed[:n], aad)
+		if err != nil {
+			return ErrWrongPassphrase
+		}
+		if _, err := w.Write(plain); err != nil {
+			return err
+		}
+		if last {
+			break
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// newZipAEAD derives the AES-256 key from passphrase and returns the GCM cipher for it
+func newZipAEAD(passphrase string, salt []byte, params argon2Params) (cipher.AEAD, error) {
+	key := argon2.IDKey([]byte(passphrase), salt, params.time, params.memory, params.threads, 32)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce returns the 12-byte nonce of the chunk at index
+func chunkNonce(prefix []byte, index uint32) []byte {
+	nonce := make([]byte, 0, len(prefix)+4)
+	nonce = append(nonce, prefix...)
+	return binary.BigEndian.AppendUint32(nonce, index)
+}
@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// encryptTestFile writes data to a file, encrypts it with passphrase, and returns the encrypted file's path
+func encryptTestFile(t *testing.T, data []byte, passphrase string) string {
+	t.Helper()
+	dir := t.TempDir()
+	src := filepath.Join(dir, "export.zip")
+	if err := os.WriteFile(src, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(dir, "export.zip.enc")
+	if err := EncryptFile(src, dst, passphrase); err != nil {
+		t.Fatalf("EncryptFile() error = %v", err)
+	}
+	return dst
+}
+
+func randomBytes(t *testing.T, n int) []byte {
+	t.Helper()
+	data := make([]byte, n)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+	}{
+		{name: "empty", size: 0},
+		{name: "small", size: 100},
+		{name: "one full chunk", size: encryptionChunkSize},
+		{name: "several chunks", size: 2*encryptionChunkSize + 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := randomBytes(t, tt.size)
+			encrypted := encryptTestFile(t, data, "s3cret")
+
+			ok, err := IsEncryptedZip(encrypted)
+			if err != nil || !ok {
+				t.Fatalf("IsEncryptedZip() = %v, %v; want true", ok, err)
+			}
+			decrypted := filepath.Join(t.TempDir(), "decrypted.zip")
+			if err := DecryptFile(encrypted, decrypted, "s3cret"); err != nil {
+				t.Fatalf("DecryptFile() error = %v", err)
+			}
+			got, err := os.ReadFile(decrypted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("decrypted %d bytes that differ from the %d bytes encrypted", len(got), len(data))
+			}
+		})
+	}
+}
+
+func TestDecryptWrongPassphrase(t *testing.T) {
+	encrypted := encryptTestFile(t, randomBytes(t, 100), "s3cret")
+	err := DecryptFile(encrypted, filepath.Join(t.TempDir(), "decrypted.zip"), "guess")
+	if !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("DecryptFile() error = %v, want ErrWrongPassphrase", err)
+	}
+}
+
+func TestDecryptTruncated(t *testing.T) {
+	headerSize := int64(encryptionHeaderSize)
+	sealedChunkSize := int64(encryptionChunkSize + 16) // GCM adds a 16-byte tag
+	encrypted := encryptTestFile(t, randomBytes(t, encryptionChunkSize+100), "s3cret")
+
+	tests := []struct {
+		name string
+		size int64
+	}{
+		// A complete first chunk, which would pass for the last one if it were not sealed as a middle chunk
+		{name: "at a chunk boundary", size: headerSize + sealedChunkSize},
+		{name: "inside a chunk", size: headerSize + sealedChunkSize + 50},
+		{name: "header only", size: headerSize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := os.ReadFile(encrypted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			truncated := filepath.Join(t.TempDir(), "truncated.zip.enc")
+			if err := os.WriteFile(truncated, data[:tt.size], 0600); err != nil {
+				t.Fatal(err)
+			}
+			err = DecryptFile(truncated, filepath.Join(t.TempDir(), "decrypted.zip"), "s3cret")
+			if !errors.Is(err, ErrWrongPassphrase) {
+				t.Errorf("DecryptFile() error = %v, want ErrWrongPassphrase", err)
+			}
+		})
+	}
+}
+
+func TestDecryptNotEncrypted(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "plain.zip")
+	if err := os.WriteFile(src, []byte("PK\x03\x04 not encrypted"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := IsEncryptedZip(src); err != nil || ok {
+		t.Errorf("IsEncryptedZip() = %v, %v; want false", ok, err)
+	}
+	if err := DecryptFile(src, filepath.Join(t.TempDir(), "decrypted.zip"), "s3cret"); err == nil || errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("DecryptFile() error = %v, want a not-encrypted error", err)
+	}
+}
+
+func TestEncryptEmptyPassphrase(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "export.zip")
+	if err := os.WriteFile(src, []byte("zip"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := EncryptFile(src, src+".enc", ""); err == nil {
+		t.Error("EncryptFile() with an empty passphrase succeeded")
+	}
+}
+
+func TestDecryptUnsupportedKeyDerivation(t *testing.T) {
+	encrypted := encryptTestFile(t, randomBytes(t, 100), "s3cret")
+	tests := []struct {
+		name   string
+		offset int // of the parameter in the header
+		value  []byte
+	}{
+		{name: "zero time", offset: len(encryptedZipMagic), value: []byte{0, 0, 0, 0}},
+		{name: "excessive memory", offset: len(encryptedZipMagic) + 4, value: []byte{0xff, 0xff, 0xff, 0xff}},
+		{name: "zero threads", offset: len(encryptedZipMagic) + 8, value: []byte{0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := os.ReadFile(encrypted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			copy(data[tt.offset:], tt.value)
+			tampered := filepath.Join(t.TempDir(), "tampered.zip.enc")
+			if err := os.WriteFile(tampered, data, 0600); err != nil {
+				t.Fatal(err)
+			}
+			err = DecryptFile(tampered, filepath.Join(t.TempDir(), "decrypted.zip"), "s3cret")
+			if err == nil || !strings.Contains(err.Error(), "unsupported key derivation setting") {
+				t.Errorf("DecryptFile() error = %v, want an unsupported key derivation setting", err)
+			}
+		})
+	}
+}
@@ -5,11 +5,14 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
+	"net"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"sort"
 	"strings"
@@ -18,7 +21,9 @@ import (
 
 	"crypto/sha256"
 
+	"github.com/Facets-cloud/fctl/pkg/output"
 	"github.com/go-ini/ini"
+	"github.com/go-openapi/runtime"
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/hashicorp/terraform-config-inspect/tfconfig"
@@ -62,7 +67,9 @@ func ExtractDeploymentID(zipPath string) (string, error) {
 	return matches[1], nil
 }
 
-// ExtractZip extracts a zip file to the destination directory
+// ExtractZip extracts a zip file to the destination directory. Symlinks are recreated after all
+// other entries are written, and must point inside the destination directory. Entries written on
+// Unix keep their mode; the others get the default mode.
 func ExtractZip(zipPath, destPath string) error {
 	reader, err := zip.OpenReader(zipPath)
 	if err != nil {
@@ -70,11 +77,24 @@ func ExtractZip(zipPath, destPath string) error {
 	}
 	defer reader.Close()
 
+	var symlinks, dirs []*zip.File
 	for _, file := range reader.File {
 		path := filepath.Join(destPath, file.Name)
+		if !isWithinDir(destPath, path) {
+			return fmt.Errorf("zip entry %s is outside the archive root", file.Name)
+		}
 
 		if file.FileInfo().IsDir() {
-			os.MkdirAll(path, file.Mode())
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+			// Modes are set last, so that read-only directories can still be filled
+			dirs = append(dirs, file)
+			continue
+		}
+		if file.Mode()&os.ModeSymlink != 0 {
+			// Created last, so no other entry is written through a symlink
+			symlinks = append(symlinks, file)
 			continue
 		}
 
@@ -99,12 +119,143 @@ func ExtractZip(zipPath, destPath string) error {
 		if err != nil {
 			return err
 		}
+		// OpenFile applies the umask, and keeps the mode of a file that already exists
+		if hasUnixMode(file) {
+			if err := os.Chmod(path, file.Mode().Perm()); err != nil {
+				return err
+			}
+		}
+	}
+	for _, file := range symlinks {
+		if err := extractZipSymlink(file, destPath); err != nil {
+			return err
+		}
+	}
+	// Children come after their directory in a zip, so going backwards sets the deepest modes first
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if hasUnixMode(dirs[i]) {
+			if err := os.Chmod(filepath.Join(destPath, dirs[i].Name), dirs[i].Mode().Perm()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// hasUnixMode reports whether the zip entry was written on Unix or macOS, so its mode is meaningful.
+// Entries written on other systems only record whether they are read-only.
+func hasUnixMode(file *zip.File) bool {
+	creator := file.CreatorVersion >> 8
+	return creator == 3 || creator == 19
+}
+
+// extractZipSymlink creates the symlink stored in file under destPath. A symlink whose target is
+// absolute or resolves outside destPath is refused.
+func extractZipSymlink(file *zip.File, destPath string) error {
+	path := filepath.Join(destPath, file.Name)
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	target, err := io.ReadAll(io.LimitReader(src, 4096))
+	src.Close()
+	if err != nil {
+		return err
+	}
+	linkTarget := filepath.FromSlash(string(target))
+	if filepath.IsAbs(linkTarget) || !isWithinDir(destPath, filepath.Join(filepath.Dir(path), linkTarget)) {
+		return fmt.Errorf("symlink %s points outside the archive root: %s", file.Name, target)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if info, err := os.Lstat(path); err == nil && !info.IsDir() {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	if err := os.Symlink(linkTarget, path); err != nil {
+		return err
+	}
+	// The path may go through other symlinks of the archive, so check where it really ends up
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		root, err := filepath.EvalSymlinks(destPath)
+		if err != nil {
+			return err
+		}
+		if !isWithinDir(root, resolved) {
+			os.Remove(path)
+			return fmt.Errorf("symlink %s points outside the archive root: %s", file.Name, target)
+		}
 	}
 	return nil
 }
 
-// ZipDir zips the contents of srcDir into zipPath
+// isWithinDir reports whether path is dir or inside it
+func isWithinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// VerifyZip reads every entry of a zip file so that truncated or corrupted archives
+// are detected by their CRC-32 checks before anything is extracted
+func VerifyZip(zipPath string) error {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return fmt.Errorf("corrupted archive %s, please re-run the export: %v", zipPath, err)
+	}
+	defer reader.Close()
+
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		srcFile, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("corrupted archive %s (entry %s), please re-run the export: %v", zipPath, file.Name, err)
+		}
+		_, err = io.Copy(io.Discard, srcFile)
+		srcFile.Close()
+		if err != nil {
+			return fmt.Errorf("corrupted archive %s (entry %s), please re-run the export: %v", zipPath, file.Name, err)
+		}
+	}
+	return nil
+}
+
+// VerifyChecksum checks a file against a digest of the form sha256:<hex>
+func VerifyChecksum(path, checksum string) error {
+	algorithm, expected, found := strings.Cut(checksum, ":")
+	if !found || algorithm != "sha256" || expected == "" {
+		return fmt.Errorf("unsupported checksum %q, expected sha256:<hex>", checksum)
+	}
+	actual, err := HashFile(path)
+	if err != nil {
+		return fmt.Errorf("could not hash %s: %v", path, err)
+	}
+	if !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("checksum mismatch for %s: expected sha256:%s, got sha256:%s", path, expected, actual)
+	}
+	return nil
+}
+
+// ZipOptions controls what ZipDirWithOptions adds to the zip
+type ZipOptions struct {
+	// Exclude leaves out the files and directories for which it returns true. It is called with the
+	// slash-separated path relative to the source directory.
+	Exclude func(relPath string, info os.FileInfo) bool
+	// DereferenceSymlinks adds the files and directories that symlinks point to instead of the symlinks
+	DereferenceSymlinks bool
+}
+
+// ZipDir zips the contents of srcDir into zipPath. Symlinks are stored as symlinks, like 'zip --symlinks'.
 func ZipDir(source, target string) error {
+	return ZipDirWithOptions(source, target, ZipOptions{})
+}
+
+// ZipDirWithOptions zips the contents of source into target
+func ZipDirWithOptions(source, target string, opts ZipOptions) error {
 	zipfile, err := os.Create(target)
 	if err != nil {
 		return err
@@ -114,37 +265,71 @@ func ZipDir(source, target string) error {
 	archive := zip.NewWriter(zipfile)
 	defer archive.Close()
 
-	err = filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
+	root, err := filepath.EvalSymlinks(source)
+	if err != nil {
+		return err
+	}
+	return zipTree(archive, source, "", opts, map[string]bool{root: true})
+}
+
+// zipTree adds the contents of dir to archive, with names under prefix. visiting holds the directories
+// being added, so that a symlink to one of them is reported instead of being followed forever.
+func zipTree(archive *zip.Writer, dir, prefix string, opts ZipOptions, visiting map[string]bool) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-		relPath, err := filepath.Rel(source, path)
+		relPath, err := filepath.Rel(dir, path)
 		if err != nil {
 			return err
 		}
-		if relPath == "." {
+		name := filepath.ToSlash(relPath)
+		if prefix != "" {
+			name = strings.TrimSuffix(prefix+"/"+name, "/.")
+		} else if relPath == "." {
 			return nil
 		}
-		if info.IsDir() {
-			// Only add directory entry if empty
-			files, err := os.ReadDir(path)
+		if relPath != "." && opts.Exclude != nil && opts.Exclude(name, info) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			if !opts.DereferenceSymlinks {
+				return addZipSymlink(archive, path, name, info)
+			}
+			resolved, err := filepath.EvalSymlinks(path)
 			if err != nil {
+				return fmt.Errorf("cannot follow symlink %s: %v", path, err)
+			}
+			if info, err = os.Stat(resolved); err != nil {
 				return err
 			}
-			if len(files) == 0 {
-				hdr := &zip.FileHeader{
-					Name:     relPath + "/",
-					Method:   zip.Deflate,
-					Modified: info.ModTime(),
+			if info.IsDir() {
+				if visiting[resolved] {
+					return fmt.Errorf("symlink %s points to a directory that contains it", path)
 				}
-				_, err := archive.CreateHeader(hdr)
+				visiting[resolved] = true
+				defer delete(visiting, resolved)
+				return zipTree(archive, resolved, name, opts, visiting)
+			}
+			path = resolved
+		}
+		if info.IsDir() {
+			// Every directory is added, so that empty directories and directory modes are kept
+			hdr, err := zip.FileInfoHeader(info)
+			if err != nil {
 				return err
 			}
-			return nil // skip non-empty directories
+			hdr.Name = name + "/"
+			hdr.Method = zip.Store
+			_, err = archive.CreateHeader(hdr)
+			return err
 		}
 		// Only add regular files
 		if !info.Mode().IsRegular() {
-			// skip non-regular files (symlinks, devices, etc.)
+			// skip non-regular files (devices, sockets, etc.)
 			return nil
 		}
 		file, err := os.Open(path)
@@ -157,7 +342,7 @@ func ZipDir(source, target string) error {
 			file.Close()
 			return err
 		}
-		hdr.Name = relPath
+		hdr.Name = name
 		hdr.Method = zip.Deflate
 
 		writer, err := archive.CreateHeader(hdr)
@@ -169,6 +354,26 @@ func ZipDir(source, target string) error {
 		file.Close()
 		return err
 	})
+}
+
+// addZipSymlink stores the symlink at path as an entry with the symlink mode bits and the link target
+// as its content, the way the zip command does
+func addZipSymlink(archive *zip.Writer, path, name string, info os.FileInfo) error {
+	linkTarget, err := os.Readlink(path)
+	if err != nil {
+		return err
+	}
+	hdr, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+	hdr.Method = zip.Store
+	writer, err := archive.CreateHeader(hdr)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(writer, filepath.ToSlash(linkTarget))
 	return err
 }
 
@@ -209,12 +414,12 @@ func ListExistingDeployments(envDir, currentDeploymentID string) ([]string, erro
 
 // PromptUser prompts the user to select a deployment or use tf.tfstate if available
 func PromptUser(existingDeployments []string, tfStatePath string) (bool, string, error) {
-	fmt.Println("\n⚠️  Found existing deployments for this environment:")
+	output.Promptf("\n⚠️  Found existing deployments for this environment:\n")
 	for i, deploymentID := range existingDeployments {
-		fmt.Printf("%d. %s\n", i+1, deploymentID)
+		output.Promptf("%d. %s\n", i+1, deploymentID)
 	}
 	promptMsg := "\n❓ Do you want to proceed with an existing state file? If yes enter 'y', else enter 'n' if you want to start fresh with a new state file, or just press enter to use the tf.tfstate file in the current environment (saved after each release): "
-	fmt.Print(promptMsg)
+	output.Promptf("%s", promptMsg)
 	reader := bufio.NewReader(os.Stdin)
 	response, err := reader.ReadString('\n')
 	if err != nil {
@@ -229,7 +434,7 @@ func PromptUser(existingDeployments []string, tfStatePath string) (bool, string,
 	if response != "y" && response != "yes" {
 		return false, "", nil
 	}
-	fmt.Print("\n📂 Enter the number of the deployment to use (1-" + fmt.Sprint(len(existingDeployments)) + "): ")
+	output.Promptf("\n📂 Enter the number of the deployment to use (1-%d): ", len(existingDeployments))
 	numStr, err := reader.ReadString('\n')
 	if err != nil {
 		return false, "", err
@@ -243,6 +448,18 @@ func PromptUser(existingDeployments []string, tfStatePath string) (bool, string,
 	return true, existingDeployments[num-1], nil
 }
 
+// Confirm asks a yes/no question on stdin and reports whether the user answered yes
+func Confirm(prompt string) (bool, error) {
+	output.Promptf("%s [y/N]: ", prompt)
+	reader := bufio.NewReader(os.Stdin)
+	response, err := reader.ReadString('\n')
+	if err != nil {
+		return false, err
+	}
+	response = strings.ToLower(strings.TrimSpace(response))
+	return response == "y" || response == "yes", nil
+}
+
 // CopyFile copies a file from src to dst
 func CopyFile(src, dst string) error {
 	srcFile, err := os.Open(src)
@@ -273,7 +490,7 @@ func CopyStateFromPreviousDeployment(envDir, currentDeploymentID, envID, selecte
 	if _, err := os.Stat(prevStatePath); err != nil {
 		return fmt.Errorf("no state file found in deployment %s", selectedDeployment)
 	}
-	fmt.Printf("📝 Found state file in deployment %s\n", selectedDeployment)
+	output.Infof("📝 Found state file in deployment %s\n", selectedDeployment)
 	newStateDir := filepath.Join(envDir, currentDeploymentID, "tfexport", "terraform.tfstate.d", envID)
 	if err := os.MkdirAll(newStateDir, 0755); err != nil {
 		return fmt.Errorf("failed to create state directory: %v", err)
@@ -282,7 +499,7 @@ func CopyStateFromPreviousDeployment(envDir, currentDeploymentID, envID, selecte
 	if err := CopyFile(prevStatePath, newStatePath); err != nil {
 		return fmt.Errorf("failed to copy state file: %v", err)
 	}
-	fmt.Printf("✅ Successfully copied state file from deployment %s\n", selectedDeployment)
+	output.Infof("✅ Successfully copied state file from deployment %s\n", selectedDeployment)
 	return nil
 }
 
@@ -302,7 +519,7 @@ func ParseStateFile(state *tfjson.State) []map[string]interface{} {
 				if attrs, ok := resource.AttributeValues["in"].(string); ok {
 					var inData map[string]interface{}
 					if err := json.Unmarshal([]byte(attrs), &inData); err != nil {
-						fmt.Printf("⚠️ Warning: Failed to parse release metadata JSON: %v\n", err)
+						output.Warnf("⚠️ Warning: Failed to parse release metadata JSON: %v\n", err)
 						continue
 					}
 					if releaseMetadata, ok := inData["release_metadata"].(map[string]interface{}); ok {
@@ -321,19 +538,158 @@ func ParseStateFile(state *tfjson.State) []map[string]interface{} {
 	return releaseMetadataList
 }
 
+// PlanChangeSummary counts the planned resource changes in one module
+type PlanChangeSummary struct {
+	Module  string `json:"module"`
+	Add     int    `json:"add"`
+	Change  int    `json:"change"`
+	Destroy int    `json:"destroy"`
+}
+
+// SummarizePlan aggregates resource changes per module, sorted by module address.
+// Replacements count as one add and one destroy, as in Terraform's own summary.
+func SummarizePlan(plan *tfjson.Plan) []PlanChangeSummary {
+	byModule := make(map[string]*PlanChangeSummary)
+	var modules []string
+	for _, rc := range plan.ResourceChanges {
+		if rc.Change == nil {
+			continue
+		}
+		module := rc.ModuleAddress
+		if module == "" {
+			module = "root"
+		}
+		summary, ok := byModule[module]
+		if !ok {
+			summary = &PlanChangeSummary{Module: module}
+			byModule[module] = summary
+			modules = append(modules, module)
+		}
+		actions := rc.Change.Actions
+		switch {
+		case actions.Replace():
+			summary.Add++
+			summary.Destroy++
+		case actions.Create():
+			summary.Add++
+		case actions.Update():
+			summary.Change++
+		case actions.Delete():
+			summary.Destroy++
+		}
+	}
+	sort.Strings(modules)
+	summaries := make([]PlanChangeSummary, 0, len(modules))
+	for _, module := range modules {
+		summaries = append(summaries, *byModule[module])
+	}
+	return summaries
+}
+
+// PlannedDestroys returns the addresses of resources a plan deletes, including replacements
+func PlannedDestroys(plan *tfjson.Plan) []string {
+	var addresses []string
+	for _, rc := range plan.ResourceChanges {
+		if rc.Change != nil && (rc.Change.Actions.Delete() || rc.Change.Actions.Replace()) {
+			addresses = append(addresses, rc.Address)
+		}
+	}
+	return addresses
+}
+
+// DriftedResources returns the resources whose real infrastructure differs from the state, as found by the
+// refresh before planning, formatted as "<address> (<how>)" and sorted by address.
+func DriftedResources(plan *tfjson.Plan) []string {
+	var drifted []string
+	for _, d := range FindDrift(plan) {
+		how := "changed"
+		if d.Action == "delete" {
+			how = "deleted"
+		}
+		drifted = append(drifted, fmt.Sprintf("%s (%s)", d.Address, how))
+	}
+	return drifted
+}
+
+// DriftedResource is a resource whose real infrastructure differs from the state
+type DriftedResource struct {
+	Address string `json:"address"`
+	Module  string `json:"module,omitempty"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	// Action is "update" when the resource was changed outside of Terraform, and "delete" when it is gone
+	Action string `json:"action"`
+	// ChangedAttributes are the top-level attributes whose value differs from the state
+	ChangedAttributes []string `json:"changed_attributes,omitempty"`
+}
+
+// FindDrift returns the drifted resources of a plan, from the changes the refresh before planning found
+// that are not no-ops, sorted by address
+func FindDrift(plan *tfjson.Plan) []DriftedResource {
+	var drifted []DriftedResource
+	for _, rc := range plan.ResourceDrift {
+		if rc.Change == nil || rc.Change.Actions.NoOp() {
+			continue
+		}
+		action := "update"
+		if rc.Change.Actions.Delete() {
+			action = "delete"
+		}
+		// The name, with its index if any, is what is left of the address after the module and type
+		name := strings.TrimPrefix(rc.Address, rc.ModuleAddress+".")
+		name = strings.TrimPrefix(strings.TrimPrefix(name, "data."), rc.Type+".")
+		drifted = append(drifted, DriftedResource{
+			Address:           rc.Address,
+			Module:            rc.ModuleAddress,
+			Type:              rc.Type,
+			Name:              name,
+			Action:            action,
+			ChangedAttributes: changedAttributes(rc.Change.Before, rc.Change.After),
+		})
+	}
+	sort.Slice(drifted, func(i, j int) bool { return drifted[i].Address < drifted[j].Address })
+	return drifted
+}
+
+// changedAttributes returns the sorted names of the top-level attributes that differ between before and
+// after. It returns nil unless both are objects, such as when the resource was deleted.
+func changedAttributes(before, after interface{}) []string {
+	beforeAttrs, ok := before.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	afterAttrs, ok := after.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	var changed []string
+	for name, value := range beforeAttrs {
+		if !reflect.DeepEqual(value, afterAttrs[name]) {
+			changed = append(changed, name)
+		}
+	}
+	for name := range afterAttrs {
+		if _, ok := beforeAttrs[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
 // GenerateReleaseMetadata generates and saves release metadata from terraform state
 func GenerateReleaseMetadata(tf *tfexec.Terraform, deployDir string) error {
 	tf.SetStdout(io.Discard)
 	tf.SetStderr(io.Discard)
 	state, err := tf.Show(context.Background())
-	tf.SetStdout(os.Stdout)
-	tf.SetStderr(os.Stdout)
+	tf.SetStdout(output.Writer())
+	tf.SetStderr(output.Writer())
 	if err != nil {
 		return fmt.Errorf("terraform show failed: %w", err)
 	}
 	releaseMetadataList := ParseStateFile(state)
 	if len(releaseMetadataList) == 0 {
-		fmt.Println("ℹ️ No release metadata found in state")
+		output.Infoln("ℹ️ No release metadata found in state")
 		return nil
 	}
 	metadataFile := filepath.Join(deployDir, "release-metadata.json")
@@ -344,7 +700,7 @@ func GenerateReleaseMetadata(tf *tfexec.Terraform, deployDir string) error {
 	if err := os.WriteFile(metadataFile, metadataJSON, 0644); err != nil {
 		return fmt.Errorf("failed to write release metadata file: %w", err)
 	}
-	fmt.Printf("📝 Release metadata saved to: %s\n", metadataFile)
+	output.Infof("📝 Release metadata saved to: %s\n", metadataFile)
 	return nil
 }
 
@@ -360,12 +716,12 @@ func GetProfileName(profileFlag string) string {
 func UpdateProfileCredentials(profile, host, username, token string) {
 	home, err := os.UserHomeDir()
 	if err != nil {
-		fmt.Printf("❌ Failed to get home directory: %v\n", err)
+		output.Errorf("❌ Failed to get home directory: %v\n", err)
 		return
 	}
 	credsPath := home + "/.facets/credentials"
 	if err := os.MkdirAll(filepath.Dir(credsPath), 0700); err != nil {
-		fmt.Printf("❌ Failed to create credentials directory: %v\n", err)
+		output.Errorf("❌ Failed to create credentials directory: %v\n", err)
 		return
 	}
 	creds, err := ini.Load(credsPath)
@@ -376,7 +732,7 @@ func UpdateProfileCredentials(profile, host, username, token string) {
 	creds.Section(profile).Key("username").SetValue(username)
 	creds.Section(profile).Key("token").SetValue(token)
 	if err := creds.SaveTo(credsPath); err != nil {
-		fmt.Printf("❌ Failed to save credentials: %v\n", err)
+		output.Errorf("❌ Failed to save credentials: %v\n", err)
 	}
 	configPath := home + "/.facets/config"
 	configIni := ini.Empty()
@@ -388,7 +744,7 @@ func UpdateProfileCredentials(profile, host, username, token string) {
 	}
 	configIni.Section("default").Key("profile").SetValue(profile)
 	if err := configIni.SaveTo(configPath); err != nil {
-		fmt.Printf("❌ Failed to save config file: %v\n", err)
+		output.Errorf("❌ Failed to save config file: %v\n", err)
 	}
 }
 
@@ -396,24 +752,116 @@ func UpdateProfileCredentials(profile, host, username, token string) {
 func UpdateProfileExpiry(profile string) {
 	home, err := os.UserHomeDir()
 	if err != nil {
-		fmt.Printf("⚠️ Warning: Failed to get home directory to update expiry: %v\n", err)
+		output.Warnf("⚠️ Warning: Failed to get home directory to update expiry: %v\n", err)
 		return
 	}
 	credsPath := home + "/.facets/credentials"
 	creds, err := ini.Load(credsPath)
 	if err != nil {
-		fmt.Printf("⚠️ Warning: Could not load credentials to update expiry: %v\n", err)
+		output.Warnf("⚠️ Warning: Could not load credentials to update expiry: %v\n", err)
 		return
 	}
 	expiry := time.Now().Add(24 * time.Hour).Format(time.RFC3339)
 	creds.Section(profile).Key("token_expiry").SetValue(expiry)
 	if err := creds.SaveTo(credsPath); err != nil {
-		fmt.Printf("⚠️ Warning: Failed to save updated token expiry: %v\n", err)
+		output.Warnf("⚠️ Warning: Failed to save updated token expiry: %v\n", err)
+	}
+}
+
+// ConfigHasResource reports whether a managed resource address such as
+// module.db.aws_db_instance.main[0] is declared in the configuration rooted at rootDir.
+// Local module sources are followed directly; remote ones through .terraform/modules/modules.json.
+func ConfigHasResource(rootDir, address string) (bool, error) {
+	parts := splitResourceAddress(address)
+	dir := rootDir
+	var moduleKey []string
+	for len(parts) >= 2 && parts[0] == "module" {
+		name := stripAddressIndex(parts[1])
+		module, diags := tfconfig.LoadModule(dir)
+		if diags.HasErrors() {
+			return false, fmt.Errorf("could not load configuration in %s: %v", dir, diags)
+		}
+		call, ok := module.ModuleCalls[name]
+		if !ok {
+			return false, nil
+		}
+		moduleKey = append(moduleKey, name)
+		if strings.HasPrefix(call.Source, "./") || strings.HasPrefix(call.Source, "../") {
+			dir = filepath.Join(dir, call.Source)
+		} else {
+			installed, err := installedModuleDir(rootDir, strings.Join(moduleKey, "."))
+			if err != nil {
+				return false, err
+			}
+			dir = installed
+		}
+		parts = parts[2:]
+	}
+	if len(parts) != 2 {
+		return false, fmt.Errorf("%q is not a managed resource address (expected [module.<name>.]<type>.<name>)", address)
+	}
+
+	module, diags := tfconfig.LoadModule(dir)
+	if diags.HasErrors() {
+		return false, fmt.Errorf("could not load configuration in %s: %v", dir, diags)
+	}
+	_, ok := module.ManagedResources[parts[0]+"."+stripAddressIndex(parts[1])]
+	return ok, nil
+}
+
+// installedModuleDir looks up where 'terraform init' installed the module with the given key
+func installedModuleDir(rootDir, key string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(rootDir, ".terraform", "modules", "modules.json"))
+	if err != nil {
+		return "", fmt.Errorf("could not read installed modules (has 'terraform init' run?): %v", err)
+	}
+	var manifest struct {
+		Modules []struct {
+			Key string `json:"Key"`
+			Dir string `json:"Dir"`
+		} `json:"Modules"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return "", fmt.Errorf("could not parse modules.json: %v", err)
+	}
+	for _, m := range manifest.Modules {
+		if m.Key == key {
+			return filepath.Join(rootDir, m.Dir), nil
+		}
+	}
+	return "", fmt.Errorf("module %s is not installed", key)
+}
+
+// splitResourceAddress splits a resource address on dots that are outside index brackets
+func splitResourceAddress(address string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, c := range address {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '.':
+			if depth == 0 {
+				parts = append(parts, address[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, address[start:])
+}
+
+// stripAddressIndex removes a trailing [index] from an address part
+func stripAddressIndex(part string) string {
+	if i := strings.Index(part, "["); i >= 0 {
+		return part[:i]
 	}
+	return part
 }
 
-// updatePreventDestroyInTFs recursively updates all .tf files in dir to set prevent_destroy = false in all resource blocks
-func UpdatePreventDestroyInTFs(root string) error {
+// UpdatePreventDestroyInTFs recursively updates all .tf files in root to set prevent_destroy to the given value in all resource blocks
+func UpdatePreventDestroyInTFs(root string, preventDestroy bool) error {
 	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
@@ -421,7 +869,7 @@ func UpdatePreventDestroyInTFs(root string) error {
 		if !d.IsDir() {
 			return nil
 		}
-		fmt.Printf("[DEBUG] Visiting directory: %s\n", path)
+		output.Debugf("Visiting directory: %s\n", path)
 		// Check if this directory contains any .tf files
 		hasTF := false
 		entries, err := os.ReadDir(path)
@@ -435,10 +883,10 @@ func UpdatePreventDestroyInTFs(root string) error {
 			}
 		}
 		if hasTF {
-			fmt.Printf("[DEBUG] Updating module in: %s\n", path)
-			err := UpdatePreventDestroyInSingleModule(path)
+			output.Debugf("Updating module in: %s\n", path)
+			err := UpdatePreventDestroyInSingleModule(path, preventDestroy)
 			if err != nil {
-				fmt.Printf("[DEBUG] Error updating module in %s: %v\n", path, err)
+				output.Debugf("Error updating module in %s: %v\n", path, err)
 			}
 			return err
 		}
@@ -446,11 +894,11 @@ func UpdatePreventDestroyInTFs(root string) error {
 	})
 }
 
-// updatePreventDestroyInSingleModule only updates .tf files in a single directory (non-recursive)
-func UpdatePreventDestroyInSingleModule(dir string) error {
+// UpdatePreventDestroyInSingleModule only updates .tf files in a single directory (non-recursive)
+func UpdatePreventDestroyInSingleModule(dir string, preventDestroy bool) error {
 	module, diags := tfconfig.LoadModule(dir)
 	if diags.HasErrors() {
-		fmt.Printf("[DEBUG] tfconfig.LoadModule errors in %s: %v\n", dir, diags)
+		output.Debugf("tfconfig.LoadModule errors in %s: %v\n", dir, diags)
 		return diags
 	}
 	fileToResources := make(map[string][]*tfconfig.Resource)
@@ -460,17 +908,17 @@ func UpdatePreventDestroyInSingleModule(dir string) error {
 	for file, resources := range fileToResources {
 		absFile := filepath.Join(dir, filepath.Base(file))
 		if _, err := os.Stat(absFile); err != nil {
-			fmt.Printf("[DEBUG] Skipping missing file: %s\n", absFile)
+			output.Debugf("Skipping missing file: %s\n", absFile)
 			continue
 		}
 		src, err := os.ReadFile(absFile)
 		if err != nil {
-			fmt.Printf("[DEBUG] Could not open file: %s\n", absFile)
+			output.Debugf("Could not open file: %s\n", absFile)
 			return err
 		}
 		f, _ := hclwrite.ParseConfig(src, absFile, hcl.Pos{Line: 1, Column: 1})
 		if f == nil {
-			fmt.Printf("[DEBUG] Could not parse file: %s\n", absFile)
+			output.Debugf("Could not parse file: %s\n", absFile)
 			continue
 		}
 		changed := false
@@ -490,10 +938,10 @@ func UpdatePreventDestroyInSingleModule(dir string) error {
 			}
 			lifecycle := FindOrCreateBlock(block.Body(), "lifecycle")
 			if lifecycle == nil || lifecycle.Body() == nil {
-				fmt.Printf("[DEBUG] Could not get or create lifecycle block in: %s\n", absFile)
+				output.Debugf("Could not get or create lifecycle block in: %s\n", absFile)
 				continue
 			}
-			lifecycle.Body().SetAttributeValue("prevent_destroy", cty.BoolVal(false))
+			lifecycle.Body().SetAttributeValue("prevent_destroy", cty.BoolVal(preventDestroy))
 			changed = true
 		}
 		if changed {
@@ -567,7 +1015,16 @@ func IsZipDifferentFromDir(zipPath, dirPath string) (bool, error) {
 		}
 		// Only compare files that are in the zip (ignore extra files in dir)
 		if _, ok := zipFiles[rel]; ok {
-			hash, err := hashFile(path)
+			if info.Mode()&os.ModeSymlink != 0 {
+				// Symlinks are stored with their target as the content
+				linkTarget, err := os.Readlink(path)
+				if err != nil {
+					return err
+				}
+				dirFiles[rel] = fmt.Sprintf("%x", sha256.Sum256([]byte(filepath.ToSlash(linkTarget))))
+				return nil
+			}
+			hash, err := HashFile(path)
 			if err != nil {
 				return err
 			}
@@ -599,7 +1056,8 @@ func IsZipDifferentFromDir(zipPath, dirPath string) (bool, error) {
 	return false, nil
 }
 
-func hashFile(path string) (string, error) {
+// HashFile returns the hex SHA-256 of the file at path
+func HashFile(path string) (string, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return "", err
@@ -634,6 +1092,10 @@ func FixPermissions(root string) error {
 		if info.IsDir() {
 			return os.Chmod(path, 0755)
 		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			// Chmod would change the target instead
+			return nil
+		}
 		mode := os.FileMode(0644)
 		// Make provider binaries executable (common pattern)
 		if strings.Contains(path, "terraform-provider-") || strings.HasSuffix(path, ".provider") {
@@ -645,7 +1107,7 @@ func FixPermissions(root string) error {
 
 // ReadMaskedInput reads input from the terminal without echoing characters (for passwords/tokens)
 func ReadMaskedInput(prompt string) (string, error) {
-	fmt.Print(prompt)
+	output.Promptf("%s", prompt)
 
 	// Check if we're on a terminal
 	if !term.IsTerminal(int(syscall.Stdin)) {
@@ -664,7 +1126,7 @@ func ReadMaskedInput(prompt string) (string, error) {
 		return "", err
 	}
 
-	fmt.Println() // Add newline after masked input
+	output.Promptf("\n") // Add newline after masked input
 	return strings.TrimSpace(string(bytePassword)), nil
 }
 
@@ -693,3 +1155,165 @@ func FormatDuration(d time.Duration) string {
 
 	return strings.Join(parts, "")
 }
+
+// maxRetryBackoff caps the wait between attempts of RetryableOperation.
+const maxRetryBackoff = 30 * time.Second
+
+// RetryableOperation calls fn up to maxAttempts times, backing off exponentially (1s, 2s, 4s, ... up to 30s)
+// between attempts while fn fails with an error IsRetryableError accepts. It returns fn's last error,
+// or that error as soon as ctx is done.
+func RetryableOperation(ctx context.Context, maxAttempts int, fn func() error) error {
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !IsRetryableError(err) || attempt >= maxAttempts {
+			return err
+		}
+		output.Debugf("Attempt %d of %d failed (%v); retrying in %s\n", attempt, maxAttempts, err, backoff)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxRetryBackoff)
+	}
+}
+
+// IsRetryableError reports whether err is a transient failure worth retrying: a network timeout or
+// connection error, or an API response of 429, 502, 503, or 504.
+func IsRetryableError(err error) bool {
+	var apiErr *runtime.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.Code {
+		case 429, 502, 503, 504:
+			return true
+		}
+		return false
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// diffEdit is one line of a line-based diff
+type diffEdit struct {
+	op     byte // ' ', '-' or '+'
+	line   string
+	ai, bi int // positions in a and b before this edit
+}
+
+// splitLines splits s into lines, ignoring a trailing newline. An empty s has no lines.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines returns the edits that turn a into b, based on their longest common subsequence
+func diffLines(a, b []string) []diffEdit {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var edits []diffEdit
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			edits = append(edits, diffEdit{' ', a[i], i, j})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, diffEdit{'-', a[i], i, j})
+			i++
+		default:
+			edits = append(edits, diffEdit{'+', b[j], i, j})
+			j++
+		}
+	}
+	return edits
+}
+
+// DiffStat returns the number of lines added and removed between two versions of a file
+func DiffStat(before, after string) (added, removed int) {
+	if before == after {
+		return 0, 0
+	}
+	for _, e := range diffLines(splitLines(before), splitLines(after)) {
+		switch e.op {
+		case '+':
+			added++
+		case '-':
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// UnifiedDiff returns a unified diff between two versions of a file, or "" if they are equal
+func UnifiedDiff(name, before, after string) string {
+	if before == after {
+		return ""
+	}
+	edits := diffLines(splitLines(before), splitLines(after))
+
+	const contextLines = 3
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- a/%s\n+++ b/%s\n", name, name)
+	for start := 0; start < len(edits); {
+		// Find the next change and grow the hunk until changes are more than 2*contextLines lines apart
+		first := start
+		for first < len(edits) && edits[first].op == ' ' {
+			first++
+		}
+		if first == len(edits) {
+			break
+		}
+		hunkStart := max(first-contextLines, start)
+		last := first
+		for k := first; k < len(edits) && k <= last+2*contextLines; k++ {
+			if edits[k].op != ' ' {
+				last = k
+			}
+		}
+		hunkEnd := min(last+contextLines+1, len(edits))
+
+		aCount, bCount := 0, 0
+		for _, e := range edits[hunkStart:hunkEnd] {
+			if e.op != '+' {
+				aCount++
+			}
+			if e.op != '-' {
+				bCount++
+			}
+		}
+		aStart, bStart := edits[hunkStart].ai, edits[hunkStart].bi
+		if aCount > 0 {
+			aStart++
+		}
+		if bCount > 0 {
+			bStart++
+		}
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", aStart, aCount, bStart, bCount)
+		for _, e := range edits[hunkStart:hunkEnd] {
+			fmt.Fprintf(&sb, "%c%s\n", e.op, e.line)
+		}
+		start = hunkEnd
+	}
+	return sb.String()
+}
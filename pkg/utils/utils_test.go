@@ -0,0 +1,415 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	goruntime "runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// loadPlan reads a plan in the format of 'terraform show -json' from testdata
+func loadPlan(t *testing.T, name string) *tfjson.Plan {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var plan tfjson.Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		t.Fatalf("could not parse %s: %v", name, err)
+	}
+	return &plan
+}
+
+// planWith returns a plan with one resource change per action list, addressed by its index
+func planWith(actions ...tfjson.Actions) *tfjson.Plan {
+	plan := &tfjson.Plan{}
+	for i, a := range actions {
+		plan.ResourceChanges = append(plan.ResourceChanges, &tfjson.ResourceChange{
+			Address: "null_resource.r" + strconv.Itoa(i),
+			Change:  &tfjson.Change{Actions: a},
+		})
+	}
+	return plan
+}
+
+func TestPlannedDestroys(t *testing.T) {
+	create := tfjson.Actions{tfjson.ActionCreate}
+	update := tfjson.Actions{tfjson.ActionUpdate}
+	del := tfjson.Actions{tfjson.ActionDelete}
+	replace := tfjson.Actions{tfjson.ActionDelete, tfjson.ActionCreate}
+	createBeforeDestroy := tfjson.Actions{tfjson.ActionCreate, tfjson.ActionDelete}
+
+	tests := []struct {
+		name string
+		plan *tfjson.Plan
+		want []string
+	}{
+		{name: "empty plan", plan: &tfjson.Plan{}, want: nil},
+		{name: "create and update", plan: planWith(create, update), want: nil},
+		{name: "delete", plan: planWith(create, del), want: []string{"null_resource.r1"}},
+		{name: "replace", plan: planWith(replace, update), want: []string{"null_resource.r0"}},
+		{name: "create before destroy", plan: planWith(createBeforeDestroy), want: []string{"null_resource.r0"}},
+		{
+			name: "fixture",
+			plan: loadPlan(t, "plan.json"),
+			want: []string{"module.db.aws_db_instance.main", "module.cache.aws_elasticache_cluster.main[0]"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PlannedDestroys(tt.plan); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PlannedDestroys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarizePlan(t *testing.T) {
+	tests := []struct {
+		name string
+		plan *tfjson.Plan
+		want []PlanChangeSummary
+	}{
+		{name: "empty plan", plan: &tfjson.Plan{}, want: []PlanChangeSummary{}},
+		{
+			name: "replace counts as add and destroy",
+			plan: planWith(tfjson.Actions{tfjson.ActionDelete, tfjson.ActionCreate}),
+			want: []PlanChangeSummary{{Module: "root", Add: 1, Destroy: 1}},
+		},
+		{
+			name: "fixture",
+			plan: loadPlan(t, "plan.json"),
+			want: []PlanChangeSummary{
+				{Module: "module.cache", Destroy: 1},
+				{Module: "module.db", Add: 1, Change: 1, Destroy: 1},
+				{Module: "root", Add: 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SummarizePlan(tt.plan); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SummarizePlan() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDriftedResources(t *testing.T) {
+	tests := []struct {
+		name string
+		plan *tfjson.Plan
+		want []string
+	}{
+		{name: "no drift", plan: &tfjson.Plan{}, want: nil},
+		{
+			name: "fixture",
+			plan: loadPlan(t, "plan.json"),
+			want: []string{"aws_s3_bucket.assets (deleted)", "module.db.aws_db_instance.main (changed)"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DriftedResources(tt.plan); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DriftedResources() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindDrift(t *testing.T) {
+	got := FindDrift(loadPlan(t, "plan.json"))
+	want := []DriftedResource{
+		{Address: "aws_s3_bucket.assets", Type: "aws_s3_bucket", Name: "assets", Action: "delete"},
+		{
+			Address:           "module.db.aws_db_instance.main",
+			Module:            "module.db",
+			Type:              "aws_db_instance",
+			Name:              "main",
+			Action:            "update",
+			ChangedAttributes: []string{"size", "tags"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindDrift() = %+v, want %+v", got, want)
+	}
+}
+
+// timeoutError is a net.Error that timed out, like the error of an HTTP client timeout
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "plain error", err: errors.New("invalid zip"), want: false},
+		{name: "429", err: runtime.NewAPIError("getExport", nil, 429), want: true},
+		{name: "502", err: runtime.NewAPIError("getExport", nil, 502), want: true},
+		{name: "503", err: runtime.NewAPIError("getExport", nil, 503), want: true},
+		{name: "504", err: runtime.NewAPIError("getExport", nil, 504), want: true},
+		{name: "wrapped 503", err: fmt.Errorf("export failed: %w", runtime.NewAPIError("getExport", nil, 503)), want: true},
+		{name: "404", err: runtime.NewAPIError("getExport", nil, 404), want: false},
+		{name: "500", err: runtime.NewAPIError("getExport", nil, 500), want: false},
+		{name: "connection refused", err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, want: true},
+		{name: "timeout", err: fmt.Errorf("request: %w", timeoutError{}), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryableError(tt.err); got != tt.want {
+				t.Errorf("IsRetryableError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryableOperation(t *testing.T) {
+	retryable := runtime.NewAPIError("getExport", nil, 503)
+	permanent := errors.New("invalid zip")
+	tests := []struct {
+		name        string
+		maxAttempts int
+		errs        []error // returned by successive calls; nil after the last
+		wantCalls   int
+		wantErr     error
+	}{
+		{name: "success", maxAttempts: 3, wantCalls: 1},
+		{name: "permanent error is not retried", maxAttempts: 3, errs: []error{permanent}, wantCalls: 1, wantErr: permanent},
+		{name: "success after a retry", maxAttempts: 3, errs: []error{retryable}, wantCalls: 2},
+		{name: "gives up after maxAttempts", maxAttempts: 2, errs: []error{retryable, retryable, retryable}, wantCalls: 2, wantErr: retryable},
+		{name: "one attempt", maxAttempts: 1, errs: []error{retryable}, wantCalls: 1, wantErr: retryable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := RetryableOperation(context.Background(), tt.maxAttempts, func() error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			})
+			if err != tt.wantErr {
+				t.Errorf("RetryableOperation() error = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("fn was called %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryableOperationStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	retryable := runtime.NewAPIError("getExport", nil, 503)
+	calls := 0
+	start := time.Now()
+	err := RetryableOperation(ctx, 5, func() error {
+		calls++
+		return retryable
+	})
+	if err != retryable || calls != 1 {
+		t.Errorf("RetryableOperation() = %v after %d calls, want the error after 1 call", err, calls)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("RetryableOperation() waited %s after the context was cancelled", elapsed)
+	}
+}
+
+// treeEntry is what TestZipDirExtractZipRoundTrip compares of each file, directory, and symlink
+type treeEntry struct {
+	mode    os.FileMode
+	content string // file contents, or the target of a symlink
+}
+
+// snapshotTree returns the entries under root by slash-separated relative path
+func snapshotTree(t *testing.T, root string) map[string]treeEntry {
+	t.Helper()
+	tree := make(map[string]treeEntry)
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || path == root {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		entry := treeEntry{mode: info.Mode()}
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			entry = treeEntry{mode: os.ModeSymlink, content: filepath.ToSlash(target)}
+		case info.Mode().IsRegular():
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			entry.content = string(data)
+		}
+		tree[filepath.ToSlash(rel)] = entry
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+// writeTree creates files with the given modes under root, then applies the modes of dirs, deepest first
+func writeTree(t *testing.T, root string, files map[string]os.FileMode, dirs map[string]os.FileMode, symlinks map[string]string) {
+	t.Helper()
+	for name, mode := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("content of "+name), mode); err != nil {
+			t.Fatal(err)
+		}
+		// WriteFile applies the umask
+		if err := os.Chmod(path, mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, target := range symlinks {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(filepath.FromSlash(target), path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name := range dirs {
+		if err := os.MkdirAll(filepath.Join(root, filepath.FromSlash(name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, mode := range dirs {
+		if err := os.Chmod(filepath.Join(root, filepath.FromSlash(name)), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestZipDirExtractZipRoundTrip(t *testing.T) {
+	if goruntime.GOOS == "windows" {
+		t.Skip("file modes and symlinks are Unix-only")
+	}
+	source := t.TempDir()
+	writeTree(t, source,
+		map[string]os.FileMode{
+			"tfexport/main.tf": 0644,
+			"tfexport/.terraform/providers/registry.terraform.io/hashicorp/aws/5.0.0/linux_amd64/terraform-provider-aws_v5.0.0": 0755,
+			"tfexport/scripts/bootstrap.sh":    0750,
+			"tfexport/modules/network/main.tf": 0600,
+		},
+		map[string]os.FileMode{
+			"tfexport/empty":                0755,
+			"tfexport/nested/empty/dirs":    0755,
+			"tfexport/nested/empty/private": 0700,
+		},
+		map[string]string{
+			"tfexport/modules/vpc":     "network",
+			"tfexport/current.tf":      "main.tf",
+			"tfexport/scripts/init.sh": "../scripts/bootstrap.sh",
+		},
+	)
+	zipPath := filepath.Join(t.TempDir(), "export.zip")
+	if err := ZipDir(source, zipPath); err != nil {
+		t.Fatalf("ZipDir() error = %v", err)
+	}
+	dest := t.TempDir()
+	if err := ExtractZip(zipPath, dest); err != nil {
+		t.Fatalf("ExtractZip() error = %v", err)
+	}
+
+	want, got := snapshotTree(t, source), snapshotTree(t, dest)
+	for name, w := range want {
+		g, ok := got[name]
+		if !ok {
+			t.Errorf("%s is missing after the round trip", name)
+			continue
+		}
+		if g != w {
+			t.Errorf("%s = %v %q after the round trip, want %v %q", name, g.mode, g.content, w.mode, w.content)
+		}
+	}
+	for name := range got {
+		if _, ok := want[name]; !ok {
+			t.Errorf("%s was added by the round trip", name)
+		}
+	}
+}
+
+func TestZipDirDereferenceSymlinks(t *testing.T) {
+	if goruntime.GOOS == "windows" {
+		t.Skip("symlinks are Unix-only")
+	}
+	source := t.TempDir()
+	writeTree(t, source,
+		map[string]os.FileMode{"tfexport/modules/network/main.tf": 0644},
+		nil,
+		map[string]string{"tfexport/modules/vpc": "network", "tfexport/main.tf": "modules/network/main.tf"},
+	)
+	zipPath := filepath.Join(t.TempDir(), "export.zip")
+	if err := ZipDirWithOptions(source, zipPath, ZipOptions{DereferenceSymlinks: true}); err != nil {
+		t.Fatalf("ZipDirWithOptions() error = %v", err)
+	}
+	dest := t.TempDir()
+	if err := ExtractZip(zipPath, dest); err != nil {
+		t.Fatalf("ExtractZip() error = %v", err)
+	}
+	for _, name := range []string{"tfexport/main.tf", "tfexport/modules/vpc/main.tf"} {
+		info, err := os.Lstat(filepath.Join(dest, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatalf("%s is missing: %v", name, err)
+		}
+		if !info.Mode().IsRegular() {
+			t.Errorf("%s has mode %v, want a regular file", name, info.Mode())
+		}
+	}
+}
+
+func TestExtractZipRefusesSymlinksOutsideRoot(t *testing.T) {
+	if goruntime.GOOS == "windows" {
+		t.Skip("symlinks are Unix-only")
+	}
+	for _, target := range []string{"../../outside", "/etc/passwd", "inside/../../../outside"} {
+		t.Run(target, func(t *testing.T) {
+			source := t.TempDir()
+			writeTree(t, source, nil, nil, map[string]string{"tfexport/link": target})
+			zipPath := filepath.Join(t.TempDir(), "export.zip")
+			if err := ZipDir(source, zipPath); err != nil {
+				t.Fatal(err)
+			}
+			dest := t.TempDir()
+			if err := ExtractZip(zipPath, dest); err == nil || !strings.Contains(err.Error(), "points outside the archive root") {
+				t.Errorf("ExtractZip() error = %v, want a symlink outside the archive root", err)
+			}
+			if _, err := os.Lstat(filepath.Join(dest, "tfexport", "link")); !os.IsNotExist(err) {
+				t.Errorf("the symlink was created")
+			}
+		})
+	}
+}
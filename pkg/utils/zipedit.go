@@ -0,0 +1,342 @@
+package utils
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ZipEdit describes the changes RewriteZip makes to a zip
+type ZipEdit struct {
+	// Remove holds slash-separated glob patterns, as for path.Match. Entries that match, or that are
+	// under a directory that matches, are left out.
+	Remove []string
+	// Rename moves entries to a new path. Renaming a directory moves every entry under it.
+	Rename []ZipRename
+	// Add holds local files and directories to add. They replace the entries of the zip with the same path.
+	Add []ZipAddition
+}
+
+// ZipRename moves the entry at Old, or the entries under the directory Old, to New. Both are
+// slash-separated paths in the zip.
+type ZipRename struct {
+	Old string
+	New string
+}
+
+// ZipAddition is a local file or directory added to a zip at Dest, a slash-separated path in the zip.
+// The contents of a directory are added under Dest, merged with what the zip already has there.
+type ZipAddition struct {
+	Source string
+	Dest   string
+}
+
+// zipEntryMatches reports whether the glob pattern matches the entry name or one of its parent directories
+func zipEntryMatches(pattern, name string) bool {
+	for dir := strings.TrimSuffix(name, "/"); dir != "."; dir = path.Dir(dir) {
+		if ok, _ := path.Match(pattern, dir); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// renamedZipEntry returns the name of the entry after the first rename that applies to it
+func renamedZipEntry(renames []ZipRename, name string) string {
+	for _, r := range renames {
+		if name == r.Old {
+			return r.New
+		}
+		if rest, ok := strings.CutPrefix(name, r.Old+"/"); ok {
+			return r.New + "/" + rest
+		}
+	}
+	return name
+}
+
+// UnmatchedZipEdits returns the Remove patterns and the Rename sources of edit that match no entry of
+// the zip at src
+func UnmatchedZipEdits(src string, edit ZipEdit) (removes, renames []string, err error) {
+	reader, err := zip.OpenReader(src)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer reader.Close()
+	for _, pattern := range edit.Remove {
+		if !slices.ContainsFunc(reader.File, func(f *zip.File) bool { return zipEntryMatches(pattern, f.Name) }) {
+			removes = append(removes, pattern)
+		}
+	}
+	for _, r := range edit.Rename {
+		matches := func(f *zip.File) bool {
+			name := strings.TrimSuffix(f.Name, "/")
+			return name == r.Old || strings.HasPrefix(name, r.Old+"/")
+		}
+		if !slices.ContainsFunc(reader.File, matches) {
+			renames = append(renames, r.Old)
+		}
+	}
+	return removes, renames, nil
+}
+
+// RewriteZip writes src with edit applied to dst, which may be src itself. Entries that are kept are
+// copied without being decompressed, and only the added files are read from disk, so no extraction is
+// needed. Removals are applied first, then renames, then additions, so a removed file can be replaced.
+// Patterns and renames that match nothing are ignored; see UnmatchedZipEdits.
+func RewriteZip(src, dst string, edit ZipEdit) error {
+	reader, err := zip.OpenReader(src)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	// The added files are zipped first, so the entries they replace are known before anything is written
+	tempDir, err := os.MkdirTemp(filepath.Dir(dst), ".fctl-rewrite-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+	added, err := zipAdditions(edit.Add, filepath.Join(tempDir, "added.zip"))
+	if err != nil {
+		return err
+	}
+	defer added.Close()
+
+	// kept maps the names of the entries that are kept, after renaming, to the entries. keptDirs holds
+	// every directory of the kept entries, including those without an entry of their own.
+	kept := make(map[string]*zip.File)
+	keptDirs := make(map[string]bool)
+	var keptNames []string
+	renamed := make(map[string]bool)
+	for _, f := range reader.File {
+		if slices.ContainsFunc(edit.Remove, func(pattern string) bool { return zipEntryMatches(pattern, f.Name) }) {
+			continue
+		}
+		oldName := strings.TrimSuffix(f.Name, "/")
+		name := renamedZipEntry(edit.Rename, oldName)
+		if _, ok := kept[name]; ok && (name != oldName || renamed[name]) {
+			return fmt.Errorf("cannot rename to %s: it is already in the zip", name)
+		}
+		if name != oldName {
+			renamed[name] = true
+		}
+		if _, ok := kept[name]; !ok {
+			keptNames = append(keptNames, name)
+		}
+		kept[name] = f
+		if f.FileInfo().IsDir() {
+			keptDirs[name] = true
+		}
+		for parent := path.Dir(name); parent != "."; parent = path.Dir(parent) {
+			keptDirs[parent] = true
+		}
+	}
+	for name := range renamed {
+		for parent := path.Dir(name); parent != "."; parent = path.Dir(parent) {
+			if k, ok := kept[parent]; ok && !k.FileInfo().IsDir() {
+				return fmt.Errorf("cannot rename to %s: %s is a file in the zip", name, parent)
+			}
+		}
+	}
+	// A later addition to the same path replaces an earlier one
+	lastAdded := make(map[string]int)
+	for i, f := range added.File {
+		name := strings.TrimSuffix(f.Name, "/")
+		lastAdded[name] = i
+		if !f.FileInfo().IsDir() && keptDirs[name] {
+			return fmt.Errorf("cannot replace directory %s with a file", name)
+		}
+		if k, ok := kept[name]; ok && f.FileInfo().IsDir() && !k.FileInfo().IsDir() {
+			return fmt.Errorf("cannot replace file %s with a directory", name)
+		}
+		for parent := path.Dir(name); parent != "."; parent = path.Dir(parent) {
+			if k, ok := kept[parent]; ok && !k.FileInfo().IsDir() {
+				return fmt.Errorf("cannot add %s: %s is a file in the zip", name, parent)
+			}
+		}
+	}
+
+	out, err := os.Create(filepath.Join(tempDir, "output.zip"))
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	writer := zip.NewWriter(out)
+	written := make(map[string]bool)
+	// newNames are the entries whose parent directories may be missing from the zip
+	var newNames []string
+	for _, name := range keptNames {
+		if _, replaced := lastAdded[name]; replaced {
+			continue
+		}
+		f := kept[name]
+		header := f.FileHeader
+		if f.FileInfo().IsDir() {
+			header.Name = name + "/"
+		} else {
+			header.Name = name
+		}
+		if err := copyZipEntry(writer, f, &header); err != nil {
+			return err
+		}
+		written[name] = true
+		if renamed[name] {
+			newNames = append(newNames, name)
+		}
+	}
+	for i, f := range added.File {
+		name := strings.TrimSuffix(f.Name, "/")
+		if lastAdded[name] != i {
+			continue
+		}
+		if err := writer.Copy(f); err != nil {
+			return err
+		}
+		written[name] = true
+		newNames = append(newNames, name)
+	}
+	// Directories that only exist because of an added or renamed entry get an entry, as ZipDir writes for every directory
+	for _, name := range newNames {
+		for parent := path.Dir(name); parent != "."; parent = path.Dir(parent) {
+			if written[parent] {
+				continue
+			}
+			hdr := &zip.FileHeader{Name: parent + "/", Method: zip.Store, Modified: time.Now()}
+			hdr.SetMode(os.ModeDir | 0755)
+			if _, err := writer.CreateHeader(hdr); err != nil {
+				return err
+			}
+			written[parent] = true
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(out.Name(), dst)
+}
+
+// copyZipEntry copies the compressed data of f to writer under header, without decompressing it
+func copyZipEntry(writer *zip.Writer, f *zip.File, header *zip.FileHeader) error {
+	w, err := writer.CreateRaw(header)
+	if err != nil {
+		return err
+	}
+	r, err := f.OpenRaw()
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, r)
+	return err
+}
+
+// zipAdditions zips the local files and directories of additions to target and opens it. Symlinks are
+// followed, as the added files are taken from the local system.
+func zipAdditions(additions []ZipAddition, target string) (*zip.ReadCloser, error) {
+	zipfile, err := os.Create(target)
+	if err != nil {
+		return nil, err
+	}
+	defer zipfile.Close()
+	archive := zip.NewWriter(zipfile)
+	for _, a := range additions {
+		source, err := filepath.EvalSymlinks(a.Source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat source %s: %w", a.Source, err)
+		}
+		dest := path.Clean(strings.Trim(filepath.ToSlash(a.Dest), "/"))
+		if dest == "." || dest == ".." || strings.HasPrefix(dest, "../") {
+			return nil, fmt.Errorf("invalid destination %s (expected a path inside the zip)", a.Dest)
+		}
+		opts := ZipOptions{DereferenceSymlinks: true}
+		if err := zipTree(archive, source, dest, opts, map[string]bool{source: true}); err != nil {
+			return nil, fmt.Errorf("failed to add %s: %w", a.Source, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	if err := zipfile.Close(); err != nil {
+		return nil, err
+	}
+	return zip.OpenReader(target)
+}
+
+// ZipManifestEntry is a file of a zip with the SHA-256 of its contents. Symlinks are listed with the
+// hash of their target, as they are stored.
+type ZipManifestEntry struct {
+	Path   string `json:"path"`
+	Size   uint64 `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// ZipEntryChange is a file whose contents differ between two zips
+type ZipEntryChange struct {
+	Path      string `json:"path"`
+	OldSize   uint64 `json:"old_size"`
+	NewSize   uint64 `json:"new_size"`
+	OldSHA256 string `json:"old_sha256"`
+	NewSHA256 string `json:"new_sha256"`
+}
+
+// ZipChanges are the differences between the manifests of two zips. A renamed file is both removed and added.
+type ZipChanges struct {
+	Added    []ZipManifestEntry `json:"added"`
+	Modified []ZipEntryChange   `json:"modified"`
+	Removed  []ZipManifestEntry `json:"removed"`
+}
+
+// ZipManifest lists the files of the zip at zipPath, sorted by path. Directories are left out.
+func ZipManifest(zipPath string) ([]ZipManifestEntry, error) {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	var entries []ZipManifestEntry
+	for _, f := range reader.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		hash, err := hashZipFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+		entries = append(entries, ZipManifestEntry{Path: f.Name, Size: f.UncompressedSize64, SHA256: hash})
+	}
+	slices.SortFunc(entries, func(a, b ZipManifestEntry) int { return strings.Compare(a.Path, b.Path) })
+	return entries, nil
+}
+
+// CompareZipManifests returns the files that were added, modified, and removed from before to after
+func CompareZipManifests(before, after []ZipManifestEntry) ZipChanges {
+	changes := ZipChanges{Added: []ZipManifestEntry{}, Modified: []ZipEntryChange{}, Removed: []ZipManifestEntry{}}
+	old := make(map[string]ZipManifestEntry, len(before))
+	for _, e := range before {
+		old[e.Path] = e
+	}
+	for _, e := range after {
+		o, ok := old[e.Path]
+		switch {
+		case !ok:
+			changes.Added = append(changes.Added, e)
+		case o.SHA256 != e.SHA256:
+			changes.Modified = append(changes.Modified, ZipEntryChange{Path: e.Path, OldSize: o.Size, NewSize: e.Size, OldSHA256: o.SHA256, NewSHA256: e.SHA256})
+		}
+		delete(old, e.Path)
+	}
+	for _, e := range before {
+		if _, ok := old[e.Path]; ok {
+			changes.Removed = append(changes.Removed, e)
+		}
+	}
+	return changes
+}
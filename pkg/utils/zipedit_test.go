@@ -0,0 +1,253 @@
+package utils
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// writeZip writes a zip with the given files, by path, and returns its path. Names ending in a slash are directories.
+func writeZip(t *testing.T, files map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "export.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w := zip.NewWriter(f)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fw, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write([]byte(files[name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// readZip returns the contents of the files of the zip at path, by name, with "" for directories
+func readZip(t *testing.T, path string) map[string]string {
+	t.Helper()
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	files := make(map[string]string)
+	for _, f := range reader.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = string(data)
+	}
+	return files
+}
+
+func TestRewriteZipAdd(t *testing.T) {
+	local := t.TempDir()
+	if err := os.WriteFile(filepath.Join(local, "main.tf"), []byte("# new main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(local, "mod"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(local, "mod", "vars.tf"), []byte("# vars\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		add     []ZipAddition
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "replace a file",
+			add:  []ZipAddition{{Source: filepath.Join(local, "main.tf"), Dest: "tfexport/main.tf"}},
+			want: map[string]string{"tfexport/": "", "tfexport/main.tf": "# new main\n", "tfexport/outputs.tf": "# outputs\n"},
+		},
+		{
+			name: "add a directory with new parents",
+			add:  []ZipAddition{{Source: filepath.Join(local, "mod"), Dest: "tfexport/modules/extra"}},
+			want: map[string]string{
+				"tfexport/": "", "tfexport/main.tf": "# main\n", "tfexport/outputs.tf": "# outputs\n",
+				"tfexport/modules/": "", "tfexport/modules/extra/": "", "tfexport/modules/extra/vars.tf": "# vars\n",
+			},
+		},
+		{name: "destination outside the zip", add: []ZipAddition{{Source: filepath.Join(local, "main.tf"), Dest: "../main.tf"}}, wantErr: true},
+		{name: "file over a directory", add: []ZipAddition{{Source: filepath.Join(local, "main.tf"), Dest: "tfexport"}}, wantErr: true},
+		{name: "missing source", add: []ZipAddition{{Source: filepath.Join(local, "missing.tf"), Dest: "tfexport/missing.tf"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := writeZip(t, map[string]string{"tfexport/": "", "tfexport/main.tf": "# main\n", "tfexport/outputs.tf": "# outputs\n"})
+			err := RewriteZip(src, src, ZipEdit{Add: tt.add})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RewriteZip() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := readZip(t, src); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RewriteZip() wrote %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRewriteZipRemoveRename(t *testing.T) {
+	files := map[string]string{
+		"tfexport/":                      "",
+		"tfexport/main.tf":               "# main\n",
+		"tfexport/.terraform.lock.hcl":   "# lock\n",
+		"tfexport/modules/":              "",
+		"tfexport/modules/cache/":        "",
+		"tfexport/modules/cache/main.tf": "# cache\n",
+	}
+	tests := []struct {
+		name    string
+		edit    ZipEdit
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "remove by glob",
+			edit: ZipEdit{Remove: []string{"tfexport/.terraform*"}},
+			want: map[string]string{
+				"tfexport/": "", "tfexport/main.tf": "# main\n",
+				"tfexport/modules/": "", "tfexport/modules/cache/": "", "tfexport/modules/cache/main.tf": "# cache\n",
+			},
+		},
+		{
+			name: "remove a directory",
+			edit: ZipEdit{Remove: []string{"tfexport/modules"}},
+			want: map[string]string{"tfexport/": "", "tfexport/main.tf": "# main\n", "tfexport/.terraform.lock.hcl": "# lock\n"},
+		},
+		{
+			name: "rename a directory",
+			edit: ZipEdit{Rename: []ZipRename{{Old: "tfexport/modules/cache", New: "tfexport/modules/redis"}}},
+			want: map[string]string{
+				"tfexport/": "", "tfexport/main.tf": "# main\n", "tfexport/.terraform.lock.hcl": "# lock\n",
+				"tfexport/modules/": "", "tfexport/modules/redis/": "", "tfexport/modules/redis/main.tf": "# cache\n",
+			},
+		},
+		{
+			name: "rename into a new directory",
+			edit: ZipEdit{Rename: []ZipRename{{Old: "tfexport/main.tf", New: "tfexport/root/main.tf"}}},
+			want: map[string]string{
+				"tfexport/": "", "tfexport/.terraform.lock.hcl": "# lock\n", "tfexport/root/": "", "tfexport/root/main.tf": "# main\n",
+				"tfexport/modules/": "", "tfexport/modules/cache/": "", "tfexport/modules/cache/main.tf": "# cache\n",
+			},
+		},
+		{
+			name: "rename over a removed file",
+			edit: ZipEdit{Remove: []string{"tfexport/.terraform.lock.hcl"}, Rename: []ZipRename{{Old: "tfexport/main.tf", New: "tfexport/.terraform.lock.hcl"}}},
+			want: map[string]string{
+				"tfexport/": "", "tfexport/.terraform.lock.hcl": "# main\n",
+				"tfexport/modules/": "", "tfexport/modules/cache/": "", "tfexport/modules/cache/main.tf": "# cache\n",
+			},
+		},
+		{name: "rename over an existing file", edit: ZipEdit{Rename: []ZipRename{{Old: "tfexport/main.tf", New: "tfexport/.terraform.lock.hcl"}}}, wantErr: true},
+		{name: "rename under a file", edit: ZipEdit{Rename: []ZipRename{{Old: "tfexport/modules/cache", New: "tfexport/main.tf/cache"}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := writeZip(t, files)
+			dst := filepath.Join(t.TempDir(), "edited.zip")
+			err := RewriteZip(src, dst, tt.edit)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RewriteZip() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := readZip(t, dst); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RewriteZip() wrote %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmatchedZipEdits(t *testing.T) {
+	src := writeZip(t, map[string]string{"tfexport/main.tf": "# main\n", "tfexport/modules/cache/main.tf": "# cache\n"})
+	edit := ZipEdit{
+		Remove: []string{"tfexport/*.tf", "tfexport/*.tfvars"},
+		Rename: []ZipRename{{Old: "tfexport/modules/cache", New: "tfexport/modules/redis"}, {Old: "tfexport/modules/cach", New: "x"}},
+	}
+	removes, renames, err := UnmatchedZipEdits(src, edit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"tfexport/*.tfvars"}; !reflect.DeepEqual(removes, want) {
+		t.Errorf("unmatched removes = %v, want %v", removes, want)
+	}
+	if want := []string{"tfexport/modules/cach"}; !reflect.DeepEqual(renames, want) {
+		t.Errorf("unmatched renames = %v, want %v", renames, want)
+	}
+}
+
+func TestZipManifestChanges(t *testing.T) {
+	before, err := ZipManifest(writeZip(t, map[string]string{
+		"tfexport/":          "",
+		"tfexport/main.tf":   "# main\n",
+		"tfexport/old.tf":    "# old\n",
+		"tfexport/output.tf": "# output\n",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(before) != 3 || before[0].Path != "tfexport/main.tf" || before[0].Size != 7 || len(before[0].SHA256) != 64 {
+		t.Fatalf("ZipManifest() = %+v, want the 3 files sorted by path, without the directory", before)
+	}
+	after, err := ZipManifest(writeZip(t, map[string]string{
+		"tfexport/main.tf":   "# main, edited\n",
+		"tfexport/new.tf":    "# new\n",
+		"tfexport/output.tf": "# output\n",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := CompareZipManifests(before, after)
+	paths := func(entries []ZipManifestEntry) []string {
+		var p []string
+		for _, e := range entries {
+			p = append(p, e.Path)
+		}
+		return p
+	}
+	if got := paths(changes.Added); !reflect.DeepEqual(got, []string{"tfexport/new.tf"}) {
+		t.Errorf("Added = %v, want [tfexport/new.tf]", got)
+	}
+	if got := paths(changes.Removed); !reflect.DeepEqual(got, []string{"tfexport/old.tf"}) {
+		t.Errorf("Removed = %v, want [tfexport/old.tf]", got)
+	}
+	if len(changes.Modified) != 1 || changes.Modified[0].Path != "tfexport/main.tf" || changes.Modified[0].OldSize != 7 || changes.Modified[0].NewSize != 15 {
+		t.Errorf("Modified = %+v, want tfexport/main.tf from 7 to 15 bytes", changes.Modified)
+	}
+
+	if unchanged := CompareZipManifests(after, after); unchanged.Added == nil || len(unchanged.Added)+len(unchanged.Modified)+len(unchanged.Removed) != 0 {
+		t.Errorf("CompareZipManifests() of the same zip = %+v, want empty, non-nil lists", unchanged)
+	}
+}